@@ -0,0 +1,424 @@
+package apispec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// url the document is registered under so $refs can be resolved
+const docURL = "file:///openapi.json"
+
+// one problem found while validating, Field is a dotted path like data.user_id
+// Keyword is the json schema keyword that failed (type, required, format, ...)
+type FieldError struct {
+	Field   string
+	Message string
+	Keyword string
+}
+
+// a loaded openapi 3.1 document that requests and responses can be checked against
+type Spec struct {
+	routes []*route
+}
+
+type route struct {
+	method   string
+	segments []string
+	// the path ended with a / so it matches everything below it, like ServeMux does
+	prefix bool
+	op     *Operation
+}
+
+type param struct {
+	name     string
+	in       string
+	required bool
+	typ      string
+	schema   *jsonschema.Schema
+}
+
+// a single method on a path in the document
+// Secured means it can't be called without an Authorization header
+type Operation struct {
+	ID      string
+	Path    string
+	Method  string
+	Secured bool
+
+	params       []param
+	body         *jsonschema.Schema
+	bodyRequired bool
+	// status (or "default") -> media type -> schema, a nil schema means anything goes
+	responses map[string]map[string]*jsonschema.Schema
+}
+
+// the parts of the document we read, schemas are compiled from the raw document
+type rawDoc struct {
+	Paths      map[string]map[string]rawOperation `json:"paths"`
+	Security   []map[string][]string              `json:"security"`
+	Components struct {
+		Responses       map[string]rawResponse `json:"responses"`
+		SecuritySchemes map[string]struct {
+			Type string `json:"type"`
+			In   string `json:"in"`
+			Name string `json:"name"`
+		} `json:"securitySchemes"`
+	} `json:"components"`
+}
+
+// true when every way of meeting the requirements needs an Authorization header
+// an empty requirement means the operation can be called without credentials
+func (raw *rawDoc) needsAuthorization(requirements []map[string][]string) bool {
+	if len(requirements) == 0 {
+		return false
+	}
+	for _, requirement := range requirements {
+		usesHeader := false
+		for name := range requirement {
+			scheme := raw.Components.SecuritySchemes[name]
+			if scheme.Type == "http" || (scheme.Type == "apiKey" && scheme.In == "header" && strings.EqualFold(scheme.Name, "Authorization")) {
+				usesHeader = true
+			}
+		}
+		if !usesHeader {
+			return false
+		}
+	}
+	return true
+}
+
+type rawOperation struct {
+	OperationID string                `json:"operationId"`
+	Security    []map[string][]string `json:"security"`
+	Parameters  []struct {
+		Name     string `json:"name"`
+		In       string `json:"in"`
+		Required bool   `json:"required"`
+		Schema   struct {
+			Type string `json:"type"`
+		} `json:"schema"`
+	} `json:"parameters"`
+	RequestBody *struct {
+		Required bool                       `json:"required"`
+		Content  map[string]json.RawMessage `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]rawResponse `json:"responses"`
+}
+
+type rawResponse struct {
+	Ref     string                     `json:"$ref"`
+	Content map[string]json.RawMessage `json:"content"`
+}
+
+var methods = []string{"get", "put", "post", "delete", "patch", "head", "options"}
+
+// parses the document and compiles every schema in it
+func Load(doc []byte) (*Spec, error) {
+	raw := rawDoc{}
+	err := json.Unmarshal(doc, &raw)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse openapi document: %w", err)
+	}
+	schemaDoc, err := jsonschema.UnmarshalJSON(bytes.NewReader(doc))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse openapi document: %w", err)
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	compiler.AssertFormat()
+	err = compiler.AddResource(docURL, schemaDoc)
+	if err != nil {
+		return nil, err
+	}
+	compile := func(pointer ...string) (*jsonschema.Schema, error) {
+		escaped := make([]string, len(pointer))
+		for i, token := range pointer {
+			escaped[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+		}
+		return compiler.Compile(docURL + "#/" + strings.Join(escaped, "/"))
+	}
+
+	spec := &Spec{}
+	for path, item := range raw.Paths {
+		for _, method := range methods {
+			rawOp, ok := item[method]
+			if !ok {
+				continue
+			}
+			security := rawOp.Security
+			if security == nil {
+				security = raw.Security
+			}
+			op := &Operation{
+				ID:        rawOp.OperationID,
+				Path:      path,
+				Method:    strings.ToUpper(method),
+				Secured:   raw.needsAuthorization(security),
+				responses: map[string]map[string]*jsonschema.Schema{},
+			}
+
+			for i, p := range rawOp.Parameters {
+				schema, err := compile("paths", path, method, "parameters", strconv.Itoa(i), "schema")
+				if err != nil {
+					return nil, fmt.Errorf("%s %s parameter %s: %w", op.Method, path, p.Name, err)
+				}
+				op.params = append(op.params, param{name: p.Name, in: p.In, required: p.Required, typ: p.Schema.Type, schema: schema})
+			}
+
+			if rawOp.RequestBody != nil {
+				if _, ok := rawOp.RequestBody.Content["application/json"]; ok {
+					op.body, err = compile("paths", path, method, "requestBody", "content", "application/json", "schema")
+					if err != nil {
+						return nil, fmt.Errorf("%s %s request body: %w", op.Method, path, err)
+					}
+					op.bodyRequired = rawOp.RequestBody.Required
+				}
+			}
+
+			for status, res := range rawOp.Responses {
+				pointer := []string{"paths", path, method, "responses", status}
+				if res.Ref != "" {
+					name := strings.TrimPrefix(res.Ref, "#/components/responses/")
+					res = raw.Components.Responses[name]
+					pointer = []string{"components", "responses", name}
+				}
+				op.responses[status] = map[string]*jsonschema.Schema{}
+				for mediaType, content := range res.Content {
+					var hasSchema struct {
+						Schema json.RawMessage `json:"schema"`
+					}
+					json.Unmarshal(content, &hasSchema)
+					if hasSchema.Schema == nil {
+						op.responses[status][mediaType] = nil
+						continue
+					}
+					schema, err := compile(append(pointer, "content", mediaType, "schema")...)
+					if err != nil {
+						return nil, fmt.Errorf("%s %s response %s: %w", op.Method, path, status, err)
+					}
+					op.responses[status][mediaType] = schema
+				}
+			}
+
+			spec.routes = append(spec.routes, &route{
+				method:   op.Method,
+				segments: strings.Split(strings.Trim(path, "/"), "/"),
+				prefix:   strings.HasSuffix(path, "/"),
+				op:       op,
+			})
+		}
+	}
+
+	//like ServeMux the most specific route wins
+	//exact paths before prefixes, literal segments before parameters and longer prefixes first
+	sort.SliceStable(spec.routes, func(i, j int) bool {
+		a, b := spec.routes[i], spec.routes[j]
+		if a.prefix != b.prefix {
+			return !a.prefix
+		}
+		if a.prefix {
+			return len(a.segments) > len(b.segments)
+		}
+		return countParams(a.segments) < countParams(b.segments)
+	})
+	return spec, nil
+}
+
+func countParams(segments []string) int {
+	n := 0
+	for _, segment := range segments {
+		if strings.HasPrefix(segment, "{") {
+			n++
+		}
+	}
+	return n
+}
+
+// finds the operation for the request and the values of its path parameters
+// returns nil when the document does not describe the request
+func (s *Spec) Find(method, path string) (*Operation, map[string]string) {
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for _, rt := range s.routes {
+		if rt.method != method {
+			continue
+		}
+		if rt.prefix {
+			if strings.HasPrefix(path, "/"+strings.Join(rt.segments, "/")+"/") {
+				return rt.op, nil
+			}
+			continue
+		}
+		if len(rt.segments) != len(segments) {
+			continue
+		}
+		values := map[string]string{}
+		matched := true
+		for i, segment := range rt.segments {
+			if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+				values[strings.Trim(segment, "{}")] = segments[i]
+				continue
+			}
+			if segment != segments[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return rt.op, values
+		}
+	}
+	return nil, nil
+}
+
+// checks the path and query parameters of the request
+func (op *Operation) ValidateParams(r *http.Request, pathValues map[string]string) []FieldError {
+	var details []FieldError
+	query := r.URL.Query()
+	for _, p := range op.params {
+		var value string
+		var found bool
+		switch p.in {
+		case "path":
+			value, found = pathValues[p.name]
+		case "query":
+			found = query.Has(p.name)
+			value = query.Get(p.name)
+		case "header":
+			value = r.Header.Get(p.name)
+			found = value != ""
+		default:
+			continue
+		}
+		if !found {
+			if p.required {
+				details = append(details, FieldError{Field: p.name, Message: "is required", Keyword: "required"})
+			}
+			continue
+		}
+
+		var instance any = value
+		if p.typ != "" && p.typ != "string" {
+			var parsed any
+			if json.Unmarshal([]byte(value), &parsed) == nil {
+				instance = parsed
+			}
+		}
+		for _, d := range validate(p.schema, instance) {
+			d.Field = p.name
+			details = append(details, d)
+		}
+	}
+	return details
+}
+
+// returned by ValidateBody when the body is not json at all
+var ErrInvalidJSON = errors.New("request body is not valid json")
+
+// checks the json request body, an empty body is only allowed if it is optional
+func (op *Operation) ValidateBody(body []byte) ([]FieldError, error) {
+	if op.body == nil {
+		return nil, nil
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.bodyRequired {
+			return nil, fmt.Errorf("%w: request body is empty", ErrInvalidJSON)
+		}
+		return nil, nil
+	}
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+	return validate(op.body, instance), nil
+}
+
+// checks a response the handler wrote
+// undocumented status codes and media types are reported as well
+func (op *Operation) ValidateResponse(status int, contentType string, body []byte) []FieldError {
+	content, ok := op.responses[strconv.Itoa(status)]
+	if !ok {
+		content, ok = op.responses["default"]
+	}
+	if !ok {
+		return []FieldError{{Message: fmt.Sprintf("status %d is not documented", status), Keyword: "responses"}}
+	}
+	if len(content) == 0 {
+		if len(body) > 0 {
+			return []FieldError{{Message: fmt.Sprintf("status %d is documented without a body", status), Keyword: "content"}}
+		}
+		return nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	schema, ok := content[mediaType]
+	if !ok {
+		mainType, _, _ := strings.Cut(mediaType, "/")
+		schema, ok = content[mainType+"/*"]
+	}
+	if !ok {
+		schema, ok = content["*/*"]
+	}
+	if !ok {
+		return []FieldError{{Message: fmt.Sprintf("content type %q is not documented for status %d", contentType, status), Keyword: "content"}}
+	}
+	if schema == nil {
+		return nil
+	}
+	var instance any = string(body)
+	if strings.HasSuffix(mediaType, "json") {
+		parsed, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+		if err != nil {
+			return []FieldError{{Message: "response body is not valid json: " + err.Error(), Keyword: "content"}}
+		}
+		instance = parsed
+	}
+	return validate(schema, instance)
+}
+
+// used to turn schema errors into messages
+var printer = message.NewPrinter(language.English)
+
+// runs the schema and flattens the errors into FieldErrors
+func validate(schema *jsonschema.Schema, instance any) []FieldError {
+	err := schema.Validate(instance)
+	if err == nil {
+		return nil
+	}
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return []FieldError{{Message: err.Error()}}
+	}
+	return leafErrors(validationErr, nil)
+}
+
+// only the leaves say what is wrong, the errors above them just group them
+func leafErrors(err *jsonschema.ValidationError, details []FieldError) []FieldError {
+	if len(err.Causes) > 0 {
+		for _, cause := range err.Causes {
+			details = leafErrors(cause, details)
+		}
+		return details
+	}
+	keyword := ""
+	if path := err.ErrorKind.KeywordPath(); len(path) > 0 {
+		keyword = path[0]
+	}
+	return append(details, FieldError{
+		Field:   strings.Join(err.InstanceLocation, "."),
+		Message: err.ErrorKind.LocalizedString(printer),
+		Keyword: keyword,
+	})
+}
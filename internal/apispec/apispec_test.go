@@ -0,0 +1,125 @@
+package apispec
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+const testDoc = `{
+  "openapi": "3.1.0",
+  "paths": {
+    "/app/": {"get": {"operationId": "app", "responses": {"200": {"description": "file"}}}},
+    "/app/docs/": {"get": {"operationId": "docs", "responses": {"200": {"description": "docs"}}}},
+    "/items/new": {"get": {"operationId": "newItem", "security": [{"bearer": []}, {"query": []}], "responses": {"200": {"description": "form"}}}},
+    "/items/{id}": {"get": {
+      "operationId": "getItem",
+      "parameters": [
+        {"name": "id", "in": "path", "required": true, "schema": {"type": "string", "format": "uuid"}},
+        {"name": "limit", "in": "query", "schema": {"type": "integer", "maximum": 10}}
+      ],
+      "responses": {
+        "200": {"description": "item", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Item"}}}},
+        "404": {"$ref": "#/components/responses/NotFound"}
+      }
+    }},
+    "/items": {"post": {
+      "operationId": "createItem",
+      "security": [{"bearer": []}],
+      "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Item"}}}},
+      "responses": {"204": {"description": "created"}}
+    }}
+  },
+  "components": {
+    "schemas": {
+      "Item": {"type": "object", "required": ["name"], "additionalProperties": false, "properties": {"name": {"type": "string", "maxLength": 3}}}
+    },
+    "securitySchemes": {
+      "bearer": {"type": "http", "scheme": "bearer"},
+      "query": {"type": "apiKey", "in": "query", "name": "token"}
+    },
+    "responses": {
+      "NotFound": {"description": "missing", "content": {"application/json": {"schema": {"type": "object", "required": ["error"]}}}}
+    }
+  }
+}`
+
+func TestFind(t *testing.T) {
+	spec, err := Load([]byte(testDoc))
+	if err != nil {
+		t.Fatalf("was not expecting an error but got error: %v", err)
+	}
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{"GET", "/app/index.html", "app"},
+		{"GET", "/app/docs/", "docs"},
+		{"HEAD", "/app/docs/index.html", "docs"},
+		{"GET", "/items/new", "newItem"},
+		{"GET", "/items/abc", "getItem"},
+		{"POST", "/items", "createItem"},
+		{"DELETE", "/items/abc", ""},
+		{"GET", "/other", ""},
+	}
+	for _, tc := range tests {
+		op, _ := spec.Find(tc.method, tc.path)
+		got := ""
+		if op != nil {
+			got = op.ID
+		}
+		if got != tc.want {
+			t.Errorf("%s %s: was expecting %q but got %q", tc.method, tc.path, tc.want, got)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	spec, err := Load([]byte(testDoc))
+	if err != nil {
+		t.Fatalf("was not expecting an error but got error: %v", err)
+	}
+
+	op, values := spec.Find("GET", "/items/not-a-uuid")
+	details := op.ValidateParams(httptest.NewRequest("GET", "/items/not-a-uuid?limit=50", nil), values)
+	if len(details) != 2 || details[0].Field != "id" || details[1].Field != "limit" {
+		t.Errorf("was expecting errors for id and limit but got %+v", details)
+	}
+
+	post, _ := spec.Find("POST", "/items")
+	if !post.Secured {
+		t.Error("createItem should be secured")
+	}
+	if form, _ := spec.Find("GET", "/items/new"); form.Secured {
+		t.Error("newItem can be called with a query token so it should not need an Authorization header")
+	}
+	_, err = post.ValidateBody(nil)
+	if err == nil {
+		t.Error("was expecting an error for a missing required body")
+	}
+	details, err = post.ValidateBody([]byte(`{"name":"long name","extra":1}`))
+	if err != nil || len(details) != 2 {
+		t.Errorf("was expecting 2 body errors but got %+v, %v", details, err)
+	}
+
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		valid       bool
+	}{
+		{"ok", 200, "application/json", `{"name":"abc"}`, true},
+		{"charset", 200, "application/json; charset=utf-8", `{"name":"abc"}`, true},
+		{"missing field", 200, "application/json", `{}`, false},
+		{"component response", 404, "application/json", `{"error":"gone"}`, true},
+		{"undocumented status", 500, "application/json", `{}`, false},
+		{"undocumented content type", 200, "text/html", `<p>`, false},
+	}
+	for _, tc := range tests {
+		drift := op.ValidateResponse(tc.status, tc.contentType, []byte(tc.body))
+		if (len(drift) == 0) != tc.valid {
+			t.Errorf("%s: was expecting valid=%v but got %+v", tc.name, tc.valid, drift)
+		}
+	}
+}
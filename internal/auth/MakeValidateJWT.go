@@ -9,9 +9,21 @@ import (
 	"github.com/google/uuid"
 )
 
+// the claims of a chirpy access token
+type Claims struct {
+	jwt.RegisteredClaims
+	// the user's role when the token was made, missing in tokens made before there were roles
+	Role string `json:"role,omitempty"`
+}
+
 // makes a jwt which is a json web token which allows users to make request only on their behalf
 // returns a complete signed string with the specified signing method
 func MakeJWT(userID uuid.UUID, tokenSecret string, expiresIn time.Duration) (string, error) {
+	return MakeJWTWithRole(userID, "", tokenSecret, expiresIn)
+}
+
+// same as MakeJWT with the user's role in the role claim
+func MakeJWTWithRole(userID uuid.UUID, role, tokenSecret string, expiresIn time.Duration) (string, error) {
 	//creating current time(UTC) and putting it in a jwt time struct
 	currentTime := time.Now().UTC()
 	currTimeJwt := jwt.NewNumericDate(currentTime)
@@ -21,11 +33,14 @@ func MakeJWT(userID uuid.UUID, tokenSecret string, expiresIn time.Duration) (str
 	expiresJwt := jwt.NewNumericDate(expiredTime)
 
 	//creating the claim
-	claims := jwt.RegisteredClaims{
-		Issuer:    "chirpy",
-		IssuedAt:  currTimeJwt,
-		ExpiresAt: expiresJwt,
-		Subject:   userID.String(),
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "chirpy",
+			IssuedAt:  currTimeJwt,
+			ExpiresAt: expiresJwt,
+			Subject:   userID.String(),
+		},
+		Role: role,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -35,21 +50,39 @@ func MakeJWT(userID uuid.UUID, tokenSecret string, expiresIn time.Duration) (str
 // validates JWT using the tokenstring and the token secret.
 // returns user id/error
 func ValidateJWT(tokenstring, tokenSecret string) (uuid.UUID, error) {
-	token, err := jwt.ParseWithClaims(tokenstring, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
+	userID, _, err := ValidateJWTExpiry(tokenstring, tokenSecret)
+	return userID, err
+}
+
+// same as ValidateJWT but also returns when the token expires
+// used by connections that stay open longer than the token is valid
+func ValidateJWTExpiry(tokenstring, tokenSecret string) (uuid.UUID, time.Time, error) {
+	userID, claims, err := ParseJWT(tokenstring, tokenSecret)
+	if err != nil {
+		return uuid.Nil, time.Time{}, err
+	}
+	return userID, claims.ExpiresAt.Time, nil
+}
+
+// same as ValidateJWT but also returns the claims, like the role and when the token expires
+func ParseJWT(tokenstring, tokenSecret string) (uuid.UUID, Claims, error) {
+	claims := Claims{}
+	token, err := jwt.ParseWithClaims(tokenstring, &claims, func(token *jwt.Token) (interface{}, error) {
 		return []byte(tokenSecret), nil
 	})
 	if err != nil {
-		fmt.Printf("token is invalid or expired: %v\n", err)
-		return uuid.Nil, err
+		return uuid.Nil, Claims{}, fmt.Errorf("token is invalid or expired: %w", err)
 	}
 	userID, err := token.Claims.GetSubject()
 	if err != nil {
-		fmt.Printf("issue getting the userID: %v", err)
-		return uuid.Nil, err
+		return uuid.Nil, Claims{}, fmt.Errorf("issue getting the userID: %w", err)
+	}
+	userIDType, err := uuid.Parse(userID)
+	if err != nil {
+		return uuid.Nil, Claims{}, fmt.Errorf("issue converting userID from string to uuid: %w", err)
 	}
-	userIDType := uuid.MustParse(userID)
-	if userIDType == uuid.Nil {
-		fmt.Println("issue converting userID from string to uuid")
+	if claims.ExpiresAt == nil {
+		return uuid.Nil, Claims{}, fmt.Errorf("token has no expiration time")
 	}
-	return userIDType, nil
+	return userIDType, claims, nil
 }
@@ -41,3 +41,25 @@ func TestValidJWT(t *testing.T) {
 	}
 	t.Log("got a time expired error")
 }
+
+func TestJWTRole(t *testing.T) {
+	userID := uuid.New()
+	tokenString, err := MakeJWTWithRole(userID, "moderator", "mySecret", time.Hour)
+	if err != nil {
+		t.Fatalf("was not expecting an error but got error: %v", err)
+	}
+	parsedID, claims, err := ParseJWT(tokenString, "mySecret")
+	if err != nil {
+		t.Fatalf("was not expecting an error but got error: %v", err)
+	}
+	if parsedID != userID || claims.Role != "moderator" {
+		t.Errorf("was expecting %v with the moderator role but got %v with %q", userID, parsedID, claims.Role)
+	}
+
+	//tokens made before roles existed have no role claim
+	tokenString, _ = MakeJWT(userID, "mySecret", time.Hour)
+	_, claims, _ = ParseJWT(tokenString, "mySecret")
+	if claims.Role != "" {
+		t.Errorf("was expecting no role but got %q", claims.Role)
+	}
+}
@@ -0,0 +1,501 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// smallest secret we accept for signing jwts (HS256 wants at least 256 bits)
+const minSecretLength = 32
+
+// everything the api needs to start
+type Config struct {
+	DBURL    string
+	Platform string
+	Secret   string
+	PolkaKey string
+	AdminKey string
+
+	Port              int
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	ShutdownTimeout   time.Duration
+	ShutdownDrain     time.Duration
+	MaxHeaderBytes    int
+	MaxBodyBytes      int64
+
+	HealthCheckTimeout time.Duration
+
+	MigrateOnStart bool
+
+	OpenAPIValidation bool
+
+	StreamReplaySize int
+
+	MaxChirpLength    int
+	MaxChirpLengthRed int
+
+	ReportHideThreshold int
+
+	ProfanityReloadInterval time.Duration
+
+	LogLevel slog.Level
+
+	TraceExporter string
+	TraceFile     string
+	ServiceName   string
+
+	//set by -print-config, main prints the config and exits
+	PrintConfig bool
+}
+
+// describes one setting and where it can be set
+// Name is the env variable, the file key is the lower case version of it
+// and the flag is the lower case version with dashes
+type setting struct {
+	Name     string
+	Default  string
+	Usage    string
+	Required bool
+	Secret   bool
+	set      func(c *Config, value string) error
+}
+
+func (s setting) fileKey() string {
+	return strings.ToLower(s.Name)
+}
+
+func (s setting) flagName() string {
+	return strings.ReplaceAll(strings.ToLower(s.Name), "_", "-")
+}
+
+var settings = []setting{
+	{Name: "DB_URL", Usage: "postgres connection url, sqlite://path/to/file.db, or memory:// to keep everything in memory", Required: true, Secret: true, set: setDBURL},
+	{Name: "PLATFORM", Default: "prod", Usage: "dev or prod, dev enables the reset endpoint", set: setPlatform},
+	{Name: "SECRET", Usage: "secret used to sign jwts", Required: true, Secret: true, set: setSecret},
+	{Name: "POLKA_KEY", Usage: "api key polka uses for webhooks", Required: true, Secret: true, set: func(c *Config, v string) error {
+		c.PolkaKey = v
+		return nil
+	}},
+	{Name: "ADMIN_KEY", Usage: "api key that can use every admin endpoint as an admin, only users with a role get in when empty", Secret: true, set: func(c *Config, v string) error {
+		c.AdminKey = v
+		return nil
+	}},
+	{Name: "PORT", Default: "8080", Usage: "port the server listens on", set: setPort},
+	{Name: "READ_TIMEOUT", Default: "10s", Usage: "max time to read a whole request", set: durationSetter(func(c *Config) *time.Duration { return &c.ReadTimeout })},
+	{Name: "READ_HEADER_TIMEOUT", Default: "5s", Usage: "max time to read request headers", set: durationSetter(func(c *Config) *time.Duration { return &c.ReadHeaderTimeout })},
+	{Name: "WRITE_TIMEOUT", Default: "30s", Usage: "max time to write a response", set: durationSetter(func(c *Config) *time.Duration { return &c.WriteTimeout })},
+	{Name: "IDLE_TIMEOUT", Default: "120s", Usage: "how long keep-alive connections stay open", set: durationSetter(func(c *Config) *time.Duration { return &c.IdleTimeout })},
+	{Name: "SHUTDOWN_TIMEOUT", Default: "15s", Usage: "how long to wait for requests to finish on shutdown", set: durationSetter(func(c *Config) *time.Duration { return &c.ShutdownTimeout })},
+	{Name: "SHUTDOWN_DRAIN", Default: "5s", Usage: "how long /readyz fails on shutdown before requests stop being taken, so load balancers can stop sending them", set: setShutdownDrain},
+	{Name: "MAX_HEADER_BYTES", Default: "1048576", Usage: "max size of the request headers", set: setMaxHeaderBytes},
+	{Name: "MAX_BODY_BYTES", Default: "1048576", Usage: "max size of a request body", set: setMaxBodyBytes},
+	{Name: "HEALTH_CHECK_TIMEOUT", Default: "2s", Usage: "max time the readiness checks get", set: durationSetter(func(c *Config) *time.Duration { return &c.HealthCheckTimeout })},
+	{Name: "MIGRATE_ON_START", Default: "false", Usage: "apply pending migrations before the server starts", set: boolSetter(func(c *Config) *bool { return &c.MigrateOnStart })},
+	{Name: "OPENAPI_VALIDATION", Default: "false", Usage: "reject requests that don't match openapi.json, in dev responses are checked too", set: boolSetter(func(c *Config) *bool { return &c.OpenAPIValidation })},
+	{Name: "STREAM_REPLAY_SIZE", Default: "1000", Usage: "how many chirp events are kept for /api/stream clients that reconnect", set: setStreamReplaySize},
+	{Name: "MAX_CHIRP_LENGTH", Default: "140", Usage: "how many characters a chirp can have, links count as 23", set: intSetter(func(c *Config) *int { return &c.MaxChirpLength })},
+	{Name: "MAX_CHIRP_LENGTH_RED", Default: "280", Usage: "how many characters a chirp from a Chirpy Red user can have", set: intSetter(func(c *Config) *int { return &c.MaxChirpLengthRed })},
+	{Name: "REPORT_HIDE_THRESHOLD", Default: "5", Usage: "how many users have to report a chirp before it is hidden until a moderator looks at it", set: intSetter(func(c *Config) *int { return &c.ReportHideThreshold })},
+	{Name: "PROFANITY_RELOAD_INTERVAL", Default: "30s", Usage: "how often the profanity terms are reloaded from the database", set: durationSetter(func(c *Config) *time.Duration { return &c.ProfanityReloadInterval })},
+	{Name: "LOG_LEVEL", Default: "info", Usage: "debug, info, warn or error", set: setLogLevel},
+	{Name: "OTEL_TRACES_EXPORTER", Default: "none", Usage: "otlp, stdout, file or none", set: setTraceExporter},
+	{Name: "OTEL_TRACES_FILE", Usage: "file spans are written to when the exporter is file", set: func(c *Config, v string) error {
+		c.TraceFile = v
+		return nil
+	}},
+	{Name: "OTEL_SERVICE_NAME", Default: "chirpy", Usage: "service name put on every span", set: func(c *Config, v string) error {
+		c.ServiceName = v
+		return nil
+	}},
+}
+
+// where Load reads from, tests swap these out
+type sources struct {
+	args      []string
+	lookupEnv func(string) (string, bool)
+	dotenv    string
+	output    io.Writer
+
+	//only DB_URL is required, used by the migrate command
+	dbOnly bool
+}
+
+// loads the config from (lowest to highest priority) the defaults,
+// a yaml or toml file, the .env file, the environment and the command line flags
+//
+// the config file is picked with -config or CONFIG_FILE
+// any secret can also be read from a file with NAME_FILE (for example SECRET_FILE)
+func Load(args []string) (*Config, error) {
+	return load(sources{
+		args:      args,
+		lookupEnv: os.LookupEnv,
+		dotenv:    ".env",
+		output:    os.Stderr,
+	})
+}
+
+// same as Load but only DB_URL has to be set, for commands that only talk to the database
+func LoadForMigrate(args []string) (*Config, error) {
+	return load(sources{
+		args:      args,
+		lookupEnv: os.LookupEnv,
+		dotenv:    ".env",
+		output:    os.Stderr,
+		dbOnly:    true,
+	})
+}
+
+func load(src sources) (*Config, error) {
+	values := map[string]string{}
+	for _, s := range settings {
+		if s.Default != "" {
+			values[s.Name] = s.Default
+		}
+	}
+
+	//flags are parsed first so we know which config file to read
+	fs := flag.NewFlagSet("chirpy", flag.ContinueOnError)
+	fs.SetOutput(src.output)
+	configFile := fs.String("config", "", "path to a yaml or toml config file")
+	printConfig := fs.Bool("print-config", false, "print the effective config with secrets redacted and exit")
+	flagNames := map[string]string{}
+	for _, s := range settings {
+		fs.String(s.flagName(), "", s.Usage)
+		flagNames[s.flagName()] = s.Name
+		if s.Secret {
+			fs.String(s.flagName()+"-file", "", "file to read "+s.flagName()+" from")
+			flagNames[s.flagName()+"-file"] = s.Name + "_FILE"
+		}
+	}
+	err := fs.Parse(src.args)
+	if err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	if *configFile == "" {
+		*configFile, _ = src.lookupEnv("CONFIG_FILE")
+	}
+	if *configFile != "" {
+		fileValues, err := readConfigFile(*configFile)
+		if err != nil {
+			return nil, err
+		}
+		mergeInto(values, fileValues)
+	}
+
+	if src.dotenv != "" {
+		dotenvValues, err := godotenv.Read(src.dotenv)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("could not read %s: %w", src.dotenv, err)
+		}
+		mergeInto(values, dotenvValues)
+	}
+
+	envValues := map[string]string{}
+	for _, s := range settings {
+		if v, ok := src.lookupEnv(s.Name); ok {
+			envValues[s.Name] = v
+		}
+		if v, ok := src.lookupEnv(s.Name + "_FILE"); ok && s.Secret {
+			envValues[s.Name+"_FILE"] = v
+		}
+	}
+	mergeInto(values, envValues)
+
+	//only the flags that were actually passed override the other sources
+	flagValues := map[string]string{}
+	fs.Visit(func(f *flag.Flag) {
+		if name, ok := flagNames[f.Name]; ok {
+			flagValues[name] = f.Value.String()
+		}
+	})
+	mergeInto(values, flagValues)
+
+	cfg := &Config{PrintConfig: *printConfig}
+	var problems []string
+	for _, s := range settings {
+		value := values[s.Name]
+		if path := values[s.Name+"_FILE"]; s.Secret && path != "" {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s_FILE: %v", s.Name, err))
+				continue
+			}
+			value = strings.TrimSpace(string(data))
+		}
+		if value == "" {
+			if s.Required && (!src.dbOnly || s.Name == "DB_URL") {
+				problems = append(problems, fmt.Sprintf("%s is required", s.Name))
+			}
+			continue
+		}
+		err := s.set(cfg, value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", s.Name, err))
+		}
+	}
+	if cfg.TraceExporter == "file" && cfg.TraceFile == "" {
+		problems = append(problems, "OTEL_TRACES_FILE is required when OTEL_TRACES_EXPORTER is file")
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid config:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return cfg, nil
+}
+
+// later values override earlier ones
+// a plain value also clears a NAME_FILE from a lower source and the other way around
+// if one source has both, NAME_FILE wins
+func mergeInto(values map[string]string, newValues map[string]string) {
+	for key, value := range newValues {
+		key = strings.ToUpper(key)
+		if !strings.HasSuffix(key, "_FILE") {
+			values[key] = value
+			delete(values, key+"_FILE")
+		}
+	}
+	for key, value := range newValues {
+		key = strings.ToUpper(key)
+		if name, ok := strings.CutSuffix(key, "_FILE"); ok {
+			values[key] = value
+			delete(values, name)
+		}
+	}
+}
+
+// reads a flat yaml or toml file, keys are the lower case setting names (db_url, port, ...)
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read config file: %w", err)
+	}
+
+	raw := map[string]any{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("config file must be .yaml, .yml or .toml")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not parse config file %s: %w", path, err)
+	}
+
+	known := map[string]bool{}
+	for _, s := range settings {
+		known[s.fileKey()] = true
+		if s.Secret {
+			known[s.fileKey()+"_file"] = true
+		}
+	}
+
+	values := map[string]string{}
+	for key, value := range raw {
+		if !known[strings.ToLower(key)] {
+			return nil, fmt.Errorf("unknown key %q in config file %s", key, path)
+		}
+		values[key] = fmt.Sprint(value)
+	}
+	return values, nil
+}
+
+func setDBURL(c *Config, value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("not a valid url")
+	}
+	switch u.Scheme {
+	case "postgres", "postgresql", "memory":
+	case "sqlite":
+		if strings.TrimPrefix(value, "sqlite://") == "" {
+			return fmt.Errorf("sqlite needs a file path like sqlite://chirpy.db")
+		}
+	default:
+		return fmt.Errorf("scheme must be postgres, postgresql, sqlite or memory, got %q", u.Scheme)
+	}
+	c.DBURL = value
+	return nil
+}
+
+func setPlatform(c *Config, value string) error {
+	if value != "dev" && value != "prod" {
+		return fmt.Errorf("must be dev or prod, got %q", value)
+	}
+	c.Platform = value
+	return nil
+}
+
+func setSecret(c *Config, value string) error {
+	if len(value) < minSecretLength {
+		return fmt.Errorf("must be at least %d characters", minSecretLength)
+	}
+	c.Secret = value
+	return nil
+}
+
+func setPort(c *Config, value string) error {
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("must be a number between 1 and 65535")
+	}
+	c.Port = port
+	return nil
+}
+
+func durationSetter(field func(c *Config) *time.Duration) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("must be a positive duration like 10s")
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+func boolSetter(field func(c *Config) *bool) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("must be true or false")
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+func intSetter(field func(c *Config) *int) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("must be a positive number")
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func setMaxHeaderBytes(c *Config, value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return fmt.Errorf("must be a positive number")
+	}
+	c.MaxHeaderBytes = n
+	return nil
+}
+
+func setShutdownDrain(c *Config, value string) error {
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return fmt.Errorf("must be 0 or a positive duration like 5s")
+	}
+	c.ShutdownDrain = d
+	return nil
+}
+
+func setStreamReplaySize(c *Config, value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return fmt.Errorf("must be 0 or a positive number")
+	}
+	c.StreamReplaySize = n
+	return nil
+}
+
+func setMaxBodyBytes(c *Config, value string) error {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n <= 0 {
+		return fmt.Errorf("must be a positive number")
+	}
+	c.MaxBodyBytes = n
+	return nil
+}
+
+func setLogLevel(c *Config, value string) error {
+	return c.LogLevel.UnmarshalText([]byte(value))
+}
+
+func setTraceExporter(c *Config, value string) error {
+	switch value {
+	case "otlp", "stdout", "file", "none":
+		c.TraceExporter = value
+		return nil
+	}
+	return fmt.Errorf("must be otlp, stdout, file or none, got %q", value)
+}
+
+// returns the effective config with the secrets hidden, safe to log or print
+func (c *Config) Redacted() map[string]string {
+	hide := func(value string) string {
+		if value == "" {
+			return ""
+		}
+		return "[REDACTED]"
+	}
+	dbURL := hide(c.DBURL)
+	if u, err := url.Parse(c.DBURL); err == nil && c.DBURL != "" {
+		dbURL = u.Redacted()
+	}
+	return map[string]string{
+		"DB_URL":                    dbURL,
+		"PLATFORM":                  c.Platform,
+		"SECRET":                    hide(c.Secret),
+		"POLKA_KEY":                 hide(c.PolkaKey),
+		"ADMIN_KEY":                 hide(c.AdminKey),
+		"PORT":                      strconv.Itoa(c.Port),
+		"READ_TIMEOUT":              c.ReadTimeout.String(),
+		"READ_HEADER_TIMEOUT":       c.ReadHeaderTimeout.String(),
+		"WRITE_TIMEOUT":             c.WriteTimeout.String(),
+		"IDLE_TIMEOUT":              c.IdleTimeout.String(),
+		"SHUTDOWN_TIMEOUT":          c.ShutdownTimeout.String(),
+		"SHUTDOWN_DRAIN":            c.ShutdownDrain.String(),
+		"MAX_HEADER_BYTES":          strconv.Itoa(c.MaxHeaderBytes),
+		"MAX_BODY_BYTES":            strconv.FormatInt(c.MaxBodyBytes, 10),
+		"HEALTH_CHECK_TIMEOUT":      c.HealthCheckTimeout.String(),
+		"MIGRATE_ON_START":          strconv.FormatBool(c.MigrateOnStart),
+		"OPENAPI_VALIDATION":        strconv.FormatBool(c.OpenAPIValidation),
+		"STREAM_REPLAY_SIZE":        strconv.Itoa(c.StreamReplaySize),
+		"MAX_CHIRP_LENGTH":          strconv.Itoa(c.MaxChirpLength),
+		"MAX_CHIRP_LENGTH_RED":      strconv.Itoa(c.MaxChirpLengthRed),
+		"REPORT_HIDE_THRESHOLD":     strconv.Itoa(c.ReportHideThreshold),
+		"PROFANITY_RELOAD_INTERVAL": c.ProfanityReloadInterval.String(),
+		"LOG_LEVEL":                 c.LogLevel.String(),
+		"OTEL_TRACES_EXPORTER":      c.TraceExporter,
+		"OTEL_TRACES_FILE":          c.TraceFile,
+		"OTEL_SERVICE_NAME":         c.ServiceName,
+	}
+}
+
+// writes the redacted config as NAME=value lines sorted by name
+func (c *Config) Print(w io.Writer) {
+	values := c.Redacted()
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "%s=%s\n", name, values[name])
+	}
+}
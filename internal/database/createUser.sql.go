@@ -18,7 +18,7 @@ values(
     $1,
     $2
 )
-returning id, created_at, updated_at, email, hashed_password, is_chirpy_red
+returning id, created_at, updated_at, email, hashed_password, is_chirpy_red, suspended_at, role, handle
 `
 
 type CreateUserParams struct {
@@ -36,6 +36,9 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.SuspendedAt,
+		&i.Role,
+		&i.Handle,
 	)
 	return i, err
 }
@@ -10,7 +10,8 @@ import (
 )
 
 const getAllChirps = `-- name: GetAllChirps :many
-select id, created_at, updated_at, body, user_id from chirps
+select id, created_at, updated_at, body, user_id, hidden_at from chirps
+where hidden_at is null
 order by created_at asc
 `
 
@@ -29,6 +30,7 @@ func (q *Queries) GetAllChirps(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.HiddenAt,
 		); err != nil {
 			return nil, err
 		}
@@ -10,7 +10,8 @@ import (
 )
 
 const getAllChirpsDesc = `-- name: GetAllChirpsDesc :many
-select id, created_at, updated_at, body, user_id from chirps
+select id, created_at, updated_at, body, user_id, hidden_at from chirps
+where hidden_at is null
 order by created_at desc
 `
 
@@ -29,6 +30,7 @@ func (q *Queries) GetAllChirpsDesc(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.HiddenAt,
 		); err != nil {
 			return nil, err
 		}
@@ -12,8 +12,8 @@ import (
 )
 
 const getAllChirpFromIDDesc = `-- name: GetAllChirpFromIDDesc :many
-select id, created_at, updated_at, body, user_id from chirps
-where user_id = $1
+select id, created_at, updated_at, body, user_id, hidden_at from chirps
+where user_id = $1 and hidden_at is null
 order by created_at desc
 `
 
@@ -32,6 +32,7 @@ func (q *Queries) GetAllChirpFromIDDesc(ctx context.Context, userID uuid.UUID) (
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.HiddenAt,
 		); err != nil {
 			return nil, err
 		}
@@ -12,7 +12,7 @@ import (
 )
 
 const getChirpWithID = `-- name: GetChirpWithID :one
-select id, created_at, updated_at, body, user_id from chirps
+select id, created_at, updated_at, body, user_id, hidden_at from chirps
 where id = $1
 `
 
@@ -25,6 +25,7 @@ func (q *Queries) GetChirpWithID(ctx context.Context, id uuid.UUID) (Chirp, erro
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.HiddenAt,
 	)
 	return i, err
 }
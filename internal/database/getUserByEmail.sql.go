@@ -10,7 +10,7 @@ import (
 )
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-select id, created_at, updated_at, email, hashed_password, is_chirpy_red from users
+select id, created_at, updated_at, email, hashed_password, is_chirpy_red, suspended_at, role, handle from users
 where email = $1
 `
 
@@ -24,6 +24,9 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.SuspendedAt,
+		&i.Role,
+		&i.Handle,
 	)
 	return i, err
 }
@@ -12,7 +12,7 @@ import (
 )
 
 const getUserFromID = `-- name: GetUserFromID :one
-select id, created_at, updated_at, email, hashed_password, is_chirpy_red from users
+select id, created_at, updated_at, email, hashed_password, is_chirpy_red, suspended_at, role, handle from users
 where id = $1
 `
 
@@ -26,6 +26,9 @@ func (q *Queries) GetUserFromID(ctx context.Context, id uuid.UUID) (User, error)
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.SuspendedAt,
+		&i.Role,
+		&i.Handle,
 	)
 	return i, err
 }
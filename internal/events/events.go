@@ -0,0 +1,198 @@
+package events
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/christianrm0821/Chirpy/internal/store"
+	"github.com/google/uuid"
+)
+
+// the kinds of events sent to subscribers
+const (
+	ChirpCreated = "chirp.created"
+	ChirpDeleted = "chirp.deleted"
+	// hidden by reports or a moderator, only its author still sees it
+	ChirpHidden = "chirp.hidden"
+	// a moderator showed a hidden chirp again
+	ChirpUnhidden = "chirp.unhidden"
+)
+
+// something that happened to a chirp
+// ID is assigned by the hub that delivers the event, it only means something on that instance
+type Event struct {
+	ID    uint64
+	Type  string
+	Chirp store.Chirp
+}
+
+// sends an event to every subscriber, on one instance or all of them
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// which events a subscriber wants, the zero value matches everything
+type Filter struct {
+	AuthorID uuid.UUID
+	// without the #, matched case insensitively
+	Hashtag string
+}
+
+// a chirp that was hidden or deleted, the event only has its id and author
+// so listeners can take it down without being sent what it said
+func (e Event) Removed() bool {
+	return e.Type == ChirpHidden || e.Type == ChirpDeleted
+}
+
+func (f Filter) Match(event Event) bool {
+	if f.AuthorID != uuid.Nil && event.Chirp.UserID != f.AuthorID {
+		return false
+	}
+	//without the body there are no hashtags, every hashtag stream gets it in case it had the chirp
+	if f.Hashtag != "" && !event.Removed() {
+		for _, tag := range Hashtags(event.Chirp.Body) {
+			if strings.EqualFold(tag, f.Hashtag) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+var hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_])#([\p{L}\p{N}_]+)`)
+
+// the hashtags in a chirp body without the #
+func Hashtags(body string) []string {
+	var tags []string
+	for _, match := range hashtagPattern.FindAllStringSubmatch(body, -1) {
+		tags = append(tags, match[1])
+	}
+	return tags
+}
+
+// how many events a subscriber can fall behind before it is dropped
+const subscriberBuffer = 64
+
+// fans events out to the subscribers on this instance
+// and keeps the newest ones so clients that reconnect can catch up
+type Hub struct {
+	mu     sync.Mutex
+	nextID uint64
+	// ring buffer of the newest events, start is the index of the oldest one
+	replay []Event
+	start  int
+	size   int
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+// replaySize is how many events are kept for clients that reconnect
+func NewHub(replaySize int) *Hub {
+	return &Hub{
+		nextID: 1,
+		replay: make([]Event, replaySize),
+		subs:   map[*Subscription]struct{}{},
+	}
+}
+
+// a subscriber's events, C is closed when the hub closes or the subscriber falls too far behind
+type Subscription struct {
+	C      <-chan Event
+	c      chan Event
+	filter Filter
+	hub    *Hub
+}
+
+// delivers the event on this instance, the hub is its own publisher when there is only one instance
+func (h *Hub) Publish(ctx context.Context, event Event) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil
+	}
+	event.ID = h.nextID
+	h.nextID++
+	if len(h.replay) > 0 {
+		h.replay[(h.start+h.size)%len(h.replay)] = event
+		if h.size < len(h.replay) {
+			h.size++
+		} else {
+			h.start = (h.start + 1) % len(h.replay)
+		}
+	}
+	for sub := range h.subs {
+		if !sub.filter.Match(event) {
+			continue
+		}
+		select {
+		case sub.c <- event:
+		default:
+			//a slow client would hold everyone else up, it can reconnect with Last-Event-ID
+			h.remove(sub)
+		}
+	}
+	return nil
+}
+
+// starts sending events that match filter
+// when lastID is not 0 the buffered events after it are returned so the client can catch up,
+// if some of them have already been dropped from the buffer missed is true
+func (h *Hub) Subscribe(filter Filter, lastID uint64) (sub *Subscription, replay []Event, missed bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	c := make(chan Event, subscriberBuffer)
+	sub = &Subscription{C: c, c: c, filter: filter, hub: h}
+	if h.closed {
+		close(c)
+		return sub, nil, false
+	}
+	h.subs[sub] = struct{}{}
+
+	if lastID == 0 || lastID >= h.nextID {
+		return sub, nil, false
+	}
+	oldest := h.nextID - uint64(h.size)
+	missed = lastID+1 < oldest
+	for i := 0; i < h.size; i++ {
+		event := h.replay[(h.start+i)%len(h.replay)]
+		if event.ID > lastID && filter.Match(event) {
+			replay = append(replay, event)
+		}
+	}
+	return sub, replay, missed
+}
+
+// stops the subscription and closes C
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+func (h *Hub) remove(sub *Subscription) {
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.c)
+	}
+}
+
+// true once Close has been called
+func (h *Hub) Closed() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.closed
+}
+
+// closes every subscription so open streams end, used on shutdown
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for sub := range h.subs {
+		h.remove(sub)
+	}
+}
@@ -0,0 +1,131 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/christianrm0821/Chirpy/internal/store"
+	"github.com/google/uuid"
+)
+
+func TestHashtags(t *testing.T) {
+	cases := map[string][]string{
+		"no tags here":              nil,
+		"#go is #Fun":               {"go", "Fun"},
+		"email me@example.com#nope": nil,
+		"(#café) and #日本":           {"café", "日本"},
+		"## # #_ok":                 {"_ok"},
+	}
+	for body, want := range cases {
+		got := Hashtags(body)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: was expecting %v but got %v", body, want, got)
+		}
+	}
+}
+
+func TestHubReplay(t *testing.T) {
+	hub := NewHub(3)
+	alice, bob := uuid.New(), uuid.New()
+	publish := func(author uuid.UUID, body string) {
+		hub.Publish(context.Background(), Event{Type: ChirpCreated, Chirp: store.Chirp{UserID: author, Body: body}})
+	}
+	for _, body := range []string{"one", "two #go", "three", "four #GO"} {
+		publish(alice, body)
+	}
+	publish(bob, "five #go")
+
+	ids := func(events []Event) []uint64 {
+		var out []uint64
+		for _, e := range events {
+			out = append(out, e.ID)
+		}
+		return out
+	}
+
+	//only 3, 4 and 5 are still buffered
+	sub, replay, missed := hub.Subscribe(Filter{}, 3)
+	if missed || !reflect.DeepEqual(ids(replay), []uint64{4, 5}) {
+		t.Errorf("was expecting events 4 and 5 but got %v (missed %v)", ids(replay), missed)
+	}
+	sub.Close()
+
+	sub, replay, missed = hub.Subscribe(Filter{AuthorID: alice, Hashtag: "go"}, 1)
+	if !missed || !reflect.DeepEqual(ids(replay), []uint64{4}) {
+		t.Errorf("was expecting event 4 and missed events but got %v (missed %v)", ids(replay), missed)
+	}
+
+	publish(bob, "six #go")
+	publish(alice, "seven #go")
+	event := <-sub.C
+	if event.ID != 7 || event.Chirp.Body != "seven #go" {
+		t.Errorf("was expecting only alice's chirp but got %+v", event)
+	}
+
+	hub.Close()
+	if _, ok := <-sub.C; ok {
+		t.Error("was expecting the subscription to be closed with the hub")
+	}
+}
+
+func TestSlowSubscriberIsDropped(t *testing.T) {
+	hub := NewHub(0)
+	sub, _, _ := hub.Subscribe(Filter{}, 0)
+	for i := 0; i <= subscriberBuffer; i++ {
+		hub.Publish(context.Background(), Event{Type: ChirpCreated})
+	}
+	n := 0
+	for range sub.C {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Errorf("was expecting %d buffered events before the subscription closed but got %d", subscriberBuffer, n)
+	}
+}
+
+// without a listener the relay can't get its own events back from postgres
+func TestPostgresRelayWithoutListener(t *testing.T) {
+	dbURL := "postgres://127.0.0.1:1/chirpy?sslmode=disable&connect_timeout=1"
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	hub := NewHub(10)
+	relay := NewPostgresRelay(db, dbURL, hub, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	sub, _, _ := hub.Subscribe(Filter{}, 0)
+	defer sub.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- relay.Run(ctx) }()
+
+	err = relay.Publish(context.Background(), Event{Type: ChirpCreated, Chirp: store.Chirp{Body: "hello"}})
+	if err == nil {
+		t.Error("was expecting the notify to fail")
+	}
+	select {
+	case event := <-sub.C:
+		if event.Chirp.Body != "hello" {
+			t.Errorf("was expecting the chirp but got %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Error("was expecting the event to be delivered on this instance")
+	}
+
+	//Run is still waiting to listen, it stops with ctx
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("was not expecting an error but got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("was expecting Run to stop when ctx is cancelled")
+	}
+}
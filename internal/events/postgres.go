@@ -0,0 +1,146 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/christianrm0821/Chirpy/internal/store"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// the postgres channel every instance listens on
+const notifyChannel = "chirpy_events"
+
+// shares events between instances with postgres LISTEN/NOTIFY
+// every instance, including the one that published it, gets the event from postgres and hands it to its hub
+// while this instance isn't listening its own events go straight to its hub so its clients still get them
+type PostgresRelay struct {
+	db       *sql.DB
+	listener *pq.Listener
+	hub      *Hub
+	logger   *slog.Logger
+	// set once Run is listening on the channel
+	listening atomic.Bool
+	// false while the listener's connection is lost
+	connected atomic.Bool
+}
+
+// postgres rejects NOTIFY payloads this long
+const maxPayload = 8000
+
+// what is sent in the NOTIFY payload, it has to stay under maxPayload bytes
+type notification struct {
+	Type      string    `json:"type"`
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Body      string    `json:"body"`
+	UserID    uuid.UUID `json:"user_id"`
+	HiddenAt  time.Time `json:"hidden_at"`
+}
+
+// db is used to publish, dbURL opens the dedicated connection the listener needs
+func NewPostgresRelay(db *sql.DB, dbURL string, hub *Hub, logger *slog.Logger) *PostgresRelay {
+	relay := &PostgresRelay{db: db, hub: hub, logger: logger}
+	relay.listener = pq.NewListener(dbURL, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		switch ev {
+		case pq.ListenerEventConnected:
+			relay.connected.Store(true)
+		case pq.ListenerEventDisconnected:
+			relay.connected.Store(false)
+			logger.Warn("lost the event listener connection, events from other instances are missed until it is back", "error", err)
+		case pq.ListenerEventReconnected:
+			relay.connected.Store(true)
+			logger.Info("event listener reconnected, events sent while it was down were missed")
+		case pq.ListenerEventConnectionAttemptFailed:
+			logger.Warn("could not connect the event listener", "error", err)
+		}
+	})
+	return relay
+}
+
+func (p *PostgresRelay) Publish(ctx context.Context, event Event) error {
+	if !p.listening.Load() || !p.connected.Load() {
+		//the event won't come back from postgres, other instances still get it if the notify works
+		p.hub.Publish(ctx, event)
+	}
+	payload, err := json.Marshal(notification{
+		Type:      event.Type,
+		ID:        event.Chirp.ID,
+		CreatedAt: event.Chirp.CreatedAt,
+		UpdatedAt: event.Chirp.UpdatedAt,
+		Body:      event.Chirp.Body,
+		UserID:    event.Chirp.UserID,
+		HiddenAt:  event.Chirp.HiddenAt.Time,
+	})
+	if err != nil {
+		return err
+	}
+	if len(payload) >= maxPayload {
+		if p.listening.Load() && p.connected.Load() {
+			p.hub.Publish(ctx, event)
+		}
+		return fmt.Errorf("event for chirp %s is %d bytes, too long to notify other instances", event.Chirp.ID, len(payload))
+	}
+	_, err = p.db.ExecContext(ctx, "SELECT pg_notify($1, $2)", notifyChannel, string(payload))
+	if err != nil {
+		return fmt.Errorf("could not notify %s: %w", notifyChannel, err)
+	}
+	return nil
+}
+
+// listens until ctx is cancelled and hands every notification to the hub
+// when it can't listen the error is returned and events are only delivered on this instance
+func (p *PostgresRelay) Run(ctx context.Context) error {
+	defer p.listener.Close()
+	//Listen waits for as long as postgres can't be reached, closing the listener stops it
+	stop := context.AfterFunc(ctx, func() { p.listener.Close() })
+	defer stop()
+	err := p.listener.Listen(notifyChannel)
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not listen on %s: %w", notifyChannel, err)
+	}
+	p.listening.Store(true)
+	defer p.listening.Store(false)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n := <-p.listener.Notify:
+			//nil is sent after a reconnect
+			if n == nil {
+				continue
+			}
+			msg := notification{}
+			err := json.Unmarshal([]byte(n.Extra), &msg)
+			if err != nil {
+				p.logger.Warn("ignoring malformed event", "error", err)
+				continue
+			}
+			p.hub.Publish(ctx, Event{
+				Type: msg.Type,
+				Chirp: store.Chirp{
+					ID:        msg.ID,
+					CreatedAt: msg.CreatedAt,
+					UpdatedAt: msg.UpdatedAt,
+					Body:      msg.Body,
+					UserID:    msg.UserID,
+					HiddenAt:  sql.NullTime{Time: msg.HiddenAt, Valid: !msg.HiddenAt.IsZero()},
+				},
+			})
+		case <-time.After(90 * time.Second):
+			//make sure the connection is still there, pq reconnects if it isn't
+			go p.listener.Ping()
+		}
+	}
+}
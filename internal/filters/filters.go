@@ -0,0 +1,234 @@
+// personal content filters, the words, phrases, hashtags and regexes a user doesn't want to see
+package filters
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/christianrm0821/Chirpy/internal/store"
+)
+
+// the kinds of filters
+const (
+	Word    = "word"
+	Phrase  = "phrase"
+	Hashtag = "hashtag"
+	Regex   = "regex"
+)
+
+var Kinds = []string{Word, Phrase, Hashtag, Regex}
+
+// where a filter applies
+const (
+	Timeline      = "timeline"
+	Notifications = "notifications"
+)
+
+var Scopes = []string{Timeline, Notifications}
+
+// the longest value a filter can have, in characters
+const MaxValueLength = 100
+
+// returns why value can't be used for a filter of kind, "" when it can
+func Check(kind, value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "is required"
+	}
+	if len([]rune(value)) > MaxValueLength {
+		return "is too long"
+	}
+	switch kind {
+	case Word:
+		if len(tokens(value)) != 1 || strings.ContainsFunc(value, unicode.IsSpace) {
+			return "must be a single word"
+		}
+	case Phrase:
+		if len(tokens(value)) == 0 {
+			return "must have a word in it"
+		}
+	case Hashtag:
+		tag := strings.TrimPrefix(value, "#")
+		if tag == "" || strings.IndexFunc(tag, func(r rune) bool { return !isTagRune(r) }) != -1 {
+			return "must be letters, digits and underscores"
+		}
+	case Regex:
+		_, err := regexp.Compile(value)
+		if err != nil {
+			return "is not a valid regex"
+		}
+	default:
+		return "unknown kind"
+	}
+	return ""
+}
+
+// the form a value is saved in, hashtags lose their #
+func Normalize(kind, value string) string {
+	value = strings.TrimSpace(value)
+	if kind == Hashtag {
+		return strings.TrimPrefix(value, "#")
+	}
+	return value
+}
+
+// reports if the filter is on for scope
+func InScope(filter store.ContentFilter, scope string) bool {
+	switch scope {
+	case Timeline:
+		return filter.Timeline
+	case Notifications:
+		return filter.Notifications
+	}
+	return false
+}
+
+// a filter that matched and the text it matched
+type Result struct {
+	Filter  store.ContentFilter
+	Matches []string
+}
+
+// a user's filters for one scope, ready to match text against
+// a nil Set matches nothing
+type Set struct {
+	filters []compiled
+}
+
+type compiled struct {
+	filter store.ContentFilter
+	// the lowercase words of word and phrase filters, the tag of hashtag filters
+	words []string
+	re    *regexp.Regexp
+}
+
+// keeps the filters that are on for scope, regexes that don't compile are left out
+func New(filters []store.ContentFilter, scope string) *Set {
+	set := &Set{}
+	for _, filter := range filters {
+		if !InScope(filter, scope) {
+			continue
+		}
+		c := compiled{filter: filter}
+		switch filter.Kind {
+		case Word, Phrase:
+			for _, token := range tokens(filter.Value) {
+				c.words = append(c.words, strings.ToLower(token.text))
+			}
+		case Hashtag:
+			c.words = []string{strings.ToLower(filter.Value)}
+		case Regex:
+			re, err := regexp.Compile("(?i)" + filter.Value)
+			if err != nil {
+				continue
+			}
+			c.re = re
+		default:
+			continue
+		}
+		set.filters = append(set.filters, c)
+	}
+	return set
+}
+
+// reports if the set has no filters
+func (s *Set) Empty() bool {
+	return s == nil || len(s.filters) == 0
+}
+
+// the filters that match text, in the order they were given to New
+func (s *Set) Match(text string) []Result {
+	if s.Empty() {
+		return nil
+	}
+	words := tokens(text)
+	var tags []token
+	for _, word := range words {
+		if word.start > 0 && text[word.start-1] == '#' {
+			tags = append(tags, word)
+		}
+	}
+	var results []Result
+	for _, c := range s.filters {
+		var matches []string
+		switch c.filter.Kind {
+		case Word, Phrase:
+			matches = matchWords(text, words, c.words)
+		case Hashtag:
+			for _, tag := range tags {
+				if strings.EqualFold(tag.text, c.words[0]) {
+					matches = appendUnique(matches, "#"+tag.text)
+				}
+			}
+		case Regex:
+			for _, match := range c.re.FindAllString(text, -1) {
+				if match != "" {
+					matches = appendUnique(matches, match)
+				}
+			}
+		}
+		if len(matches) > 0 {
+			results = append(results, Result{Filter: c.filter, Matches: matches})
+		}
+	}
+	return results
+}
+
+// a run of letters, digits and underscores and where it starts in the text
+type token struct {
+	text  string
+	start int
+}
+
+func isTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || r == '_'
+}
+
+func tokens(text string) []token {
+	var list []token
+	start := -1
+	for i, r := range text {
+		if isTagRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			list = append(list, token{text: text[start:i], start: start})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		list = append(list, token{text: text[start:], start: start})
+	}
+	return list
+}
+
+// every place the words appear one after the other, as written in the text
+func matchWords(text string, words []token, want []string) []string {
+	var matches []string
+	for i := 0; i+len(want) <= len(words); i++ {
+		found := true
+		for j, w := range want {
+			if strings.ToLower(words[i+j].text) != w {
+				found = false
+				break
+			}
+		}
+		if found {
+			last := words[i+len(want)-1]
+			matches = appendUnique(matches, text[words[i].start:last.start+len(last.text)])
+		}
+	}
+	return matches
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, have := range list {
+		if have == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
@@ -0,0 +1,71 @@
+package filters
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/christianrm0821/Chirpy/internal/store"
+)
+
+func TestMatch(t *testing.T) {
+	filter := func(kind, value string) store.ContentFilter {
+		return store.ContentFilter{Kind: kind, Value: value, Timeline: true}
+	}
+	tests := []struct {
+		filter store.ContentFilter
+		text   string
+		want   []string
+	}{
+		{filter(Word, "spoiler"), "No SPOILERS here, just a Spoiler!", []string{"Spoiler"}},
+		{filter(Word, "cat"), "concatenate", nil},
+		{filter(Phrase, "game of thrones"), "who watched Game  of\nThrones?", []string{"Game  of\nThrones"}},
+		{filter(Phrase, "game of thrones"), "game of chess", nil},
+		{filter(Hashtag, "golang"), "#GoLang is fun, golang is not a tag, #golang_dev is another", []string{"#GoLang"}},
+		{filter(Regex, `fo+bar`), "FOOOBAR and foobar", []string{"FOOOBAR", "foobar"}},
+		{filter(Word, "café"), "the café is open", []string{"café"}},
+	}
+	for _, test := range tests {
+		set := New([]store.ContentFilter{test.filter}, Timeline)
+		var got []string
+		for _, result := range set.Match(test.text) {
+			got = result.Matches
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s %q on %q: was expecting %q but got %q", test.filter.Kind, test.filter.Value, test.text, test.want, got)
+		}
+	}
+}
+
+func TestScope(t *testing.T) {
+	set := New([]store.ContentFilter{{Kind: Word, Value: "spoiler", Notifications: true}}, Timeline)
+	if !set.Empty() || len(set.Match("spoiler")) != 0 {
+		t.Errorf("was expecting a notifications filter to be left out of the timeline")
+	}
+	var nilSet *Set
+	if nilSet.Match("spoiler") != nil {
+		t.Errorf("was expecting a nil set to match nothing")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		kind, value string
+		ok          bool
+	}{
+		{Word, "spoiler", true},
+		{Word, "two words", false},
+		{Phrase, "two words", true},
+		{Phrase, "!!!", false},
+		{Hashtag, "#go_lang", true},
+		{Hashtag, "go-lang", false},
+		{Regex, "fo+", true},
+		{Regex, "fo(", false},
+		{"emoji", "x", false},
+		{Word, " ", false},
+	}
+	for _, test := range tests {
+		if got := Check(test.kind, test.value) == ""; got != test.ok {
+			t.Errorf("%s %q: was expecting ok to be %v but got %q", test.kind, test.value, test.ok, Check(test.kind, test.value))
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// checks that the database answers a ping
+func DBPing(db *sql.DB) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	})
+}
+
+// checks that the newest applied goose migration is the one this build expects
+func SchemaVersion(db *sql.DB, expected int64) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		var version int64
+		err := db.QueryRowContext(ctx, "select coalesce(max(version_id), 0) from goose_db_version where is_applied").Scan(&version)
+		if err != nil {
+			return fmt.Errorf("could not read migration version: %w", err)
+		}
+		if version != expected {
+			return fmt.Errorf("database is at migration %d but %d is expected", version, expected)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,143 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// something the api depends on that can be checked (database, mailer, ...)
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// lets a plain function be used as a Checker
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+type namedChecker struct {
+	name    string
+	checker Checker
+}
+
+// keeps the dependency checks used by the readiness probe
+// it starts out ready, SetReady(false) takes the instance out of rotation
+type Registry struct {
+	timeout time.Duration
+
+	mu       sync.RWMutex
+	checkers []namedChecker
+	ready    atomic.Bool
+}
+
+// makes a registry where every check gets at most timeout to finish
+func NewRegistry(timeout time.Duration) *Registry {
+	reg := &Registry{timeout: timeout}
+	reg.ready.Store(true)
+	return reg
+}
+
+// adds a check that has to pass for the instance to be ready
+func (reg *Registry) Register(name string, checker Checker) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.checkers = append(reg.checkers, namedChecker{name: name, checker: checker})
+}
+
+// turns readiness on or off, used to stop getting traffic during shutdown
+func (reg *Registry) SetReady(ready bool) {
+	reg.ready.Store(ready)
+}
+
+// result of a single check
+type CheckResult struct {
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	LatencyMS int64  `json:"latency_ms"`
+}
+
+// body returned by the probes
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// runs every check at the same time and reports if all of them passed
+func (reg *Registry) Run(ctx context.Context) (Report, bool) {
+	reg.mu.RLock()
+	checkers := append([]namedChecker(nil), reg.checkers...)
+	reg.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, reg.timeout)
+	defer cancel()
+
+	report := Report{Status: "ok", Checks: make(map[string]CheckResult, len(checkers))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range checkers {
+		wg.Add(1)
+		go func(c namedChecker) {
+			defer wg.Done()
+			start := time.Now()
+			err := c.checker.Check(ctx)
+			result := CheckResult{Status: "ok", LatencyMS: time.Since(start).Milliseconds()}
+			if err != nil {
+				result.Status = "fail"
+				result.Error = err.Error()
+			}
+			mu.Lock()
+			report.Checks[c.name] = result
+			mu.Unlock()
+		}(c)
+	}
+	wg.Wait()
+
+	healthy := true
+	for _, result := range report.Checks {
+		if result.Status != "ok" {
+			healthy = false
+		}
+	}
+	if !reg.ready.Load() {
+		report.Status = "shutting_down"
+		return report, false
+	}
+	if !healthy {
+		report.Status = "fail"
+	}
+	return report, healthy
+}
+
+// liveness only says the process is up and serving, it never checks dependencies
+// so a database outage does not get the instance restarted
+func (reg *Registry) LiveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, http.StatusOK, Report{Status: "ok"})
+	})
+}
+
+// readiness runs the checks and returns 503 if any of them fail
+func (reg *Registry) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report, ok := reg.Run(r.Context())
+		code := http.StatusOK
+		if !ok {
+			code = http.StatusServiceUnavailable
+		}
+		writeReport(w, code, report)
+	})
+}
+
+func writeReport(w http.ResponseWriter, code int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	res, _ := json.Marshal(report)
+	w.Write(res)
+}
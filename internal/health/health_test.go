@@ -0,0 +1,67 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func getReport(t *testing.T, handler http.Handler) (int, Report) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+	report := Report{}
+	err := json.Unmarshal(rec.Body.Bytes(), &report)
+	if err != nil {
+		t.Fatalf("could not decode report: %v", err)
+	}
+	return rec.Code, report
+}
+
+func TestReadyHandler(t *testing.T) {
+	reg := NewRegistry(50 * time.Millisecond)
+	reg.Register("database", CheckerFunc(func(ctx context.Context) error { return nil }))
+
+	code, report := getReport(t, reg.ReadyHandler())
+	if code != http.StatusOK || report.Status != "ok" {
+		t.Errorf("was expecting 200 ok but got %d %s", code, report.Status)
+	}
+
+	reg.Register("mailer", CheckerFunc(func(ctx context.Context) error { return errors.New("connection refused") }))
+	reg.Register("slow", CheckerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+	code, report = getReport(t, reg.ReadyHandler())
+	if code != http.StatusServiceUnavailable {
+		t.Errorf("was expecting 503 but got %d", code)
+	}
+	if report.Checks["mailer"].Error != "connection refused" {
+		t.Errorf("was expecting the mailer error in the report but got %+v", report.Checks["mailer"])
+	}
+	if report.Checks["slow"].Status != "fail" {
+		t.Errorf("slow check should time out but got %+v", report.Checks["slow"])
+	}
+	if report.Checks["database"].Status != "ok" {
+		t.Errorf("database check should still pass but got %+v", report.Checks["database"])
+	}
+}
+
+func TestSetReady(t *testing.T) {
+	reg := NewRegistry(time.Second)
+	reg.SetReady(false)
+
+	code, report := getReport(t, reg.ReadyHandler())
+	if code != http.StatusServiceUnavailable || report.Status != "shutting_down" {
+		t.Errorf("was expecting 503 shutting_down but got %d %s", code, report.Status)
+	}
+
+	code, _ = getReport(t, reg.LiveHandler())
+	if code != http.StatusOK {
+		t.Errorf("liveness should not depend on readiness, got %d", code)
+	}
+}
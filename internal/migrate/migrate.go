@@ -0,0 +1,98 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"text/tabwriter"
+
+	"github.com/christianrm0821/Chirpy/sql/schema"
+	sqliteschema "github.com/christianrm0821/Chirpy/sql/sqlite/schema"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+// runs the goose migrations embedded in the binary
+type Migrator struct {
+	provider *goose.Provider
+	logger   *slog.Logger
+}
+
+// makes a migrator for the postgres schema in sql/schema
+// every run holds a postgres advisory lock so instances starting at the same time don't race
+func NewPostgres(db *sql.DB, logger *slog.Logger) (*Migrator, error) {
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, fmt.Errorf("could not make migration lock: %w", err)
+	}
+	return newMigrator(goose.DialectPostgres, db, schema.FS, logger, goose.WithSessionLocker(locker))
+}
+
+// makes a migrator for the sqlite schema in sql/sqlite/schema
+// sqlite is only used by a single instance so no lock is taken
+func NewSQLite(db *sql.DB, logger *slog.Logger) (*Migrator, error) {
+	return newMigrator(goose.DialectSQLite3, db, sqliteschema.FS, logger)
+}
+
+func newMigrator(dialect goose.Dialect, db *sql.DB, fsys fs.FS, logger *slog.Logger, opts ...goose.ProviderOption) (*Migrator, error) {
+	provider, err := goose.NewProvider(dialect, db, fsys, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not load migrations: %w", err)
+	}
+	return &Migrator{provider: provider, logger: logger}, nil
+}
+
+// newest migration version embedded in the binary
+func (m *Migrator) Latest() int64 {
+	sources := m.provider.ListSources()
+	if len(sources) == 0 {
+		return 0
+	}
+	return sources[len(sources)-1].Version
+}
+
+// applies every pending migration
+func (m *Migrator) Up(ctx context.Context) error {
+	results, err := m.provider.Up(ctx)
+	for _, res := range results {
+		m.logger.Info("applied migration", "version", res.Source.Version, "duration", res.Duration.String())
+	}
+	if err != nil {
+		return fmt.Errorf("could not apply migrations: %w", err)
+	}
+	if len(results) == 0 {
+		m.logger.Info("no pending migrations", "version", m.Latest())
+	}
+	return nil
+}
+
+// rolls back the newest applied migration
+func (m *Migrator) Down(ctx context.Context) error {
+	res, err := m.provider.Down(ctx)
+	if err != nil {
+		return fmt.Errorf("could not roll back migration: %w", err)
+	}
+	m.logger.Info("rolled back migration", "version", res.Source.Version, "duration", res.Duration.String())
+	return nil
+}
+
+// writes a table with every migration and whether it has been applied
+func (m *Migrator) Status(ctx context.Context, w io.Writer) error {
+	statuses, err := m.provider.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get migration status: %w", err)
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tSTATE\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "-"
+		if status.State == goose.StateApplied {
+			appliedAt = status.AppliedAt.UTC().Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\n", status.Source.Version, status.State, appliedAt)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,91 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/christianrm0821/Chirpy/internal/store"
+)
+
+func TestSQLiteUpDownStatus(t *testing.T) {
+	db, err := store.OpenSQLite(filepath.Join(t.TempDir(), "chirpy.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	migrator, err := NewSQLite(db, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	latest := migrator.Latest()
+	if latest == 0 {
+		t.Fatal("was expecting the sqlite migrations to be embedded")
+	}
+
+	var status bytes.Buffer
+	migrator.Status(ctx, &status)
+	if strings.Count(status.String(), "pending") != int(latest) {
+		t.Errorf("was expecting %d pending migrations but got\n%s", latest, status.String())
+	}
+
+	err = migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("was not expecting an error but got %v", err)
+	}
+	if version := appliedVersion(t, db); version != latest {
+		t.Errorf("was expecting version %d after up but got %d", latest, version)
+	}
+	//running up again has nothing to do
+	err = migrator.Up(ctx)
+	if err != nil {
+		t.Errorf("was not expecting an error running up twice but got %v", err)
+	}
+
+	err = migrator.Down(ctx)
+	if err != nil {
+		t.Fatalf("was not expecting an error but got %v", err)
+	}
+	if version := appliedVersion(t, db); version != latest-1 {
+		t.Errorf("was expecting version %d after down but got %d", latest-1, version)
+	}
+	status.Reset()
+	migrator.Status(ctx, &status)
+	if strings.Count(status.String(), "pending") != 1 || strings.Count(status.String(), "applied") != int(latest-1) {
+		t.Errorf("was expecting only the newest migration to be pending but got\n%s", status.String())
+	}
+
+	//every migration can be rolled back and applied again
+	for version := latest - 1; version > 0; version-- {
+		err = migrator.Down(ctx)
+		if err != nil {
+			t.Fatalf("was not expecting an error rolling back %d but got %v", version, err)
+		}
+	}
+	if version := appliedVersion(t, db); version != 0 {
+		t.Errorf("was expecting every migration to be rolled back but got %d", version)
+	}
+	err = migrator.Up(ctx)
+	if err != nil {
+		t.Errorf("was not expecting an error but got %v", err)
+	}
+	if version := appliedVersion(t, db); version != latest {
+		t.Errorf("was expecting version %d after up but got %d", latest, version)
+	}
+}
+
+func appliedVersion(t *testing.T, db *sql.DB) int64 {
+	t.Helper()
+	var version int64
+	err := db.QueryRow("select coalesce(max(version_id), 0) from goose_db_version where is_applied").Scan(&version)
+	if err != nil {
+		t.Fatalf("could not read the migration version: %v", err)
+	}
+	return version
+}
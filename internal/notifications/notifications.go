@@ -0,0 +1,184 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/christianrm0821/Chirpy/internal/store"
+	"github.com/google/uuid"
+)
+
+// the kinds of notifications
+const (
+	Mention   = "mention"
+	ChirpyRed = "chirpy_red"
+	// a moderator resolved a chirp the user reported
+	ReportResolved = "report_resolved"
+)
+
+// every type in the order they are shown, all of them are on until the user turns them off
+var Types = []string{Mention, ChirpyRed, ReportResolved}
+
+// returned by SetPreferences for a type that does not exist
+var ErrUnknownType = errors.New("unknown notification type")
+
+// records notifications for users, respecting their preferences
+type Service struct {
+	store store.Store
+}
+
+func NewService(s store.Store) *Service {
+	return &Service{store: s}
+}
+
+// records that actor did something to userID, or to one of their chirps when chirpID is not Nil
+// nothing is recorded when users act on their own things, have turned the type off
+// or when either of them blocked the other
+func (s *Service) Notify(ctx context.Context, notificationType string, userID, actorID, chirpID uuid.UUID) error {
+	if actorID != uuid.Nil && actorID == userID {
+		return nil
+	}
+	if actorID != uuid.Nil {
+		blocked, err := s.store.IsBlocked(ctx, userID, actorID)
+		if err != nil {
+			return fmt.Errorf("could not check blocks: %w", err)
+		}
+		if blocked {
+			return nil
+		}
+	}
+	prefs, err := s.store.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("could not get preferences: %w", err)
+	}
+	if enabled, ok := prefs[notificationType]; ok && !enabled {
+		return nil
+	}
+
+	n := store.Notification{
+		UserID:  userID,
+		Type:    notificationType,
+		ActorID: uuid.NullUUID{UUID: actorID, Valid: actorID != uuid.Nil},
+		ChirpID: uuid.NullUUID{UUID: chirpID, Valid: chirpID != uuid.Nil},
+	}
+	_, err = s.store.AddNotification(ctx, n)
+	return err
+}
+
+// tells the users who reported a chirp that a moderator is done with it
+// chirpID is Nil when the chirp was deleted
+func (s *Service) ReportResolved(ctx context.Context, reporterIDs []uuid.UUID, chirpID uuid.UUID) error {
+	var errs []error
+	for _, userID := range reporterIDs {
+		err := s.Notify(ctx, ReportResolved, userID, uuid.Nil, chirpID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not notify %s: %w", userID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// notifies every user mentioned in a new chirp
+func (s *Service) ChirpPosted(ctx context.Context, chirp store.Chirp) error {
+	var errs []error
+	for _, handle := range Mentions(chirp.Body) {
+		user, err := s.store.GetUserByHandle(ctx, handle)
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		}
+		if err == nil {
+			err = s.Notify(ctx, Mention, user.ID, chirp.UserID, chirp.ID)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not notify @%s: %w", handle, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// tells the user their Chirpy Red subscription is active
+func (s *Service) UserUpgraded(ctx context.Context, userID uuid.UUID) error {
+	return s.Notify(ctx, ChirpyRed, userID, uuid.Nil, uuid.Nil)
+}
+
+// every type with whether the user gets it
+func (s *Service) Preferences(ctx context.Context, userID uuid.UUID) (map[string]bool, error) {
+	saved, err := s.store.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	prefs := map[string]bool{}
+	for _, notificationType := range Types {
+		enabled, ok := saved[notificationType]
+		prefs[notificationType] = enabled || !ok
+	}
+	return prefs, nil
+}
+
+// changes the types in prefs, the others stay as they are
+func (s *Service) SetPreferences(ctx context.Context, userID uuid.UUID, prefs map[string]bool) error {
+	for notificationType := range prefs {
+		if !IsType(notificationType) {
+			return fmt.Errorf("%w: %q", ErrUnknownType, notificationType)
+		}
+	}
+	for notificationType, enabled := range prefs {
+		err := s.store.SetNotificationPreference(ctx, userID, notificationType, enabled)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func IsType(notificationType string) bool {
+	for _, t := range Types {
+		if t == notificationType {
+			return true
+		}
+	}
+	return false
+}
+
+// what the notification says
+func Message(n store.Notification) string {
+	switch n.Type {
+	case Mention:
+		return "someone mentioned you"
+	case ChirpyRed:
+		return "your Chirpy Red subscription is active"
+	case ReportResolved:
+		return "a moderator reviewed a chirp you reported"
+	}
+	return n.Type
+}
+
+// what a handle can be, letters, digits and underscores
+var handlePattern = regexp.MustCompile(`^[a-z0-9_]{1,15}$`)
+
+// whether handle is allowed, it has to be lowercase already
+func IsHandle(handle string) bool {
+	return handlePattern.MatchString(handle)
+}
+
+// users are mentioned by handle, like @alice
+// the trailing @ picks up email addresses so they can be skipped
+var mentionPattern = regexp.MustCompile(`(?:^|\s|\()@(\w+)(@?)`)
+
+// the lowercase handles mentioned in a chirp body without duplicates
+func Mentions(body string) []string {
+	var handles []string
+	seen := map[string]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		handle := strings.ToLower(match[1])
+		if match[2] != "" || !IsHandle(handle) || seen[handle] {
+			continue
+		}
+		seen[handle] = true
+		handles = append(handles, handle)
+	}
+	return handles
+}
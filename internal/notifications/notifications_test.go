@@ -0,0 +1,31 @@
+package notifications
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/christianrm0821/Chirpy/internal/store"
+)
+
+func TestMentions(t *testing.T) {
+	got := Mentions("@alice and (@Bob), not me@example.com, @carol@example.com, @this_handle_is_too_long or @alice.")
+	want := []string{"alice", "bob"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("was expecting %v but got %v", want, got)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		n    store.Notification
+		want string
+	}{
+		{store.Notification{Type: Mention}, "someone mentioned you"},
+		{store.Notification{Type: ReportResolved}, "a moderator reviewed a chirp you reported"},
+	}
+	for _, test := range tests {
+		if got := Message(test.n); got != test.want {
+			t.Errorf("was expecting %q but got %q", test.want, got)
+		}
+	}
+}
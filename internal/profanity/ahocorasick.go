@@ -0,0 +1,105 @@
+package profanity
+
+import "sort"
+
+// finds every pattern in a text in one pass, however many patterns there are (aho-corasick)
+type automaton struct {
+	nodes []acNode
+	// the root has the most edges, it is looked up directly
+	root [256]int32
+}
+
+type acNode struct {
+	// sorted by byte, most nodes only have one or two
+	edges []acEdge
+	// the longest proper suffix of this node that is also in the trie
+	fail int32
+	// the patterns that end here, with the ones reached through fail
+	out []int32
+}
+
+type acEdge struct {
+	c  byte
+	to int32
+}
+
+func newAutomaton(patterns []string) *automaton {
+	a := &automaton{nodes: []acNode{{}}}
+	for i, pattern := range patterns {
+		n := int32(0)
+		for j := 0; j < len(pattern); j++ {
+			child, ok := a.step(n, pattern[j])
+			if !ok {
+				child = int32(len(a.nodes))
+				a.nodes = append(a.nodes, acNode{})
+				edges := append(a.nodes[n].edges, acEdge{c: pattern[j], to: child})
+				sort.Slice(edges, func(x, y int) bool { return edges[x].c < edges[y].c })
+				a.nodes[n].edges = edges
+				if n == 0 {
+					a.root[pattern[j]] = child
+				}
+			}
+			n = child
+		}
+		a.nodes[n].out = append(a.nodes[n].out, int32(i))
+	}
+
+	//breadth first so the fail node of a node is always done before it
+	queue := []int32{}
+	for _, e := range a.nodes[0].edges {
+		queue = append(queue, e.to)
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, e := range a.nodes[n].edges {
+			queue = append(queue, e.to)
+			f := a.nodes[n].fail
+			next, ok := a.step(f, e.c)
+			for !ok && f != 0 {
+				f = a.nodes[f].fail
+				next, ok = a.step(f, e.c)
+			}
+			if ok && next != e.to {
+				a.nodes[e.to].fail = next
+			}
+			a.nodes[e.to].out = append(a.nodes[e.to].out, a.nodes[a.nodes[e.to].fail].out...)
+		}
+	}
+	return a
+}
+
+// the node after n on c
+func (a *automaton) step(n int32, c byte) (int32, bool) {
+	if n == 0 {
+		next := a.root[c]
+		return next, next != 0
+	}
+	edges := a.nodes[n].edges
+	for i := range edges {
+		if edges[i].c == c {
+			return edges[i].to, true
+		}
+		if edges[i].c > c {
+			break
+		}
+	}
+	return 0, false
+}
+
+// calls found with the index of the pattern and the byte offset it ends at, in the order they end
+func (a *automaton) scan(text string, found func(pattern, end int)) {
+	n := int32(0)
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		next, ok := a.step(n, c)
+		for !ok && n != 0 {
+			n = a.nodes[n].fail
+			next, ok = a.step(n, c)
+		}
+		n = next
+		for _, pattern := range a.nodes[n].out {
+			found(int(pattern), i+1)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package profanity
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/christianrm0821/Chirpy/internal/store"
+)
+
+// the filter chirps went through before the terms were kept in the store, kept to compare against
+func validString(str string) string {
+	//what replaces bad words
+	const replacement = "****"
+
+	//Words not allowed
+	badWords := []string{"kerfuffle", "sharbert", "fornax"}
+
+	strList := strings.Split(str, " ")
+	for index, val := range strList {
+		for _, bad := range badWords {
+			if strings.EqualFold(val, bad) {
+				strList[index] = replacement
+				break
+			}
+		}
+	}
+	ans := strings.Join(strList, " ")
+
+	return ans
+}
+
+// about as long as a chirp can be
+var benchmarkChirps = map[string]string{
+	"plain":      "I had something interesting for breakfast and it was a kerfuffle, the toast burnt and the coffee spilled all over",
+	"obfuscated": "I had something interesting for breakfast and it was a k3rrfuff|e, the toast burnt and the f0rn4x coffee s h a r b e r t",
+}
+
+// the default terms and n-3 made up ones
+func benchmarkTerms(n int) []store.ProfanityTerm {
+	terms := []store.ProfanityTerm{{Term: "kerfuffle", Action: Mask}, {Term: "sharbert", Action: Mask}, {Term: "fornax", Action: Mask}}
+	r := rand.New(rand.NewSource(1))
+	for len(terms) < n {
+		word := make([]byte, 5+r.Intn(6))
+		for i := range word {
+			word[i] = byte('a' + r.Intn(26))
+		}
+		term := string(word)
+		switch r.Intn(10) {
+		case 0:
+			term += "*"
+		case 1:
+			term = "*" + term
+		case 2:
+			term += " " + term
+		}
+		terms = append(terms, store.ProfanityTerm{Term: term, Action: Mask})
+	}
+	return terms
+}
+
+func BenchmarkValidString(b *testing.B) {
+	for name, chirp := range benchmarkChirps {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				validString(chirp)
+			}
+		})
+	}
+}
+
+func BenchmarkApply(b *testing.B) {
+	for _, n := range []int{3, 100, 1000, 10000} {
+		m := NewMatcher(benchmarkTerms(n))
+		for name, chirp := range benchmarkChirps {
+			b.Run(fmt.Sprintf("terms=%d/%s", n, name), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					m.Apply(chirp)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkApplyWildcards(b *testing.B) {
+	m := NewMatcher([]store.ProfanityTerm{{Term: "*a*a*a*a*b*", Action: Mask}})
+	for _, n := range []int{140, 280, 1000} {
+		word := strings.Repeat("a", n)
+		b.Run(fmt.Sprintf("letters=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				m.Apply(word)
+			}
+		})
+	}
+}
+
+func BenchmarkNewMatcher(b *testing.B) {
+	for _, n := range []int{100, 1000, 10000} {
+		terms := benchmarkTerms(n)
+		b.Run(fmt.Sprintf("terms=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				NewMatcher(terms)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package profanity
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// letters from other scripts that look like latin ones, checked after lower casing
+// fullwidth and styled letters are already plain after compatibility decomposition
+var confusables = map[rune]rune{
+	//cyrillic
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'к': 'k', 'м': 'm', 'н': 'h', 'о': 'o', 'р': 'p',
+	'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'ѕ': 's', 'і': 'i', 'ј': 'j', 'ԁ': 'd', 'һ': 'h', 'ӏ': 'l',
+	//greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o', 'ρ': 'p',
+	'τ': 't', 'υ': 'u', 'χ': 'x', 'ω': 'w',
+	//latin lookalikes
+	'ı': 'i', 'ł': 'l', 'ø': 'o', 'đ': 'd',
+}
+
+// digits and symbols written in place of letters ("f0rn4x")
+func leet(r rune) (rune, bool) {
+	switch r {
+	case '0':
+		return 'o', true
+	case '1', '!':
+		return 'i', true
+	case '3':
+		return 'e', true
+	case '4', '@':
+		return 'a', true
+	case '5', '$':
+		return 's', true
+	case '7':
+		return 't', true
+	case '8':
+		return 'b', true
+	case '9':
+		return 'g', true
+	case '|':
+		return 'l', true
+	}
+	return r, false
+}
+
+// what can go between single letters that are spelled out ("k e r f u f f l e", "k.e.r.f")
+var spacers = map[rune]bool{' ': true, '.': true, '-': true, '_': true, '*': true, '·': true, '/': true}
+
+// how many spelled out letters in a row are read as one word
+const minSpelledOut = 3
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
+
+// zero-width and other invisible characters, they are skipped as if they weren't there
+func isIgnorable(r rune) bool {
+	return r >= utf8.RuneSelf && unicode.Is(unicode.Cf, r)
+}
+
+func isLeetSymbol(r rune) bool {
+	return r == '@' || r == '$' || r == '!' || r == '|'
+}
+
+// the letters of s as they are compared: no accents or invisible characters, lower case,
+// with lookalikes and leetspeak turned into the letters they stand for
+// a word of only digits is a number and keeps its digits ("8008" is not "boob")
+// a * is kept when keepStar is set, for the wildcards of terms
+func skeleton(s string, keepStar bool) string {
+	if !isASCII(s) {
+		s = norm.NFKD.String(s)
+	}
+	number := isNumber(s)
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if r >= utf8.RuneSelf {
+			if unicode.Is(unicode.Mn, r) || isIgnorable(r) {
+				continue
+			}
+			r = unicode.ToLower(r)
+			if mapped, ok := confusables[r]; ok {
+				r = mapped
+			}
+		} else if mapped, ok := leet(r); ok && !number {
+			r = mapped
+		} else if r == '*' && !keepStar {
+			continue
+		} else if 'A' <= r && r <= 'Z' {
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// s without repeated letters, anchors are found in this form so stretched words still have them
+// ("kerrrfuffle" is "kerfufle")
+func collapse(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	last := rune(-1)
+	for _, r := range s {
+		if r != last {
+			b.WriteRune(r)
+			last = r
+		}
+	}
+	return b.String()
+}
+
+// digits with nothing but invisible characters, marks and wildcards between them
+func isNumber(s string) bool {
+	digits := false
+	for _, r := range s {
+		switch {
+		case unicode.IsDigit(r):
+			digits = true
+		case r != '*' && !unicode.IsMark(r) && !isIgnorable(r):
+			return false
+		}
+	}
+	return digits
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// a word in the text, start and end are byte offsets into the text as written
+type token struct {
+	start, end int
+	// the skeleton of the word, and the same without repeated letters
+	full, skel string
+}
+
+func newToken(start, end int, word string) token {
+	full := skeleton(word, false)
+	return token{start: start, end: end, full: full, skel: collapse(full)}
+}
+
+// splits text into words, leet symbols and invisible characters inside a word are part of it
+// ("sh!t", "ker​fuffle") and spelled out letters are joined into one word
+func tokenize(text string) []token {
+	type span struct {
+		start, end int
+		// the runes that aren't invisible
+		letters int
+	}
+	var spans []span
+	cur := span{start: -1}
+	flush := func() {
+		if cur.start < 0 {
+			return
+		}
+		//! and | at the edges are punctuation, not letters
+		for cur.end > cur.start {
+			r, size := utf8.DecodeLastRuneInString(text[cur.start:cur.end])
+			if r != '!' && r != '|' && !isIgnorable(r) {
+				break
+			}
+			cur.end -= size
+		}
+		for cur.start < cur.end {
+			r, size := utf8.DecodeRuneInString(text[cur.start:cur.end])
+			if r != '!' && r != '|' && !isIgnorable(r) {
+				break
+			}
+			cur.start += size
+		}
+		for _, r := range text[cur.start:cur.end] {
+			if !isIgnorable(r) {
+				cur.letters++
+			}
+		}
+		if cur.letters > 0 {
+			spans = append(spans, cur)
+		}
+		cur = span{start: -1}
+	}
+	for i, r := range text {
+		if !isWordRune(r) && !isIgnorable(r) && !isLeetSymbol(r) {
+			flush()
+			continue
+		}
+		if cur.start < 0 {
+			cur.start = i
+		}
+		cur.end = i + utf8.RuneLen(r)
+	}
+	flush()
+
+	tokens := make([]token, 0, len(spans))
+	for i := 0; i < len(spans); {
+		//a run of single letters with one spacer between each
+		j := i
+		for j+1 < len(spans) && spans[j].letters == 1 && spans[j+1].letters == 1 && singleSpacer(text[spans[j].end:spans[j+1].start]) {
+			j++
+		}
+		if j-i+1 >= minSpelledOut {
+			var letters strings.Builder
+			for _, s := range spans[i : j+1] {
+				letters.WriteString(text[s.start:s.end])
+			}
+			tokens = append(tokens, newToken(spans[i].start, spans[j].end, letters.String()))
+			i = j + 1
+			continue
+		}
+		tokens = append(tokens, newToken(spans[i].start, spans[i].end, text[spans[i].start:spans[i].end]))
+		i++
+	}
+	return tokens
+}
+
+func singleSpacer(gap string) bool {
+	r, size := utf8.DecodeRuneInString(gap)
+	return size == len(gap) && spacers[r]
+}
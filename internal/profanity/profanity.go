@@ -0,0 +1,348 @@
+// the profanity filter chirps and messages go through, the terms are kept in the store
+package profanity
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/christianrm0821/Chirpy/internal/store"
+	"golang.org/x/text/unicode/norm"
+)
+
+// what happens when a term is found
+const (
+	// the words are replaced by ****
+	Mask = "mask"
+	// the chirp is not posted
+	Reject = "reject"
+	// the chirp is posted as written and flagged for moderators
+	Moderate = "moderate"
+)
+
+var Actions = []string{Mask, Reject, Moderate}
+
+// what masked words are replaced with
+const replacement = "****"
+
+// the longest term, in characters
+const MaxTermLength = 100
+
+// the form terms are saved in: normalized, lower case and single spaced
+// returns why the term can't be used, "" when it can
+//
+// a term is one or more words, a * in a word matches any letters ("darn*")
+func ParseTerm(term string) (string, string) {
+	words := strings.Fields(fold(term))
+	if len(words) == 0 {
+		return "", "is required"
+	}
+	for _, word := range words {
+		if strings.Trim(word, "*") == "" {
+			return "", "words can't be only *"
+		}
+		for _, r := range word {
+			if r != '*' && !isWordRune(r) {
+				return "", "can only have letters, digits, spaces and *"
+			}
+		}
+	}
+	normalized := strings.Join(words, " ")
+	if len([]rune(normalized)) > MaxTermLength {
+		return "", "is too long"
+	}
+	return normalized, ""
+}
+
+// the text after the terms were applied
+type Result struct {
+	// the text with the mask terms replaced
+	Text string
+	// the reject terms that were found
+	Rejected []string
+	// the moderate terms that were found
+	Flagged []string
+}
+
+// matches text against a fixed list of terms
+//
+// text and terms are compared by their skeletons (see skeleton), so spellings meant to get past
+// the filter still match: "f0rn4x", "kerrrfuffle", "k e r f u f f l e", zero-width characters
+// and letters from other scripts that look like latin ones
+// a term only matches whole words, with every letter at least as often as in the term ("as" is not "ass")
+type Matcher struct {
+	terms []term
+	// finds the anchor of every term in one pass over the text
+	automaton *automaton
+	// the terms with each anchor, by the anchor's pattern index
+	anchors [][]anchor
+}
+
+type term struct {
+	text   string
+	action string
+	// one pattern per word
+	words []wordPattern
+}
+
+// the longest run of letters in a term without a *, a term can only match where its anchor is found
+type anchor struct {
+	term int
+	// the word of the term the anchor is in
+	word int
+}
+
+// terms that don't parse are left out
+func NewMatcher(terms []store.ProfanityTerm) *Matcher {
+	m := &Matcher{}
+	var patterns []string
+	patternIndex := map[string]int{}
+	for _, t := range terms {
+		normalized, problem := ParseTerm(t.Term)
+		if problem != "" {
+			continue
+		}
+		found := term{text: t.Term, action: t.Action}
+		best, bestWord := "", 0
+		for i, word := range strings.Fields(normalized) {
+			pattern := skeleton(word, true)
+			found.words = append(found.words, newWordPattern(pattern))
+			for _, part := range strings.Split(collapse(pattern), "*") {
+				if len(part) > len(best) {
+					best, bestWord = part, i
+				}
+			}
+		}
+		//only marks, nothing is left to match
+		if best == "" {
+			continue
+		}
+		i, ok := patternIndex[best]
+		if !ok {
+			i = len(patterns)
+			patternIndex[best] = i
+			patterns = append(patterns, best)
+			m.anchors = append(m.anchors, nil)
+		}
+		m.anchors[i] = append(m.anchors[i], anchor{term: len(m.terms), word: bestWord})
+		m.terms = append(m.terms, found)
+	}
+	m.automaton = newAutomaton(patterns)
+	return m
+}
+
+// finds the terms in text, everything that isn't a masked word is kept as written ("Kerfuffle!" is "****!")
+// where terms overlap the one with the most words wins
+func (m *Matcher) Apply(text string) Result {
+	tokens := tokenize(text)
+	//the best term starting at each token, -1 for none
+	best := make([]int, len(tokens))
+	for i := range best {
+		best[i] = -1
+	}
+	if len(m.terms) > 0 {
+		//anchors never have a space so they are only found inside a word
+		var joined strings.Builder
+		starts := make([]int, len(tokens))
+		for i, tok := range tokens {
+			if i > 0 {
+				joined.WriteByte(' ')
+			}
+			starts[i] = joined.Len()
+			joined.WriteString(tok.skel)
+		}
+		current := 0
+		m.automaton.scan(joined.String(), func(pattern, end int) {
+			for current+1 < len(starts) && starts[current+1] < end {
+				current++
+			}
+			for _, a := range m.anchors[pattern] {
+				t := &m.terms[a.term]
+				start := current - a.word
+				if start < 0 || !t.matchesAt(tokens, start) {
+					continue
+				}
+				if b := best[start]; b < 0 || len(t.words) > len(m.terms[b].words) || (len(t.words) == len(m.terms[b].words) && a.term < b) {
+					best[start] = a.term
+				}
+			}
+		})
+	}
+
+	result := Result{}
+	var out strings.Builder
+	written := 0
+	for i := 0; i < len(tokens); {
+		if best[i] < 0 {
+			i++
+			continue
+		}
+		found := &m.terms[best[i]]
+		first, last := tokens[i], tokens[i+len(found.words)-1]
+		switch found.action {
+		case Mask:
+			out.WriteString(text[written:first.start])
+			out.WriteString(replacement)
+			written = last.end
+		case Reject:
+			result.Rejected = appendUnique(result.Rejected, found.text)
+		case Moderate:
+			result.Flagged = appendUnique(result.Flagged, found.text)
+		}
+		i += len(found.words)
+	}
+	out.WriteString(text[written:])
+	result.Text = out.String()
+	return result
+}
+
+func (t *term) matchesAt(tokens []token, i int) bool {
+	if i+len(t.words) > len(tokens) {
+		return false
+	}
+	for j, pattern := range t.words {
+		if !pattern.match([]rune(tokens[i+j].full)) {
+			return false
+		}
+	}
+	return true
+}
+
+// the skeleton of a term word as runs of the same letter
+// a word matches when it has every run with at least as many letters, and anything for a *
+// so "kerrrfuuuffle" is "kerfuffle" but "kerfufle" is not
+type wordPattern []letterRun
+
+type letterRun struct {
+	r rune
+	n int
+}
+
+func newWordPattern(skel string) wordPattern {
+	var p wordPattern
+	for _, r := range skel {
+		if len(p) > 0 && p[len(p)-1].r == r && r != '*' {
+			p[len(p)-1].n++
+			continue
+		}
+		if r == '*' && len(p) > 0 && p[len(p)-1].r == '*' {
+			continue
+		}
+		p = append(p, letterRun{r: r, n: 1})
+	}
+	return p
+}
+
+func (p wordPattern) match(word []rune) bool {
+	if !slices.ContainsFunc(p, func(run letterRun) bool { return run.r == '*' }) {
+		i := 0
+		for _, run := range p {
+			same := 0
+			for i+same < len(word) && word[i+same] == run.r {
+				same++
+			}
+			if same < run.n {
+				return false
+			}
+			i += same
+		}
+		return i == len(word)
+	}
+
+	//with a * there is a choice of where it ends, so this goes over (pattern, word) positions once
+	//instead of trying every choice, which blows up with a few * on a long word
+	//same[i] is how many times word[i] repeats from i
+	same := make([]int, len(word)+1)
+	for i := len(word) - 1; i >= 0; i-- {
+		same[i] = 1
+		if i+1 < len(word) && word[i+1] == word[i] {
+			same[i] = same[i+1] + 1
+		}
+	}
+	//next[i] is whether p[k+1:] matches word[i:], cur is the same for p[k:]
+	next := make([]bool, len(word)+1)
+	cur := make([]bool, len(word)+1)
+	next[len(word)] = true
+	for k := len(p) - 1; k >= 0; k-- {
+		run := p[k]
+		for i := len(word); i >= 0; i-- {
+			switch {
+			case run.r == '*':
+				cur[i] = next[i] || (i < len(word) && cur[i+1])
+			case i == len(word) || word[i] != run.r || same[i] < run.n:
+				cur[i] = false
+			case k+1 < len(p) && p[k+1].r == '*':
+				//a * after the run can take the rest of it, otherwise the run is all of it
+				cur[i] = next[i+run.n]
+			default:
+				cur[i] = next[i+same[i]]
+			}
+		}
+		next, cur = cur, next
+	}
+	return next[0]
+}
+
+// keeps the matcher for the terms in the store, reloading them when they are older than maxAge
+// so terms changed on another instance are picked up
+type Filter struct {
+	store  store.Store
+	maxAge time.Duration
+
+	mu       sync.Mutex
+	matcher  *Matcher
+	loadedAt time.Time
+}
+
+// a maxAge of 0 only loads the terms once and when Reload is called
+func NewFilter(s store.Store, maxAge time.Duration) *Filter {
+	return &Filter{store: s, maxAge: maxAge}
+}
+
+// loads the terms from the store now
+func (f *Filter) Reload(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.reload(ctx)
+}
+
+// callers hold the lock
+func (f *Filter) reload(ctx context.Context) error {
+	terms, err := f.store.ListProfanityTerms(ctx)
+	//a failed load is retried after maxAge, not on every chirp
+	f.loadedAt = time.Now()
+	if err != nil {
+		return fmt.Errorf("could not load profanity terms: %w", err)
+	}
+	f.matcher = NewMatcher(terms)
+	return nil
+}
+
+// the current matcher, when reloading fails the last one is returned with the error
+// it is only nil when the terms were never loaded
+func (f *Filter) Matcher(ctx context.Context) (*Matcher, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.matcher == nil || (f.maxAge > 0 && time.Since(f.loadedAt) >= f.maxAge) {
+		err := f.reload(ctx)
+		return f.matcher, err
+	}
+	return f.matcher, nil
+}
+
+// compatibility normalization makes fullwidth and styled letters plain ("ｋｅｒｆｕｆｆｌｅ" is "kerfuffle")
+func fold(s string) string {
+	return strings.ToLower(norm.NFKC.String(s))
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, have := range list {
+		if have == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
@@ -0,0 +1,137 @@
+package profanity
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christianrm0821/Chirpy/internal/store"
+)
+
+func TestApply(t *testing.T) {
+	m := NewMatcher([]store.ProfanityTerm{
+		{Term: "kerfuffle", Action: Mask},
+		{Term: "sharbert", Action: Mask},
+		{Term: "what the *fuffle", Action: Reject},
+		{Term: "darn*", Action: Mask},
+		{Term: "*bert*", Action: Moderate},
+	})
+	tests := []struct {
+		text string
+		want Result
+	}{
+		{"what a Kerfuffle!", Result{Text: "what a ****!"}},
+		{"kerfuffle\nsharbert, fine", Result{Text: "****\n****, fine"}},
+		{"ｋｅｒｆｕｆｆｌｅ", Result{Text: "****"}},
+		{"darnit DARNED darn", Result{Text: "**** **** ****"}},
+		{"what the kerfuffle", Result{Text: "what the kerfuffle", Rejected: []string{"what the *fuffle"}}},
+		{"roberto and alberta", Result{Text: "roberto and alberta", Flagged: []string{"*bert*"}}},
+		{"kerfuffles are fine", Result{Text: "kerfuffles are fine"}},
+	}
+	for _, test := range tests {
+		if got := m.Apply(test.text); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q: was expecting %+v but got %+v", test.text, test.want, got)
+		}
+	}
+}
+
+func TestApplyObfuscated(t *testing.T) {
+	m := NewMatcher([]store.ProfanityTerm{
+		{Term: "kerfuffle", Action: Mask},
+		{Term: "fornax", Action: Mask},
+		{Term: "sharbert", Action: Reject},
+		{Term: "ass", Action: Mask},
+		{Term: "boob", Action: Mask},
+	})
+	tests := []struct {
+		text string
+		want Result
+	}{
+		{"f0rn4x!", Result{Text: "****!"}},
+		{"kerrrfuuuffle", Result{Text: "****"}},
+		{"ker​fuf‍fle", Result{Text: "****"}},
+		{"k e r f u f f l e, ok", Result{Text: "****, ok"}},
+		{"f.o.r.n.a.x", Result{Text: "****"}},
+		//cyrillic а and е
+		{"kеrfufflе fornаx", Result{Text: "**** ****"}},
+		{"kérfüffle", Result{Text: "****"}},
+		{"$h@rb3rt", Result{Text: "$h@rb3rt", Rejected: []string{"sharbert"}}},
+		{"@$$", Result{Text: "****"}},
+		//words that only have a term inside them are fine
+		{"a classic assessment", Result{Text: "a classic assessment"}},
+		{"a b c", Result{Text: "a b c"}},
+		{"hi! 1 2 3", Result{Text: "hi! 1 2 3"}},
+		{"b00b and b o o o b", Result{Text: "**** and ****"}},
+		{"asss", Result{Text: "****"}},
+		//fewer letters than the term is a different word, and numbers are not letters
+		{"as good as it gets", Result{Text: "as good as it gets"}},
+		{"Bob is here", Result{Text: "Bob is here"}},
+		{"I have 8 0 0 8 apples", Result{Text: "I have 8 0 0 8 apples"}},
+		{"call 8008", Result{Text: "call 8008"}},
+		{"a kerfufle", Result{Text: "a kerfufle"}},
+	}
+	for _, test := range tests {
+		if got := m.Apply(test.text); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q: was expecting %+v but got %+v", test.text, test.want, got)
+		}
+	}
+}
+
+// every * could end anywhere in the word, trying each way took minutes on a word this long
+func TestApplyManyWildcards(t *testing.T) {
+	m := NewMatcher([]store.ProfanityTerm{{Term: "*a*a*a*a*b*", Action: Mask}})
+	long := strings.Repeat("a", 1000)
+	if got := m.Apply(long); got.Text != long {
+		t.Errorf("was expecting a word without b to be left alone but got %q", got.Text)
+	}
+	if got := m.Apply(long + "b"); got.Text != "****" {
+		t.Errorf("was expecting the word to be masked but got %q", got.Text)
+	}
+}
+
+func TestParseTerm(t *testing.T) {
+	tests := []struct {
+		term, want string
+		ok         bool
+	}{
+		{"  What  THE heck ", "what the heck", true},
+		{"darn*", "darn*", true},
+		{"**", "", false},
+		{"f-word", "", false},
+		{"", "", false},
+	}
+	for _, test := range tests {
+		got, problem := ParseTerm(test.term)
+		if got != test.want || (problem == "") != test.ok {
+			t.Errorf("%q: was expecting %q (ok %v) but got %q %q", test.term, test.want, test.ok, got, problem)
+		}
+	}
+}
+
+func TestFilterReloads(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemory()
+	f := NewFilter(s, 20*time.Millisecond)
+	apply := func() string {
+		t.Helper()
+		m, err := f.Matcher(ctx)
+		if err != nil {
+			t.Fatalf("could not load terms: %v", err)
+		}
+		return m.Apply("gosh kerfuffle").Text
+	}
+	if got := apply(); got != "gosh ****" {
+		t.Errorf("was expecting the default terms but got %q", got)
+	}
+	//another instance adds a term
+	s.CreateProfanityTerm(ctx, "gosh", Mask)
+	if got := apply(); got != "gosh ****" {
+		t.Errorf("was expecting the loaded terms to be kept until they are old but got %q", got)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if got := apply(); got != "**** ****" {
+		t.Errorf("was expecting the new term after the reload interval but got %q", got)
+	}
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// middle ware that adds 1 to the amounts of request and then serves the given http request
+// Haddlerfunc is different from handlefunc
+func (s *Server) middlewareMetricsInc(next http.Handler) http.Handler {
+	newHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.fileserverHits.Add(1)
+		next.ServeHTTP(w, r)
+	})
+	return newHandler
+}
+
+// prints out the number of request made
+func (s *Server) handlerMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+	value := s.fileserverHits.Load()
+	fmt.Fprintf(w, "<html><body><h1>Welcome, Chirpy Admin</h1><p>Chirpy has been visited %d times!</p></body></html>", value)
+}
+
+// resets the number of requests made
+func (s *Server) handlerReset(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.Platform != "dev" {
+		s.respondWithError(w, r, errForbidden("reset is only allowed on the dev platform"))
+		return
+	}
+	s.fileserverHits.Store(0)
+	err := s.store.DeleteAllUsers(r.Context())
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("error with reset: %w", err))
+		return
+	}
+}
+
+// middleware that stops a handler from reading more than maxBytes of the request body
+func middlewareMaxBody(maxBytes int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+		next.ServeHTTP(w, r)
+	})
+}
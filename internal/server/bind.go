@@ -0,0 +1,217 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/christianrm0821/Chirpy/internal/store"
+	"github.com/google/uuid"
+)
+
+// bcrypt ignores everything after 72 bytes and errors on longer passwords
+const maxPasswordBytes = 72
+
+// chirp length limits used when Config leaves them at 0, see chirpLengthLimit
+const (
+	defaultMaxChirpLength    = 140
+	defaultMaxChirpLengthRed = 280
+)
+
+// the most bytes a chirp can have whatever its length in characters,
+// combining marks and zero-width joiners add bytes but no characters
+// and chirps have to fit in a postgres NOTIFY (8000 bytes) to reach the other instances
+const maxChirpBytes = 4000
+
+// request bodies that can check their own fields after being decoded
+type validator interface {
+	validate() []fieldError
+}
+
+// decodes the json request body into v without being picky about extra fields
+// used for bodies we don't control, like the polka webhooks
+func decodeJSON(r *http.Request, v any) error {
+	return decodeBody(r, v, false)
+}
+
+// strictly decodes the json request body into v and validates it
+// unknown fields and trailing data are a 400, fields that fail validation are a 422
+func bindJSON(r *http.Request, v validator) error {
+	err := decodeBody(r, v, true)
+	if err != nil {
+		return err
+	}
+	if details := v.validate(); len(details) > 0 {
+		return errValidation(details...)
+	}
+	return nil
+}
+
+func decodeBody(r *http.Request, v any, strict bool) error {
+	decoder := json.NewDecoder(r.Body)
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	err := decoder.Decode(v)
+	if err == nil && strict && decoder.More() {
+		err = errors.New("request body must only contain a single json object")
+	}
+	if err == nil {
+		return nil
+	}
+
+	if apiErr := errBodyTooLarge(err); apiErr != nil {
+		return apiErr
+	}
+
+	apiErr := &apiError{Status: http.StatusBadRequest, Code: codeInvalidJSON, Message: "request body is not valid json", Err: err}
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		apiErr.Message = "request body is empty"
+	case errors.As(err, &typeErr):
+		apiErr.Details = []fieldError{{Field: typeErr.Field, Message: "must be a " + typeErr.Type.String()}}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field, _ := strconv.Unquote(strings.TrimPrefix(err.Error(), "json: unknown field "))
+		apiErr.Details = []fieldError{{Field: field, Message: "unknown field"}}
+	}
+	return apiErr
+}
+
+// returns a 413 if err came from reading past the MaxBytesReader limit, nil otherwise
+func errBodyTooLarge(err error) *apiError {
+	var maxBytesErr *http.MaxBytesError
+	if !errors.As(err, &maxBytesErr) {
+		return nil
+	}
+	return &apiError{
+		Status:  http.StatusRequestEntityTooLarge,
+		Code:    codeBodyTooLarge,
+		Message: fmt.Sprintf("request body is larger than %d bytes", maxBytesErr.Limit),
+		Err:     err,
+	}
+}
+
+// parses a uuid from a path, query or body parameter, name is used in the error details
+func parseUUID(name, value string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, errInvalidParam(name, "must be a valid uuid", err)
+	}
+	return id, nil
+}
+
+// the query parameters GET /api/chirps accepts
+// limit and cursor are only read in v2, v1 always returns every chirp
+type listChirpsQuery struct {
+	AuthorID uuid.UUID
+	Desc     bool
+	Limit    int
+	After    store.ChirpCursor
+}
+
+func errInvalidParam(name, msg string, err error) *apiError {
+	return &apiError{
+		Status:  http.StatusBadRequest,
+		Code:    codeInvalidParam,
+		Message: "invalid " + name,
+		Details: []fieldError{{Field: name, Message: msg}},
+		Err:     err,
+	}
+}
+
+func bindListChirpsQuery(r *http.Request) (listChirpsQuery, error) {
+	query := listChirpsQuery{}
+	if authorID := r.URL.Query().Get("author_id"); authorID != "" {
+		id, err := parseUUID("author_id", authorID)
+		if err != nil {
+			return query, err
+		}
+		query.AuthorID = id
+	}
+	switch sort := r.URL.Query().Get("sort"); sort {
+	case "", "asc":
+	case "desc":
+		query.Desc = true
+	default:
+		return query, errInvalidParam("sort", "must be asc or desc", nil)
+	}
+
+	if apiVersion(r) < apiV2 {
+		return query, nil
+	}
+	page, err := bindPageQuery(r)
+	if err != nil {
+		return query, err
+	}
+	query.Limit = page.Limit
+	query.After = store.ChirpCursor{CreatedAt: page.AfterTime, ID: page.AfterID}
+	return query, nil
+}
+
+// limit and cursor of a paginated list
+type pageQuery struct {
+	Limit     int
+	AfterTime time.Time
+	AfterID   uuid.UUID
+}
+
+func bindPageQuery(r *http.Request) (pageQuery, error) {
+	page := pageQuery{Limit: defaultPageSize}
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxPageSize {
+			return page, errInvalidParam("limit", fmt.Sprintf("must be a number from 1 to %d", maxPageSize), err)
+		}
+		page.Limit = n
+	}
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		at, id, err := decodeCursor(cursor)
+		if err != nil {
+			return page, errInvalidParam("cursor", "must be a next_cursor returned by this endpoint", err)
+		}
+		page.AfterTime, page.AfterID = at, id
+	}
+	return page, nil
+}
+
+// checks the email looks like a plain address, "Name <a@b.com>" is not allowed
+func validateEmail(field, value string) []fieldError {
+	if value == "" {
+		return []fieldError{{Field: field, Message: "is required"}}
+	}
+	addr, err := mail.ParseAddress(value)
+	if err != nil || addr.Address != value {
+		return []fieldError{{Field: field, Message: "must be a valid email address"}}
+	}
+	return nil
+}
+
+func validatePassword(field, value string) []fieldError {
+	if value == "" {
+		return []fieldError{{Field: field, Message: "is required"}}
+	}
+	if len(value) > maxPasswordBytes {
+		return []fieldError{{Field: field, Message: fmt.Sprintf("must be at most %d bytes", maxPasswordBytes)}}
+	}
+	return nil
+}
+
+func (req email) validate() []fieldError {
+	details := validateEmail("email", req.Email)
+	return append(details, validatePassword("password", req.Password)...)
+}
+
+func (req chirpPostReq) validate() []fieldError {
+	if strings.TrimSpace(req.Body) == "" {
+		return []fieldError{{Field: "body", Message: "is required"}}
+	}
+	//the length limit depends on the author, it is checked in handlerCreateChirp
+	return nil
+}
@@ -0,0 +1,207 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/christianrm0821/Chirpy/internal/store"
+	"github.com/google/uuid"
+)
+
+type blockReq struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (req blockReq) validate() []fieldError {
+	if req.UserID == uuid.Nil {
+		return []fieldError{{Field: "user_id", Message: "is required"}}
+	}
+	return nil
+}
+
+type muteReq struct {
+	UserID uuid.UUID `json:"user_id"`
+	// the mute ends at this time, it lasts until it is removed when missing
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+func (req muteReq) validate() []fieldError {
+	if req.UserID == uuid.Nil {
+		return []fieldError{{Field: "user_id", Message: "is required"}}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return []fieldError{{Field: "expires_at", Message: "must be in the future"}}
+	}
+	return nil
+}
+
+type blockRes struct {
+	UserID    uuid.UUID `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type muteRes struct {
+	UserID    uuid.UUID  `json:"user_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// the user a block or mute is for, they have to exist and can't be the one asking
+func (s *Server) otherUser(r *http.Request, userID, otherID uuid.UUID) error {
+	if otherID == userID {
+		return errValidation(fieldError{Field: "user_id", Message: "can't be you"})
+	}
+	_, err := s.store.GetUserByID(r.Context(), otherID)
+	if errors.Is(err, store.ErrNotFound) {
+		return errValidation(fieldError{Field: "user_id", Message: fmt.Sprintf("user %s does not exist", otherID)})
+	}
+	if err != nil {
+		return fmt.Errorf("could not get user: %w", err)
+	}
+	return nil
+}
+
+// blocked users can't see each other's chirps, mention each other or send each other messages
+func (s *Server) handlerBlockUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticateActive(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	request := blockReq{}
+	err = bindJSON(r, &request)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	err = s.otherUser(r, userID, request.UserID)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	err = s.store.BlockUser(r.Context(), userID, request.UserID)
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not block user: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handlerListBlocks(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticate(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	blocks, err := s.store.ListBlocks(r.Context(), userID)
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not list blocks: %w", err))
+		return
+	}
+	res := []blockRes{}
+	for _, block := range blocks {
+		res = append(res, blockRes{UserID: block.BlockedID, CreatedAt: block.CreatedAt})
+	}
+	respondWithData(w, r, 200, res)
+}
+
+func (s *Server) handlerUnblockUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticateActive(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	blockedID, err := parseUUID("userID", r.PathValue("userID"))
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	err = s.store.UnblockUser(r.Context(), userID, blockedID)
+	if errors.Is(err, store.ErrNotFound) {
+		s.respondWithError(w, r, errNotFound("block", err))
+		return
+	}
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not unblock user: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// muted users are only hidden from the muter's timelines, muting again changes the expiry
+func (s *Server) handlerMuteUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticateActive(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	request := muteReq{}
+	err = bindJSON(r, &request)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	err = s.otherUser(r, userID, request.UserID)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	mute := store.Mute{MuterID: userID, MutedID: request.UserID}
+	if request.ExpiresAt != nil {
+		mute.ExpiresAt.Time, mute.ExpiresAt.Valid = *request.ExpiresAt, true
+	}
+	err = s.store.MuteUser(r.Context(), mute)
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not mute user: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// the mutes that haven't expired
+func (s *Server) handlerListMutes(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticate(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	mutes, err := s.store.ListMutes(r.Context(), userID, time.Now())
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not list mutes: %w", err))
+		return
+	}
+	res := []muteRes{}
+	for _, mute := range mutes {
+		muteRes := muteRes{UserID: mute.MutedID, CreatedAt: mute.CreatedAt}
+		if mute.ExpiresAt.Valid {
+			muteRes.ExpiresAt = &mute.ExpiresAt.Time
+		}
+		res = append(res, muteRes)
+	}
+	respondWithData(w, r, 200, res)
+}
+
+func (s *Server) handlerUnmuteUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticateActive(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	mutedID, err := parseUUID("userID", r.PathValue("userID"))
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	err = s.store.UnmuteUser(r.Context(), userID, mutedID)
+	if errors.Is(err, store.ErrNotFound) {
+		s.respondWithError(w, r, errNotFound("mute", err))
+		return
+	}
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not unmute user: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
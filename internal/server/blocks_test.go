@@ -0,0 +1,104 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestBlocksAndMutes(t *testing.T) {
+	c := newTestClient(t, Config{})
+	alice := c.login("alice@example.com", "hunter2")
+	bob := c.login("bob@example.com", "password")
+	c.do("PUT", "/api/v1/users/handle", alice.Token, handleReq{Handle: "alice"}, nil)
+	carol := c.login("carol@example.com", "secret")
+
+	bobChirp := validChirp{}
+	c.do("POST", "/api/v1/chirps", bob.Token, chirpPostReq{Body: "hello from bob"}, &bobChirp)
+	c.do("POST", "/api/v1/chirps", carol.Token, chirpPostReq{Body: "hello from carol"}, nil)
+
+	code := c.do("POST", "/api/v1/blocks", alice.Token, blockReq{UserID: bob.ID}, nil)
+	if code != http.StatusNoContent {
+		t.Fatalf("was expecting 204 blocking bob but got %d", code)
+	}
+	var chirps []validChirp
+	c.do("GET", "/api/v1/chirps", alice.Token, nil, &chirps)
+	if len(chirps) != 1 || chirps[0].UserID != carol.ID {
+		t.Errorf("was expecting only carol's chirp for alice but got %+v", chirps)
+	}
+	c.do("GET", "/api/v1/chirps", "", nil, &chirps)
+	if len(chirps) != 2 {
+		t.Errorf("was expecting every chirp without a token but got %+v", chirps)
+	}
+	code = c.do("GET", "/api/v1/chirps/"+bobChirp.ID.String(), alice.Token, nil, nil)
+	if code != http.StatusNotFound {
+		t.Errorf("was expecting 404 for a blocked user's chirp but got %d", code)
+	}
+	//blocks work both ways
+	code = c.do("POST", "/api/v1/conversations", bob.Token, conversationCreateReq{MemberIDs: []uuid.UUID{alice.ID}}, nil)
+	if code != http.StatusForbidden {
+		t.Errorf("was expecting 403 messaging someone who blocked you but got %d", code)
+	}
+	c.do("POST", "/api/v1/chirps", bob.Token, chirpPostReq{Body: "hi @alice"}, nil)
+	list := notificationList{}
+	c.do("GET", "/api/v1/notifications", alice.Token, nil, &list)
+	if len(list.Notifications) != 0 {
+		t.Errorf("was expecting no mention from a blocked user but got %+v", list)
+	}
+
+	blocks := []blockRes{}
+	c.do("GET", "/api/v1/blocks", alice.Token, nil, &blocks)
+	if len(blocks) != 1 || blocks[0].UserID != bob.ID {
+		t.Errorf("was expecting bob to be blocked but got %+v", blocks)
+	}
+	code = c.do("DELETE", "/api/v1/blocks/"+bob.ID.String(), alice.Token, nil, nil)
+	if code != http.StatusNoContent {
+		t.Errorf("was expecting 204 unblocking bob but got %d", code)
+	}
+	code = c.do("DELETE", "/api/v1/blocks/"+bob.ID.String(), alice.Token, nil, nil)
+	if code != http.StatusNotFound {
+		t.Errorf("was expecting 404 unblocking twice but got %d", code)
+	}
+
+	expiresAt := time.Now().Add(time.Hour)
+	code = c.do("POST", "/api/v1/mutes", alice.Token, muteReq{UserID: carol.ID, ExpiresAt: &expiresAt}, nil)
+	if code != http.StatusNoContent {
+		t.Fatalf("was expecting 204 muting carol but got %d", code)
+	}
+	c.do("GET", "/api/v1/chirps", alice.Token, nil, &chirps)
+	for _, chirp := range chirps {
+		if chirp.UserID == carol.ID {
+			t.Errorf("was expecting carol's chirps to be hidden but got %+v", chirps)
+		}
+	}
+	//asking for her chirps still shows them
+	c.do("GET", "/api/v1/chirps?author_id="+carol.ID.String(), alice.Token, nil, &chirps)
+	if len(chirps) != 1 {
+		t.Errorf("was expecting carol's chirp by author but got %+v", chirps)
+	}
+	mutes := []muteRes{}
+	c.do("GET", "/api/v1/mutes", alice.Token, nil, &mutes)
+	if len(mutes) != 1 || mutes[0].UserID != carol.ID || mutes[0].ExpiresAt == nil {
+		t.Errorf("was expecting carol to be muted for an hour but got %+v", mutes)
+	}
+	code = c.do("DELETE", "/api/v1/mutes/"+carol.ID.String(), alice.Token, nil, nil)
+	if code != http.StatusNoContent {
+		t.Errorf("was expecting 204 unmuting carol but got %d", code)
+	}
+
+	code = c.do("POST", "/api/v1/blocks", alice.Token, blockReq{UserID: alice.ID}, nil)
+	if code != http.StatusUnprocessableEntity {
+		t.Errorf("was expecting 422 blocking yourself but got %d", code)
+	}
+	past := time.Now().Add(-time.Hour)
+	code = c.do("POST", "/api/v1/mutes", alice.Token, muteReq{UserID: bob.ID, ExpiresAt: &past}, nil)
+	if code != http.StatusUnprocessableEntity {
+		t.Errorf("was expecting 422 for a mute that already ended but got %d", code)
+	}
+	code = c.do("GET", "/api/chirps", "not-a-token", nil, nil)
+	if code != http.StatusUnauthorized {
+		t.Errorf("was expecting 401 listing chirps with a bad token but got %d", code)
+	}
+}
@@ -0,0 +1,223 @@
+package server
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/christianrm0821/Chirpy/internal/auth"
+	"github.com/christianrm0821/Chirpy/internal/events"
+	"github.com/christianrm0821/Chirpy/internal/filters"
+	"github.com/christianrm0821/Chirpy/internal/store"
+	"github.com/christianrm0821/Chirpy/internal/textlen"
+	"golang.org/x/text/unicode/norm"
+)
+
+// makes sure the chirp is valid, cleans bad words and saves it
+// chirps with moderate terms are saved and put in the moderation queue
+func (s *Server) handlerCreateChirp(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		s.respondWithError(w, r, errUnauthorized(err))
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, s.cfg.Secret)
+	if err != nil {
+		s.requestLog(r).Info("rejected chirp token", "error", err)
+		s.respondWithError(w, r, errUnauthorized(err))
+		return
+	}
+	setRequestUser(r, userID)
+	user, err := s.activeUser(r, userID)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+
+	//get the information and putting it into request
+	request := chirpPostReq{}
+	err = bindJSON(r, &request)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	//the chirp is saved the way its length was counted
+	request.Body = norm.NFC.String(request.Body)
+	err = s.checkChirpLength(user, request.Body)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+
+	//masks bad words and rejects chirps with banned ones
+	checked, err := s.checkProfanity(r, "body", request.Body)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+
+	myChirp, err := s.store.CreateChirp(r.Context(), userID, checked.Text)
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("error creating chirp: %w", err))
+		return
+	}
+	if len(checked.Flagged) > 0 {
+		//the chirp is up either way, a moderator just won't hear about it
+		err = s.flagChirp(r, myChirp, checked.Flagged)
+		if err != nil {
+			s.requestLog(r).Error("could not flag chirp", "chirp_id", myChirp.ID, "error", err)
+		}
+	}
+	s.publish(r, events.ChirpCreated, myChirp)
+	//the chirp is saved either way, a failed notification is only logged
+	err = s.notifications.ChirpPosted(r.Context(), myChirp)
+	if err != nil {
+		s.requestLog(r).Error("could not notify mentioned users", "chirp_id", myChirp.ID, "error", err)
+	}
+	valChirp := mapChirpToValidChirp(myChirp)
+	respondWithData(w, r, 201, valChirp)
+}
+
+// opens a moderation case for a chirp posted with moderate terms
+func (s *Server) flagChirp(r *http.Request, chirp store.Chirp, terms []string) error {
+	moderationCase, err := s.store.OpenModerationCase(r.Context(), chirp)
+	if err != nil {
+		return err
+	}
+	_, err = s.store.FlagModerationCase(r.Context(), moderationCase.ID, terms)
+	return err
+}
+
+// chirps are limited by the characters people see, Chirpy Red users can write longer ones
+// bytes are only capped so characters can't be stretched without end
+func (s *Server) checkChirpLength(user store.User, body string) error {
+	if len(body) > maxChirpBytes {
+		return errValidation(fieldError{Field: "body", Message: fmt.Sprintf("is %d bytes, the limit is %d", len(body), maxChirpBytes)})
+	}
+	limit := s.chirpLengthLimit(user)
+	length := textlen.Length(body)
+	if length > limit {
+		return errValidation(fieldError{Field: "body", Message: fmt.Sprintf("is %d characters, the limit is %d", length, limit), Length: length, Limit: limit})
+	}
+	return nil
+}
+
+func (s *Server) chirpLengthLimit(user store.User) int {
+	if user.IsChirpyRed {
+		return cmp.Or(s.cfg.MaxChirpLengthRed, defaultMaxChirpLengthRed)
+	}
+	return cmp.Or(s.cfg.MaxChirpLength, defaultMaxChirpLength)
+}
+
+// returns every chirp, can be filtered with author_id and sorted with sort=desc
+// with an access token chirps from blocked and muted users are left out
+// and chirps matching the user's timeline filters are marked as filtered
+func (s *Server) handlerListChirps(w http.ResponseWriter, r *http.Request) {
+	viewerID, err := s.optionalUser(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	query, err := bindListChirpsQuery(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	params := store.ListChirpsParams{AuthorID: query.AuthorID, Desc: query.Desc, After: query.After, ViewerID: viewerID}
+	if query.Limit > 0 {
+		//one extra chirp tells us if there is another page
+		params.Limit = query.Limit + 1
+	}
+	chirps, err := s.store.ListChirps(r.Context(), params)
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("error getting chirps: %w", err))
+		return
+	}
+
+	page := &pagination{Limit: query.Limit}
+	if query.Limit > 0 && len(chirps) > query.Limit {
+		chirps = chirps[:query.Limit]
+		last := chirps[len(chirps)-1]
+		page.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+	set, err := s.contentFilters(r.Context(), viewerID, filters.Timeline)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	var valChirps []validChirp
+	for _, val := range chirps {
+		tmpChirp := mapFilteredChirp(val, viewerID, set)
+		valChirps = append(valChirps, tmpChirp)
+	}
+	if apiVersion(r) >= apiV2 {
+		//v1 has always sent null when there are no chirps, v2 sends an empty list
+		if valChirps == nil {
+			valChirps = []validChirp{}
+		}
+		respondWithJson(w, 200, envelope{Data: valChirps, Pagination: page})
+		return
+	}
+	respondWithJson(w, 200, valChirps)
+}
+
+// gets a specific chirp given with the ID
+// with an access token chirps of users blocked either way are not found
+// hidden chirps are only found by their author
+func (s *Server) handlerGetChirp(w http.ResponseWriter, r *http.Request) {
+	viewerID, err := s.optionalUser(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	myChirp, err := s.visibleChirp(r, viewerID)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	set, err := s.contentFilters(r.Context(), viewerID, filters.Timeline)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	respondWithData(w, r, 200, mapFilteredChirp(myChirp, viewerID, set))
+}
+
+// deletes a specific chirp if it belongs to the user
+func (s *Server) handlerDeleteChirp(w http.ResponseWriter, r *http.Request) {
+	userIDToken, err := s.authenticateActive(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+
+	chirpID, err := parseUUID("chirpID", r.PathValue("chirpID"))
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	myChirp, err := s.store.GetChirp(r.Context(), chirpID)
+	if errors.Is(err, store.ErrNotFound) {
+		s.respondWithError(w, r, errNotFound("chirp", err))
+		return
+	}
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("error getting chirp with given ID: %w", err))
+		return
+	}
+
+	if myChirp.UserID != userIDToken {
+		s.respondWithError(w, r, errForbidden("chirp belongs to another user"))
+		return
+	}
+
+	err = s.store.DeleteChirp(r.Context(), chirpID)
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not delete chirp: %w", err))
+		return
+	}
+	s.publish(r, events.ChirpDeleted, myChirp)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/christianrm0821/Chirpy/internal/store"
+)
+
+// stable codes clients can match on, the messages that go with them can change
+const (
+	codeInvalidJSON   = "invalid_json"
+	codeInvalidParam  = "invalid_parameter"
+	codeBodyTooLarge  = "body_too_large"
+	codeValidation    = "validation_failed"
+	codeUnauthorized  = "unauthorized"
+	codeForbidden     = "forbidden"
+	codeSuspended     = "account_suspended"
+	codeNotFound      = "not_found"
+	codeEmailTaken    = "email_taken"
+	codeConflict      = "conflict"
+	codeInternalError = "internal_error"
+)
+
+const problemContentType = "application/problem+json"
+
+// one field of the request that did not pass validation
+type fieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	// for text that is too long, how long it is and how long it can be
+	Length int `json:"length,omitempty"`
+	Limit  int `json:"limit,omitempty"`
+}
+
+// an error that knows how it should be shown to the client
+// Err is the internal cause, it only ends up in the logs
+type apiError struct {
+	Status  int
+	Code    string
+	Message string
+	Details []fieldError
+	Err     error
+}
+
+func (e *apiError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *apiError) Unwrap() error {
+	return e.Err
+}
+
+func errUnauthorized(err error) *apiError {
+	return &apiError{Status: http.StatusUnauthorized, Code: codeUnauthorized, Message: "Unauthorized", Err: err}
+}
+
+func errForbidden(msg string) *apiError {
+	return &apiError{Status: http.StatusForbidden, Code: codeForbidden, Message: msg}
+}
+
+// suspended users can still read, but not log in or post anything
+func errSuspended() *apiError {
+	return &apiError{Status: http.StatusForbidden, Code: codeSuspended, Message: "your account is suspended"}
+}
+
+// what is the thing that could not be found, like "chirp"
+func errNotFound(what string, err error) *apiError {
+	return &apiError{Status: http.StatusNotFound, Code: codeNotFound, Message: what + " not found", Err: err}
+}
+
+func errValidation(details ...fieldError) *apiError {
+	return &apiError{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    codeValidation,
+		Message: "request failed validation",
+		Details: details,
+	}
+}
+
+func errEmailTaken(err error) *apiError {
+	return &apiError{Status: http.StatusConflict, Code: codeEmailTaken, Message: "email is already in use", Err: err}
+}
+
+func errInternal(err error) *apiError {
+	return &apiError{Status: http.StatusInternalServerError, Code: codeInternalError, Message: "internal server error", Err: err}
+}
+
+// turns any error into an apiError
+// store errors get their matching status, everything else is a 500 that hides the cause
+func toAPIError(err error) *apiError {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return &apiError{Status: http.StatusNotFound, Code: codeNotFound, Message: "not found", Err: err}
+	case errors.Is(err, store.ErrConflict):
+		return &apiError{Status: http.StatusConflict, Code: codeConflict, Message: "already exists", Err: err}
+	}
+	return errInternal(err)
+}
+
+// the default error body, kept close to the old {"error": "..."} shape
+type resErr struct {
+	Error     string       `json:"error"`
+	Code      string       `json:"code"`
+	Details   []fieldError `json:"details,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// RFC 9457 problem details, sent when the client accepts application/problem+json
+type problemDetails struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail"`
+	Instance  string       `json:"instance"`
+	Code      string       `json:"code"`
+	Errors    []fieldError `json:"errors,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// writes err to the client and logs the internal cause
+func (s *Server) respondWithError(w http.ResponseWriter, r *http.Request, err error) {
+	apiErr := toAPIError(err)
+	requestID := getRequestInfo(r.Context()).ID
+
+	logAttrs := []any{"status", apiErr.Status, "code", apiErr.Code}
+	if apiErr.Err != nil {
+		logAttrs = append(logAttrs, "error", apiErr.Err)
+	}
+	if apiErr.Status >= 500 {
+		s.requestLog(r).Error("request failed", logAttrs...)
+	} else {
+		s.requestLog(r).Debug("request rejected", logAttrs...)
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), problemContentType) {
+		w.Header().Set("Content-Type", problemContentType)
+		w.WriteHeader(apiErr.Status)
+		res, _ := json.Marshal(problemDetails{
+			Type:      "about:blank",
+			Title:     http.StatusText(apiErr.Status),
+			Status:    apiErr.Status,
+			Detail:    apiErr.Message,
+			Instance:  r.URL.Path,
+			Code:      apiErr.Code,
+			Errors:    apiErr.Details,
+			RequestID: requestID,
+		})
+		w.Write(res)
+		return
+	}
+
+	respondWithJson(w, apiErr.Status, resErr{
+		Error:     apiErr.Message,
+		Code:      apiErr.Code,
+		Details:   apiErr.Details,
+		RequestID: requestID,
+	})
+}
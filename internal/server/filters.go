@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/christianrm0821/Chirpy/internal/filters"
+	"github.com/christianrm0821/Chirpy/internal/store"
+	"github.com/google/uuid"
+)
+
+// how many filters a user can have at once
+const maxContentFilters = 100
+
+type contentFilterReq struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+	// every scope when missing
+	Scopes []string `json:"scopes,omitempty"`
+	// the filter ends at this time, it lasts until it is removed when missing
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+func (req contentFilterReq) validate() []fieldError {
+	var errs []fieldError
+	if !slices.Contains(filters.Kinds, req.Kind) {
+		errs = append(errs, fieldError{Field: "kind", Message: "must be word, phrase, hashtag or regex"})
+	} else if msg := filters.Check(req.Kind, req.Value); msg != "" {
+		errs = append(errs, fieldError{Field: "value", Message: msg})
+	}
+	if req.Scopes != nil && len(req.Scopes) == 0 {
+		errs = append(errs, fieldError{Field: "scopes", Message: "must have at least one scope"})
+	}
+	for _, scope := range req.Scopes {
+		if !slices.Contains(filters.Scopes, scope) {
+			errs = append(errs, fieldError{Field: "scopes", Message: fmt.Sprintf("%q is not timeline or notifications", scope)})
+		}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		errs = append(errs, fieldError{Field: "expires_at", Message: "must be in the future"})
+	}
+	return errs
+}
+
+type contentFilterRes struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	Kind      string     `json:"kind"`
+	Value     string     `json:"value"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+func mapContentFilter(filter store.ContentFilter) contentFilterRes {
+	res := contentFilterRes{ID: filter.ID, CreatedAt: filter.CreatedAt, Kind: filter.Kind, Value: filter.Value, Scopes: []string{}}
+	for _, scope := range filters.Scopes {
+		if filters.InScope(filter, scope) {
+			res.Scopes = append(res.Scopes, scope)
+		}
+	}
+	if filter.ExpiresAt.Valid {
+		res.ExpiresAt = &filter.ExpiresAt.Time
+	}
+	return res
+}
+
+// one of the viewer's filters that matched and the text it matched
+type filterResult struct {
+	FilterID uuid.UUID `json:"filter_id"`
+	Kind     string    `json:"kind"`
+	Value    string    `json:"value"`
+	Matches  []string  `json:"matches"`
+}
+
+func filterResults(set *filters.Set, text string) []filterResult {
+	var res []filterResult
+	for _, result := range set.Match(text) {
+		res = append(res, filterResult{FilterID: result.Filter.ID, Kind: result.Filter.Kind, Value: result.Filter.Value, Matches: result.Matches})
+	}
+	return res
+}
+
+// the viewer's filters for scope, nil when there is no viewer
+func (s *Server) contentFilters(ctx context.Context, viewerID uuid.UUID, scope string) (*filters.Set, error) {
+	if viewerID == uuid.Nil {
+		return nil, nil
+	}
+	list, err := s.store.ListContentFilters(ctx, viewerID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("could not get content filters: %w", err)
+	}
+	return filters.New(list, scope), nil
+}
+
+// the chirp as the viewer sees it, their own chirps are never filtered
+func mapFilteredChirp(chirp store.Chirp, viewerID uuid.UUID, set *filters.Set) validChirp {
+	res := mapChirpToValidChirp(chirp)
+	if chirp.UserID != viewerID {
+		res.Filtered = filterResults(set, chirp.Body)
+	}
+	return res
+}
+
+// filtered chirps and notifications are still returned, marked with what matched
+func (s *Server) handlerCreateContentFilter(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticateActive(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	request := contentFilterReq{}
+	err = bindJSON(r, &request)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	existing, err := s.store.ListContentFilters(r.Context(), userID, time.Now())
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not list content filters: %w", err))
+		return
+	}
+	if len(existing) >= maxContentFilters {
+		s.respondWithError(w, r, errForbidden(fmt.Sprintf("you can have at most %d filters", maxContentFilters)))
+		return
+	}
+
+	filter := store.ContentFilter{UserID: userID, Kind: request.Kind, Value: filters.Normalize(request.Kind, request.Value)}
+	scopes := request.Scopes
+	if scopes == nil {
+		scopes = filters.Scopes
+	}
+	filter.Timeline = slices.Contains(scopes, filters.Timeline)
+	filter.Notifications = slices.Contains(scopes, filters.Notifications)
+	if request.ExpiresAt != nil {
+		filter.ExpiresAt.Time, filter.ExpiresAt.Valid = *request.ExpiresAt, true
+	}
+	filter, err = s.store.CreateContentFilter(r.Context(), filter)
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not create content filter: %w", err))
+		return
+	}
+	respondWithData(w, r, 201, mapContentFilter(filter))
+}
+
+// the filters that haven't expired
+func (s *Server) handlerListContentFilters(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticate(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	list, err := s.store.ListContentFilters(r.Context(), userID, time.Now())
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not list content filters: %w", err))
+		return
+	}
+	res := []contentFilterRes{}
+	for _, filter := range list {
+		res = append(res, mapContentFilter(filter))
+	}
+	respondWithData(w, r, 200, res)
+}
+
+func (s *Server) handlerDeleteContentFilter(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticateActive(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	filterID, err := parseUUID("filterID", r.PathValue("filterID"))
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	err = s.store.DeleteContentFilter(r.Context(), userID, filterID)
+	if errors.Is(err, store.ErrNotFound) {
+		s.respondWithError(w, r, errNotFound("filter", err))
+		return
+	}
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not delete content filter: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
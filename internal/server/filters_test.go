@@ -0,0 +1,95 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestContentFilters(t *testing.T) {
+	c := newTestClient(t, Config{})
+	alice := c.login("alice@example.com", "hunter2")
+	bob := c.login("bob@example.com", "password")
+	c.do("PUT", "/api/v1/users/handle", alice.Token, handleReq{Handle: "alice"}, nil)
+
+	filter := contentFilterRes{}
+	code := c.do("POST", "/api/v1/filters", alice.Token, contentFilterReq{Kind: "phrase", Value: "season finale", Scopes: []string{"timeline"}}, &filter)
+	if code != http.StatusCreated || filter.Value != "season finale" || len(filter.Scopes) != 1 || filter.Scopes[0] != "timeline" {
+		t.Fatalf("was expecting a timeline filter but got %d %+v", code, filter)
+	}
+	tag := contentFilterRes{}
+	expiresAt := time.Now().Add(time.Hour)
+	c.do("POST", "/api/v1/filters", alice.Token, contentFilterReq{Kind: "hashtag", Value: "#Spoilers", ExpiresAt: &expiresAt}, &tag)
+	if tag.Value != "Spoilers" || len(tag.Scopes) != 2 || tag.ExpiresAt == nil {
+		t.Errorf("was expecting the hashtag without # on every scope but got %+v", tag)
+	}
+	//there is no search to filter
+	code = c.do("POST", "/api/v1/filters", alice.Token, contentFilterReq{Kind: "word", Value: "spoiler", Scopes: []string{"search"}}, nil)
+	if code != http.StatusUnprocessableEntity {
+		t.Errorf("was expecting 422 for the search scope but got %d", code)
+	}
+
+	c.do("POST", "/api/v1/chirps", bob.Token, chirpPostReq{Body: "the Season  Finale was wild #spoilers"}, nil)
+	c.do("POST", "/api/v1/chirps", bob.Token, chirpPostReq{Body: "nothing to see"}, nil)
+	//your own chirps aren't filtered
+	c.do("POST", "/api/v1/chirps", alice.Token, chirpPostReq{Body: "season finale tonight"}, nil)
+
+	var chirps []validChirp
+	c.do("GET", "/api/v1/chirps", alice.Token, nil, &chirps)
+	if len(chirps) != 3 {
+		t.Fatalf("was expecting filtered chirps to still be listed but got %+v", chirps)
+	}
+	filtered := chirps[0].Filtered
+	if len(filtered) != 2 || filtered[0].FilterID != tag.ID || filtered[0].Matches[0] != "#spoilers" ||
+		filtered[1].FilterID != filter.ID || filtered[1].Matches[0] != "Season  Finale" {
+		t.Errorf("was expecting both filters to match bob's chirp but got %+v", filtered)
+	}
+	if chirps[1].Filtered != nil || chirps[2].Filtered != nil {
+		t.Errorf("was expecting the other chirps not to be filtered but got %+v", chirps[1:])
+	}
+	chirps = nil
+	c.do("GET", "/api/v1/chirps", "", nil, &chirps)
+	if chirps[0].Filtered != nil {
+		t.Errorf("was expecting no filters without a token but got %+v", chirps[0])
+	}
+	chirp := validChirp{}
+	c.do("GET", "/api/v1/chirps/"+chirps[0].ID.String(), alice.Token, nil, &chirp)
+	if len(chirp.Filtered) != 2 {
+		t.Errorf("was expecting one chirp to be filtered too but got %+v", chirp)
+	}
+
+	//only the hashtag filter is on for notifications
+	c.do("POST", "/api/v1/chirps", bob.Token, chirpPostReq{Body: "@alice season finale #SPOILERS"}, nil)
+	list := notificationList{}
+	c.do("GET", "/api/v1/notifications", alice.Token, nil, &list)
+	if len(list.Notifications) != 1 || len(list.Notifications[0].Filtered) != 1 || list.Notifications[0].Filtered[0].FilterID != tag.ID {
+		t.Errorf("was expecting the mention to be filtered by the hashtag but got %+v", list)
+	}
+
+	filters := []contentFilterRes{}
+	c.do("GET", "/api/v1/filters", alice.Token, nil, &filters)
+	if len(filters) != 2 || filters[0].ID != tag.ID {
+		t.Errorf("was expecting both filters newest first but got %+v", filters)
+	}
+	code = c.do("DELETE", "/api/v1/filters/"+tag.ID.String(), bob.Token, nil, nil)
+	if code != http.StatusNotFound {
+		t.Errorf("was expecting 404 deleting someone else's filter but got %d", code)
+	}
+	code = c.do("DELETE", "/api/v1/filters/"+tag.ID.String(), alice.Token, nil, nil)
+	if code != http.StatusNoContent {
+		t.Errorf("was expecting 204 but got %d", code)
+	}
+
+	tests := []contentFilterReq{
+		{Kind: "word", Value: "two words"},
+		{Kind: "regex", Value: "fo("},
+		{Kind: "emoji", Value: "x"},
+		{Kind: "word", Value: "spoiler", Scopes: []string{"everywhere"}},
+	}
+	for _, test := range tests {
+		code = c.do("POST", "/api/v1/filters", alice.Token, test, nil)
+		if code != http.StatusUnprocessableEntity {
+			t.Errorf("was expecting 422 for %+v but got %d", test, code)
+		}
+	}
+}
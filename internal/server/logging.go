@@ -0,0 +1,116 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// header used to pass the request id between clients, proxies and the api
+const requestIDHeader = "X-Request-ID"
+
+type requestInfoKey struct{}
+
+// information about the current request that handlers can fill in for the access log
+type requestInfo struct {
+	ID         string
+	UserID     uuid.UUID
+	APIVersion int
+	// the role an /admin request was let in with
+	Role string
+}
+
+func getRequestInfo(ctx context.Context) *requestInfo {
+	info, ok := ctx.Value(requestInfoKey{}).(*requestInfo)
+	if !ok {
+		return &requestInfo{}
+	}
+	return info
+}
+
+// records the authenticated user so it shows up in the access log
+func setRequestUser(r *http.Request, userID uuid.UUID) {
+	getRequestInfo(r.Context()).UserID = userID
+}
+
+// returns the logger with the request id already attached
+func (s *Server) requestLog(r *http.Request) *slog.Logger {
+	return s.logger.With("request_id", getRequestInfo(r.Context()).ID)
+}
+
+// keeps track of the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(code int) {
+	rec.status = code
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// lets websocket handlers take over the connection, it is logged as a 101
+func (rec *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := http.NewResponseController(rec.ResponseWriter).Hijack()
+	if err == nil {
+		rec.status = http.StatusSwitchingProtocols
+	}
+	return conn, brw, err
+}
+
+// middleware that assigns a request id (or reuses the one sent by the client)
+// and writes an access log line once the request is done
+func (s *Server) middlewareRequestLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		requestID := r.Header.Get(requestIDHeader)
+		if requestID == "" || len(requestID) > 128 {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set(requestIDHeader, requestID)
+
+		info := &requestInfo{ID: requestID}
+		req := r.WithContext(context.WithValue(r.Context(), requestInfoKey{}, info))
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, req)
+
+		//serveMux sets the pattern on the request it was given
+		//copy it back so the middleware wrapping this one can see the route too
+		r.Pattern = req.Pattern
+
+		attrs := []any{
+			"request_id", requestID,
+			"method", r.Method,
+			"path", r.URL.Path,
+			"route", req.Pattern,
+			"status", rec.status,
+			"latency_ms", time.Since(start).Milliseconds(),
+			"remote_addr", r.RemoteAddr,
+		}
+		if info.APIVersion != 0 {
+			attrs = append(attrs, "api_version", info.APIVersion)
+		}
+		if info.UserID != uuid.Nil {
+			attrs = append(attrs, "user_id", info.UserID.String())
+		}
+		if info.Role != "" {
+			attrs = append(attrs, "role", info.Role)
+		}
+		if spanCtx := trace.SpanContextFromContext(r.Context()); spanCtx.HasTraceID() {
+			attrs = append(attrs, "trace_id", spanCtx.TraceID().String())
+		}
+		s.logger.Info("request", attrs...)
+	})
+}
@@ -0,0 +1,422 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/christianrm0821/Chirpy/internal/store"
+	"github.com/google/uuid"
+)
+
+// who can start a conversation with a user
+const (
+	dmEveryone = "everyone"
+	dmNobody   = "nobody"
+)
+
+const (
+	// members of a conversation, including the one who started it
+	maxConversationMembers = 10
+	maxMessageLength       = 1000
+)
+
+type conversationCreateReq struct {
+	MemberIDs []uuid.UUID `json:"member_ids"`
+}
+
+func (req conversationCreateReq) validate() []fieldError {
+	if len(req.MemberIDs) == 0 {
+		return []fieldError{{Field: "member_ids", Message: "is required"}}
+	}
+	if len(req.MemberIDs) > maxConversationMembers-1 {
+		return []fieldError{{Field: "member_ids", Message: fmt.Sprintf("can have at most %d users", maxConversationMembers-1)}}
+	}
+	return nil
+}
+
+type messageCreateReq struct {
+	Body string `json:"body"`
+}
+
+func (req messageCreateReq) validate() []fieldError {
+	if strings.TrimSpace(req.Body) == "" {
+		return []fieldError{{Field: "body", Message: "is required"}}
+	}
+	if utf8.RuneCountInString(req.Body) > maxMessageLength {
+		return []fieldError{{Field: "body", Message: fmt.Sprintf("must be at most %d characters", maxMessageLength)}}
+	}
+	return nil
+}
+
+type dmSettings struct {
+	DMPrivacy string `json:"dm_privacy"`
+}
+
+func (req dmSettings) validate() []fieldError {
+	switch req.DMPrivacy {
+	case dmEveryone, dmNobody:
+		return nil
+	}
+	return []fieldError{{Field: "dm_privacy", Message: "must be everyone or nobody"}}
+}
+
+type conversationMemberRes struct {
+	UserID     uuid.UUID  `json:"user_id"`
+	JoinedAt   time.Time  `json:"joined_at"`
+	LastReadAt *time.Time `json:"last_read_at,omitempty"`
+}
+
+type conversationRes struct {
+	ID        uuid.UUID               `json:"id"`
+	CreatedAt time.Time               `json:"created_at"`
+	UpdatedAt time.Time               `json:"updated_at"`
+	CreatedBy uuid.UUID               `json:"created_by"`
+	Members   []conversationMemberRes `json:"members"`
+	// there are messages the user hasn't read
+	Unread bool `json:"unread"`
+}
+
+type messageRes struct {
+	ID             uuid.UUID `json:"id"`
+	CreatedAt      time.Time `json:"created_at"`
+	ConversationID uuid.UUID `json:"conversation_id"`
+	SenderID       uuid.UUID `json:"sender_id"`
+	Body           string    `json:"body"`
+	// the other members who have read the message
+	ReadBy []uuid.UUID `json:"read_by"`
+}
+
+// v1 has no envelope so the page comes with its cursor
+type messageList struct {
+	Messages   []messageRes `json:"messages"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+func mapConversation(c store.Conversation, userID uuid.UUID) conversationRes {
+	res := conversationRes{
+		ID:        c.ID,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+		CreatedBy: c.CreatedBy,
+		Members:   []conversationMemberRes{},
+	}
+	for _, member := range c.Members {
+		memberRes := conversationMemberRes{UserID: member.UserID, JoinedAt: member.JoinedAt}
+		if member.LastReadAt.Valid {
+			memberRes.LastReadAt = &member.LastReadAt.Time
+		}
+		res.Members = append(res.Members, memberRes)
+		if member.UserID == userID {
+			res.Unread = !member.LastReadAt.Valid || member.LastReadAt.Time.Before(c.UpdatedAt)
+		}
+	}
+	return res
+}
+
+func mapMessage(m store.Message, c store.Conversation) messageRes {
+	res := messageRes{
+		ID:             m.ID,
+		CreatedAt:      m.CreatedAt,
+		ConversationID: m.ConversationID,
+		SenderID:       m.SenderID,
+		Body:           m.Body,
+		ReadBy:         []uuid.UUID{},
+	}
+	for _, member := range c.Members {
+		if member.UserID != m.SenderID && member.LastReadAt.Valid && !member.LastReadAt.Time.Before(m.CreatedAt) {
+			res.ReadBy = append(res.ReadBy, member.UserID)
+		}
+	}
+	return res
+}
+
+// reports if the user lets the sender start a conversation with them
+func (s *Server) acceptsMessages(ctx context.Context, senderID, userID uuid.UUID) (bool, error) {
+	blocked, err := s.store.IsBlocked(ctx, senderID, userID)
+	if err != nil || blocked {
+		return false, err
+	}
+	privacy, err := s.store.GetDMPrivacy(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return privacy != dmNobody, nil
+}
+
+// the conversation with the id in the path, it is not found for users who aren't in it
+func (s *Server) memberConversation(r *http.Request, userID uuid.UUID) (store.Conversation, error) {
+	id, err := parseUUID("conversationID", r.PathValue("conversationID"))
+	if err != nil {
+		return store.Conversation{}, err
+	}
+	conversation, err := s.store.GetConversation(r.Context(), id)
+	if errors.Is(err, store.ErrNotFound) {
+		return store.Conversation{}, errNotFound("conversation", err)
+	}
+	if err != nil {
+		return store.Conversation{}, fmt.Errorf("could not get conversation: %w", err)
+	}
+	for _, member := range conversation.Members {
+		if member.UserID == userID {
+			return conversation, nil
+		}
+	}
+	return store.Conversation{}, errNotFound("conversation", nil)
+}
+
+// starts a conversation with the given users
+// a direct conversation that already exists is returned instead of making a second one
+func (s *Server) handlerCreateConversation(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticateActive(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	request := conversationCreateReq{}
+	err = bindJSON(r, &request)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+
+	var others []uuid.UUID
+	seen := map[uuid.UUID]bool{userID: true}
+	for _, id := range request.MemberIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		others = append(others, id)
+
+		_, err := s.store.GetUserByID(r.Context(), id)
+		if errors.Is(err, store.ErrNotFound) {
+			s.respondWithError(w, r, errValidation(fieldError{Field: "member_ids", Message: fmt.Sprintf("user %s does not exist", id)}))
+			return
+		}
+		if err != nil {
+			s.respondWithError(w, r, fmt.Errorf("could not get user: %w", err))
+			return
+		}
+		accepts, err := s.acceptsMessages(r.Context(), userID, id)
+		if err != nil {
+			s.respondWithError(w, r, fmt.Errorf("could not check if user accepts messages: %w", err))
+			return
+		}
+		if !accepts {
+			s.respondWithError(w, r, errForbidden(fmt.Sprintf("user %s does not accept direct messages", id)))
+			return
+		}
+	}
+	if len(others) == 0 {
+		s.respondWithError(w, r, errValidation(fieldError{Field: "member_ids", Message: "must have someone other than you"}))
+		return
+	}
+
+	if len(others) == 1 {
+		conversation, err := s.store.FindDirectConversation(r.Context(), userID, others[0])
+		if err == nil {
+			respondWithData(w, r, 200, mapConversation(conversation, userID))
+			return
+		}
+		if !errors.Is(err, store.ErrNotFound) {
+			s.respondWithError(w, r, fmt.Errorf("could not look for conversation: %w", err))
+			return
+		}
+	}
+	conversation, err := s.store.CreateConversation(r.Context(), userID, append([]uuid.UUID{userID}, others...))
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not create conversation: %w", err))
+		return
+	}
+	respondWithData(w, r, 201, mapConversation(conversation, userID))
+}
+
+// the user's conversations, the one with the newest message first
+func (s *Server) handlerListConversations(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticate(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	conversations, err := s.store.ListConversations(r.Context(), userID)
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not list conversations: %w", err))
+		return
+	}
+	res := []conversationRes{}
+	for _, conversation := range conversations {
+		res = append(res, mapConversation(conversation, userID))
+	}
+	respondWithData(w, r, 200, res)
+}
+
+func (s *Server) handlerGetConversation(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticate(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	conversation, err := s.memberConversation(r, userID)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	respondWithData(w, r, 200, mapConversation(conversation, userID))
+}
+
+// sends a message, bad words are cleaned like in chirps
+func (s *Server) handlerCreateMessage(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticateActive(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	conversation, err := s.memberConversation(r, userID)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	request := messageCreateReq{}
+	err = bindJSON(r, &request)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	//nobody gets messages from someone they blocked, or who blocked them
+	for _, member := range conversation.Members {
+		if member.UserID == userID {
+			continue
+		}
+		blocked, err := s.store.IsBlocked(r.Context(), userID, member.UserID)
+		if err != nil {
+			s.respondWithError(w, r, fmt.Errorf("could not check blocks: %w", err))
+			return
+		}
+		if blocked {
+			s.respondWithError(w, r, errForbidden("a member of this conversation is blocked"))
+			return
+		}
+	}
+
+	//messages are private, moderate terms don't flag them
+	checked, err := s.checkProfanity(r, "body", request.Body)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+
+	message, err := s.store.CreateMessage(r.Context(), conversation.ID, userID, checked.Text)
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not create message: %w", err))
+		return
+	}
+	respondWithData(w, r, 201, mapMessage(message, conversation))
+}
+
+// the messages of a conversation, newest first
+func (s *Server) handlerListMessages(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticate(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	conversation, err := s.memberConversation(r, userID)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	page, err := bindPageQuery(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+
+	messages, err := s.store.ListMessages(r.Context(), conversation.ID, store.ListMessagesParams{
+		After: store.MessageCursor{CreatedAt: page.AfterTime, ID: page.AfterID},
+		//one extra message tells us if there is another page
+		Limit: page.Limit + 1,
+	})
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not list messages: %w", err))
+		return
+	}
+
+	nextCursor := ""
+	if len(messages) > page.Limit {
+		messages = messages[:page.Limit]
+		last := messages[len(messages)-1]
+		nextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+	res := []messageRes{}
+	for _, message := range messages {
+		res = append(res, mapMessage(message, conversation))
+	}
+	if apiVersion(r) >= apiV2 {
+		respondWithJson(w, 200, envelope{Data: res, Pagination: &pagination{Limit: page.Limit, NextCursor: nextCursor}})
+		return
+	}
+	respondWithJson(w, 200, messageList{Messages: res, NextCursor: nextCursor})
+}
+
+// read receipt, the user has read every message sent so far
+func (s *Server) handlerMarkConversationRead(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticateActive(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	conversation, err := s.memberConversation(r, userID)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	err = s.store.MarkConversationRead(r.Context(), conversation.ID, userID, time.Now().UTC())
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not mark conversation read: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handlerGetDMSettings(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticate(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	privacy, err := s.store.GetDMPrivacy(r.Context(), userID)
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not get dm privacy: %w", err))
+		return
+	}
+	if privacy == "" {
+		privacy = dmEveryone
+	}
+	respondWithData(w, r, 200, dmSettings{DMPrivacy: privacy})
+}
+
+// changes who can start conversations with the user, conversations that already exist are kept
+func (s *Server) handlerUpdateDMSettings(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticateActive(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	request := dmSettings{}
+	err = bindJSON(r, &request)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	err = s.store.SetDMPrivacy(r.Context(), userID, request.DMPrivacy)
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not set dm privacy: %w", err))
+		return
+	}
+	respondWithData(w, r, 200, request)
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestDirectMessages(t *testing.T) {
+	c := newTestClient(t, Config{})
+	alice := c.login("alice@example.com", "hunter2")
+	bob := c.login("bob@example.com", "password")
+	carol := c.login("carol@example.com", "secret")
+
+	direct := conversationRes{}
+	code := c.do("POST", "/api/v1/conversations", alice.Token, conversationCreateReq{MemberIDs: []uuid.UUID{bob.ID, alice.ID}}, &direct)
+	if code != http.StatusCreated || len(direct.Members) != 2 || direct.CreatedBy != alice.ID {
+		t.Fatalf("was expecting a new conversation between alice and bob but got %d %+v", code, direct)
+	}
+	again := conversationRes{}
+	code = c.do("POST", "/api/v1/conversations", bob.Token, conversationCreateReq{MemberIDs: []uuid.UUID{alice.ID}}, &again)
+	if code != http.StatusOK || again.ID != direct.ID {
+		t.Errorf("was expecting the same direct conversation but got %d %+v", code, again)
+	}
+
+	path := "/api/v1/conversations/" + direct.ID.String()
+	message := messageRes{}
+	code = c.do("POST", path+"/messages", alice.Token, messageCreateReq{Body: "what a kerfuffle"}, &message)
+	if code != http.StatusCreated || message.Body != "what a ****" || message.SenderID != alice.ID || len(message.ReadBy) != 0 {
+		t.Fatalf("was expecting a cleaned message from alice but got %d %+v", code, message)
+	}
+	c.do("POST", path+"/messages", bob.Token, messageCreateReq{Body: "indeed"}, nil)
+
+	code = c.do("GET", path+"/messages", carol.Token, nil, nil)
+	if code != http.StatusNotFound {
+		t.Errorf("was expecting 404 for someone outside the conversation but got %d", code)
+	}
+	code = c.do("POST", path+"/messages", carol.Token, messageCreateReq{Body: "hi"}, nil)
+	if code != http.StatusNotFound {
+		t.Errorf("was expecting 404 sending to someone else's conversation but got %d", code)
+	}
+
+	//replying means bob has read alice's message, alice hasn't read his yet
+	list := messageList{}
+	c.do("GET", path+"/messages", alice.Token, nil, &list)
+	if len(list.Messages) != 2 || len(list.Messages[0].ReadBy) != 0 || len(list.Messages[1].ReadBy) != 1 || list.Messages[1].ReadBy[0] != bob.ID {
+		t.Errorf("was expecting only alice's message to be read by bob but got %+v", list)
+	}
+	conversations := []conversationRes{}
+	c.do("GET", "/api/v1/conversations", alice.Token, nil, &conversations)
+	if len(conversations) != 1 || !conversations[0].Unread {
+		t.Errorf("was expecting alice to have an unread conversation but got %+v", conversations)
+	}
+	code = c.do("POST", path+"/read", alice.Token, nil, nil)
+	if code != http.StatusNoContent {
+		t.Errorf("was expecting 204 but got %d", code)
+	}
+	c.do("GET", path+"/messages", bob.Token, nil, &list)
+	if len(list.Messages[0].ReadBy) != 1 || list.Messages[0].ReadBy[0] != alice.ID {
+		t.Errorf("was expecting a read receipt from alice but got %+v", list.Messages[0])
+	}
+
+	page := struct {
+		Data       []messageRes `json:"data"`
+		Pagination pagination   `json:"pagination"`
+	}{}
+	c.do("GET", "/api/v2/conversations/"+direct.ID.String()+"/messages?limit=1", bob.Token, nil, &page)
+	if len(page.Data) != 1 || page.Data[0].Body != "indeed" || page.Pagination.NextCursor == "" {
+		t.Fatalf("was expecting bob's message first with a cursor but got %+v", page)
+	}
+	list = messageList{}
+	c.do("GET", path+"/messages?limit=1&cursor="+page.Pagination.NextCursor, bob.Token, nil, &list)
+	if len(list.Messages) != 1 || list.Messages[0].ID != message.ID || list.NextCursor != "" {
+		t.Errorf("was expecting alice's message on the last page but got %+v", list)
+	}
+
+	settings := dmSettings{}
+	code = c.do("PUT", "/api/v1/conversations/settings", carol.Token, dmSettings{DMPrivacy: "nobody"}, &settings)
+	if code != http.StatusOK || settings.DMPrivacy != "nobody" {
+		t.Errorf("was expecting carol's settings to change but got %d %+v", code, settings)
+	}
+	code = c.do("POST", "/api/v1/conversations", alice.Token, conversationCreateReq{MemberIDs: []uuid.UUID{bob.ID, carol.ID}}, nil)
+	if code != http.StatusForbidden {
+		t.Errorf("was expecting 403 adding someone who doesn't accept messages but got %d", code)
+	}
+	code = c.do("PUT", "/api/v1/conversations/settings", carol.Token, dmSettings{DMPrivacy: "followers"}, nil)
+	if code != http.StatusUnprocessableEntity {
+		t.Errorf("was expecting 422 for an unknown setting but got %d", code)
+	}
+	code = c.do("POST", "/api/v1/conversations", alice.Token, conversationCreateReq{MemberIDs: []uuid.UUID{uuid.New()}}, nil)
+	if code != http.StatusUnprocessableEntity {
+		t.Errorf("was expecting 422 for a user that doesn't exist but got %d", code)
+	}
+	code = c.do("POST", "/api/v1/conversations", alice.Token, conversationCreateReq{MemberIDs: []uuid.UUID{alice.ID}}, nil)
+	if code != http.StatusUnprocessableEntity {
+		t.Errorf("was expecting 422 for a conversation with yourself but got %d", code)
+	}
+}
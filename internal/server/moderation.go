@@ -0,0 +1,470 @@
+package server
+
+import (
+	"cmp"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/christianrm0821/Chirpy/internal/events"
+	"github.com/christianrm0821/Chirpy/internal/store"
+	"github.com/google/uuid"
+)
+
+// used when Config.ReportHideThreshold is 0
+const defaultReportHideThreshold = 5
+
+// why a chirp is reported
+var reportReasons = []string{"spam", "harassment", "hate", "violence", "sexual", "self_harm", "misinformation", "other"}
+
+// what a moderator can do with a case
+const (
+	// nothing is wrong, a chirp hidden by reports is shown again
+	resolveDismiss = "dismiss"
+	resolveHide    = "hide"
+	resolveDelete  = "delete"
+	// hides the chirp and suspends its author
+	resolveSuspend = "suspend"
+)
+
+var resolveActions = []string{resolveDismiss, resolveHide, resolveDelete, resolveSuspend}
+
+const (
+	maxReportCommentLength = 500
+	maxModeratorLength     = 100
+	maxNoteLength          = 1000
+)
+
+type reportReq struct {
+	Reason  string `json:"reason"`
+	Comment string `json:"comment,omitempty"`
+}
+
+func (req reportReq) validate() []fieldError {
+	var errs []fieldError
+	if !slices.Contains(reportReasons, req.Reason) {
+		errs = append(errs, fieldError{Field: "reason", Message: "must be spam, harassment, hate, violence, sexual, self_harm, misinformation or other"})
+	}
+	if len(req.Comment) > maxReportCommentLength {
+		errs = append(errs, fieldError{Field: "comment", Message: fmt.Sprintf("can't be longer than %d bytes", maxReportCommentLength)})
+	}
+	return errs
+}
+
+// moderators are the user of their token, with ADMIN_KEY the request names who is handling the case
+type claimCaseReq struct {
+	Moderator string `json:"moderator,omitempty"`
+}
+
+func (req claimCaseReq) validate() []fieldError {
+	return validateModerator(req.Moderator)
+}
+
+type resolveCaseReq struct {
+	Moderator string `json:"moderator,omitempty"`
+	Action    string `json:"action"`
+	Note      string `json:"note,omitempty"`
+}
+
+func (req resolveCaseReq) validate() []fieldError {
+	errs := validateModerator(req.Moderator)
+	if !slices.Contains(resolveActions, req.Action) {
+		errs = append(errs, fieldError{Field: "action", Message: "must be dismiss, hide, delete or suspend"})
+	}
+	if len(req.Note) > maxNoteLength {
+		errs = append(errs, fieldError{Field: "note", Message: fmt.Sprintf("can't be longer than %d bytes", maxNoteLength)})
+	}
+	return errs
+}
+
+func validateModerator(moderator string) []fieldError {
+	if len(moderator) > maxModeratorLength {
+		return []fieldError{{Field: "moderator", Message: fmt.Sprintf("can't be longer than %d bytes", maxModeratorLength)}}
+	}
+	return nil
+}
+
+type reportRes struct {
+	ID         uuid.UUID `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	ChirpID    uuid.UUID `json:"chirp_id"`
+	ReporterID uuid.UUID `json:"reporter_id"`
+	Reason     string    `json:"reason"`
+	Comment    string    `json:"comment,omitempty"`
+}
+
+func mapReport(report store.Report) reportRes {
+	return reportRes{
+		ID:         report.ID,
+		CreatedAt:  report.CreatedAt,
+		ChirpID:    report.ChirpID,
+		ReporterID: report.ReporterID,
+		Reason:     report.Reason,
+		Comment:    report.Comment,
+	}
+}
+
+type moderationCaseRes struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	ChirpID   uuid.UUID `json:"chirp_id"`
+	AuthorID  uuid.UUID `json:"author_id"`
+	// the chirp as it was when the case was opened, it is kept if the chirp is deleted
+	Body         string     `json:"body"`
+	Status       string     `json:"status"`
+	FlaggedTerms []string   `json:"flagged_terms"`
+	ReportCount  int        `json:"report_count"`
+	ClaimedBy    string     `json:"claimed_by,omitempty"`
+	ClaimedAt    *time.Time `json:"claimed_at,omitempty"`
+	Resolution   string     `json:"resolution,omitempty"`
+	Note         string     `json:"note,omitempty"`
+	ResolvedBy   string     `json:"resolved_by,omitempty"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+	// only when a single case is asked for
+	Reports []reportRes `json:"reports,omitempty"`
+}
+
+func mapModerationCase(c store.ModerationCase) moderationCaseRes {
+	res := moderationCaseRes{
+		ID:           c.ID,
+		CreatedAt:    c.CreatedAt,
+		UpdatedAt:    c.UpdatedAt,
+		ChirpID:      c.ChirpID,
+		AuthorID:     c.AuthorID,
+		Body:         c.Body,
+		Status:       c.Status(),
+		FlaggedTerms: c.FlaggedTerms,
+		ReportCount:  c.Reports,
+		ClaimedBy:    c.ClaimedBy,
+		Resolution:   c.Resolution,
+		Note:         c.Note,
+		ResolvedBy:   c.ResolvedBy,
+	}
+	if res.FlaggedTerms == nil {
+		res.FlaggedTerms = []string{}
+	}
+	if c.ClaimedAt.Valid {
+		res.ClaimedAt = &c.ClaimedAt.Time
+	}
+	if c.ResolvedAt.Valid {
+		res.ResolvedAt = &c.ResolvedAt.Time
+	}
+	return res
+}
+
+// the user behind an access token, suspended users are forbidden from what this guards
+func (s *Server) activeUser(r *http.Request, userID uuid.UUID) (store.User, error) {
+	user, err := s.store.GetUserByID(r.Context(), userID)
+	if errors.Is(err, store.ErrNotFound) {
+		return store.User{}, errUnauthorized(err)
+	}
+	if err != nil {
+		return store.User{}, fmt.Errorf("could not get user: %w", err)
+	}
+	if user.SuspendedAt.Valid {
+		return store.User{}, errSuspended()
+	}
+	return user, nil
+}
+
+// a chirp the viewer can see: hidden chirps are only there for their author
+// and chirps of users blocked either way are not there at all
+func (s *Server) visibleChirp(r *http.Request, viewerID uuid.UUID) (store.Chirp, error) {
+	chirpID, err := parseUUID("chirpID", r.PathValue("chirpID"))
+	if err != nil {
+		return store.Chirp{}, err
+	}
+	chirp, err := s.store.GetChirp(r.Context(), chirpID)
+	if errors.Is(err, store.ErrNotFound) {
+		return store.Chirp{}, errNotFound("chirp", err)
+	}
+	if err != nil {
+		return store.Chirp{}, fmt.Errorf("error getting this chirp: %w", err)
+	}
+	if chirp.HiddenAt.Valid && chirp.UserID != viewerID {
+		return store.Chirp{}, errNotFound("chirp", nil)
+	}
+	if viewerID != uuid.Nil {
+		blocked, err := s.store.IsBlocked(r.Context(), viewerID, chirp.UserID)
+		if err != nil {
+			return store.Chirp{}, fmt.Errorf("could not check blocks: %w", err)
+		}
+		if blocked {
+			return store.Chirp{}, errNotFound("chirp", nil)
+		}
+	}
+	return chirp, nil
+}
+
+// reports a chirp to the moderators, each user can report a chirp once while its case is open
+// the chirp is hidden once REPORT_HIDE_THRESHOLD users reported it
+func (s *Server) handlerCreateReport(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticateActive(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	chirp, err := s.visibleChirp(r, userID)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	if chirp.UserID == userID {
+		s.respondWithError(w, r, errForbidden("you can't report your own chirp"))
+		return
+	}
+	request := reportReq{}
+	err = bindJSON(r, &request)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+
+	moderationCase, err := s.store.OpenModerationCase(r.Context(), chirp)
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not open moderation case: %w", err))
+		return
+	}
+	report := store.Report{CaseID: moderationCase.ID, ChirpID: chirp.ID, ReporterID: userID, Reason: request.Reason, Comment: request.Comment}
+	moderationCase, err = s.store.AddReport(r.Context(), report)
+	if errors.Is(err, store.ErrConflict) {
+		s.respondWithError(w, r, &apiError{Status: http.StatusConflict, Code: codeConflict, Message: "you already reported this chirp", Err: err})
+		return
+	}
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not add report: %w", err))
+		return
+	}
+	if moderationCase.Reports >= cmp.Or(s.cfg.ReportHideThreshold, defaultReportHideThreshold) && !chirp.HiddenAt.Valid {
+		//the report is in either way, the chirp just stays up until a moderator gets to it
+		err = s.setChirpHidden(r, chirp, true)
+		if err != nil {
+			s.requestLog(r).Error("could not hide reported chirp", "chirp_id", chirp.ID, "error", err)
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// the moderation queue, oldest first
+// status is open, claimed or resolved, without it every case that isn't resolved
+func (s *Server) handlerListModerationCases(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status != "" && status != store.CaseOpen && status != store.CaseClaimed && status != store.CaseResolved {
+		s.respondWithError(w, r, errInvalidParam("status", "must be open, claimed or resolved", nil))
+		return
+	}
+	cases, err := s.store.ListModerationCases(r.Context(), status)
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not list moderation cases: %w", err))
+		return
+	}
+	res := []moderationCaseRes{}
+	for _, c := range cases {
+		res = append(res, mapModerationCase(c))
+	}
+	respondWithJson(w, 200, res)
+}
+
+// a case with its reports
+func (s *Server) handlerGetModerationCase(w http.ResponseWriter, r *http.Request) {
+	moderationCase, err := s.moderationCase(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	reports, err := s.store.ListReports(r.Context(), moderationCase.ID)
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not list reports: %w", err))
+		return
+	}
+	res := mapModerationCase(moderationCase)
+	for _, report := range reports {
+		res.Reports = append(res.Reports, mapReport(report))
+	}
+	respondWithJson(w, 200, res)
+}
+
+func (s *Server) moderationCase(r *http.Request) (store.ModerationCase, error) {
+	caseID, err := parseUUID("caseID", r.PathValue("caseID"))
+	if err != nil {
+		return store.ModerationCase{}, err
+	}
+	moderationCase, err := s.store.GetModerationCase(r.Context(), caseID)
+	if errors.Is(err, store.ErrNotFound) {
+		return store.ModerationCase{}, errNotFound("moderation case", err)
+	}
+	if err != nil {
+		return store.ModerationCase{}, fmt.Errorf("could not get moderation case: %w", err)
+	}
+	return moderationCase, nil
+}
+
+// claimed cases can only be resolved by the moderator who claimed them, 409 when someone else has
+func (s *Server) handlerClaimModerationCase(w http.ResponseWriter, r *http.Request) {
+	caseID, err := parseUUID("caseID", r.PathValue("caseID"))
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	request := claimCaseReq{}
+	err = bindJSON(r, &request)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	moderator, err := staffName(r, request.Moderator)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	moderationCase, err := s.store.ClaimModerationCase(r.Context(), caseID, moderator)
+	if err != nil {
+		s.respondWithError(w, r, caseChangeError(err))
+		return
+	}
+	respondWithJson(w, 200, mapModerationCase(moderationCase))
+}
+
+// acts on the chirp, closes the case and tells the reporters
+func (s *Server) handlerResolveModerationCase(w http.ResponseWriter, r *http.Request) {
+	moderationCase, err := s.moderationCase(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	request := resolveCaseReq{}
+	err = bindJSON(r, &request)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	moderator, err := staffName(r, request.Moderator)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	//checked before acting so a case someone else has isn't acted on,
+	//the store checks again when it is resolved
+	if moderationCase.ResolvedAt.Valid || (moderationCase.ClaimedBy != "" && moderationCase.ClaimedBy != moderator) {
+		s.respondWithError(w, r, caseChangeError(store.ErrConflict))
+		return
+	}
+	deleted, err := s.applyResolution(r, moderationCase, request.Action)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	moderationCase, err = s.store.ResolveModerationCase(r.Context(), moderationCase.ID, moderator, request.Action, request.Note)
+	if err != nil {
+		s.respondWithError(w, r, caseChangeError(err))
+		return
+	}
+
+	//the case is closed either way, a failed notification is only logged
+	reports, err := s.store.ListReports(r.Context(), moderationCase.ID)
+	if err == nil {
+		var reporterIDs []uuid.UUID
+		for _, report := range reports {
+			reporterIDs = append(reporterIDs, report.ReporterID)
+		}
+		chirpID := moderationCase.ChirpID
+		if deleted {
+			chirpID = uuid.Nil
+		}
+		err = s.notifications.ReportResolved(r.Context(), reporterIDs, chirpID)
+	}
+	if err != nil {
+		s.requestLog(r).Error("could not notify reporters", "case_id", moderationCase.ID, "error", err)
+	}
+	respondWithJson(w, 200, mapModerationCase(moderationCase))
+}
+
+// does what the moderator decided, deleted is whether the chirp is gone afterwards
+// a chirp its author already deleted is left alone
+func (s *Server) applyResolution(r *http.Request, moderationCase store.ModerationCase, action string) (deleted bool, err error) {
+	chirp, err := s.store.GetChirp(r.Context(), moderationCase.ChirpID)
+	if errors.Is(err, store.ErrNotFound) {
+		deleted = true
+	} else if err != nil {
+		return false, fmt.Errorf("could not get chirp: %w", err)
+	}
+
+	switch action {
+	case resolveDismiss:
+		if !deleted && chirp.HiddenAt.Valid {
+			err = s.setChirpHidden(r, chirp, false)
+		}
+	case resolveHide, resolveSuspend:
+		if !deleted && !chirp.HiddenAt.Valid {
+			err = s.setChirpHidden(r, chirp, true)
+		}
+	case resolveDelete:
+		if !deleted {
+			err = s.store.DeleteChirp(r.Context(), chirp.ID)
+			if err == nil {
+				s.publish(r, events.ChirpDeleted, chirp)
+			}
+			deleted = true
+		}
+	}
+	if err != nil {
+		return deleted, fmt.Errorf("could not %s chirp: %w", action, err)
+	}
+	if action == resolveSuspend {
+		err = s.store.SetUserSuspended(r.Context(), moderationCase.AuthorID, true)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			return deleted, fmt.Errorf("could not suspend user: %w", err)
+		}
+	}
+	return deleted, nil
+}
+
+// hides or shows a chirp again and tells the stream about it
+func (s *Server) setChirpHidden(r *http.Request, chirp store.Chirp, hidden bool) error {
+	err := s.store.SetChirpHidden(r.Context(), chirp.ID, hidden)
+	if err != nil {
+		return err
+	}
+	eventType := events.ChirpUnhidden
+	chirp.HiddenAt = sql.NullTime{}
+	if hidden {
+		eventType = events.ChirpHidden
+		chirp.HiddenAt = sql.NullTime{Time: time.Now().UTC(), Valid: true}
+	}
+	s.publish(r, eventType, chirp)
+	return nil
+}
+
+// claiming or resolving a case that is gone, resolved or someone else's
+func caseChangeError(err error) error {
+	if errors.Is(err, store.ErrNotFound) {
+		return errNotFound("moderation case", err)
+	}
+	if errors.Is(err, store.ErrConflict) {
+		return &apiError{Status: http.StatusConflict, Code: codeConflict, Message: "the case is resolved or claimed by another moderator", Err: err}
+	}
+	return fmt.Errorf("could not change moderation case: %w", err)
+}
+
+// lets a suspended user log in and post again
+func (s *Server) handlerLiftSuspension(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseUUID("userID", r.PathValue("userID"))
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	err = s.store.SetUserSuspended(r.Context(), userID, false)
+	if errors.Is(err, store.ErrNotFound) {
+		s.respondWithError(w, r, errNotFound("user", err))
+		return
+	}
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not lift suspension: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
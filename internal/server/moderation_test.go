@@ -0,0 +1,169 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestReportsAndModeration(t *testing.T) {
+	c := newTestClient(t, Config{AdminKey: "admin-key", ReportHideThreshold: 2})
+	const admin = "ApiKey admin-key"
+	alice := c.login("alice@example.com", "hunter2")
+	bob := c.login("bob@example.com", "password")
+	carol := c.login("carol@example.com", "secret")
+
+	chirp := validChirp{}
+	c.do("POST", "/api/v1/chirps", alice.Token, chirpPostReq{Body: "buy my stuff"}, &chirp)
+	reports := "/api/v1/chirps/" + chirp.ID.String() + "/reports"
+
+	code := c.do("POST", reports, alice.Token, reportReq{Reason: "spam"}, nil)
+	if code != http.StatusForbidden {
+		t.Errorf("was expecting 403 reporting your own chirp but got %d", code)
+	}
+	code = c.do("POST", reports, bob.Token, reportReq{Reason: "boring"}, nil)
+	if code != http.StatusUnprocessableEntity {
+		t.Errorf("was expecting 422 for an unknown reason but got %d", code)
+	}
+	code = c.do("POST", reports, bob.Token, reportReq{Reason: "spam", Comment: "again"}, nil)
+	if code != http.StatusNoContent {
+		t.Fatalf("was expecting 204 reporting a chirp but got %d", code)
+	}
+	code = c.do("POST", reports, bob.Token, reportReq{Reason: "spam"}, nil)
+	if code != http.StatusConflict {
+		t.Errorf("was expecting 409 reporting twice but got %d", code)
+	}
+	code = c.do("GET", "/api/v1/chirps/"+chirp.ID.String(), "", nil, nil)
+	if code != http.StatusOK {
+		t.Errorf("was expecting the chirp to stay up under the threshold but got %d", code)
+	}
+
+	//the second report hides the chirp from everyone but alice
+	c.do("POST", reports, carol.Token, reportReq{Reason: "spam"}, nil)
+	code = c.do("GET", "/api/v1/chirps/"+chirp.ID.String(), bob.Token, nil, nil)
+	if code != http.StatusNotFound {
+		t.Errorf("was expecting 404 for a hidden chirp but got %d", code)
+	}
+	own := validChirp{}
+	code = c.do("GET", "/api/v1/chirps/"+chirp.ID.String(), alice.Token, nil, &own)
+	if code != http.StatusOK || !own.Hidden {
+		t.Errorf("was expecting alice to see her hidden chirp but got %d %+v", code, own)
+	}
+	var chirps []validChirp
+	c.do("GET", "/api/v1/chirps", "", nil, &chirps)
+	if len(chirps) != 0 {
+		t.Errorf("was expecting the hidden chirp to be left out but got %+v", chirps)
+	}
+
+	cases := []moderationCaseRes{}
+	c.doAuthorized("GET", "/admin/moderation/cases", admin, nil, &cases)
+	if len(cases) != 1 || cases[0].ChirpID != chirp.ID || cases[0].ReportCount != 2 || cases[0].Status != "open" {
+		t.Fatalf("was expecting one open case with two reports but got %+v", cases)
+	}
+	caseURL := "/admin/moderation/cases/" + cases[0].ID.String()
+	single := moderationCaseRes{}
+	c.doAuthorized("GET", caseURL, admin, nil, &single)
+	if len(single.Reports) != 2 || single.Reports[0].ReporterID != bob.ID || single.Reports[0].Comment != "again" {
+		t.Errorf("was expecting the case with its reports but got %+v", single)
+	}
+
+	code = c.doAuthorized("POST", caseURL+"/claim", admin, claimCaseReq{Moderator: "mod-a"}, nil)
+	if code != http.StatusOK {
+		t.Errorf("was expecting 200 claiming the case but got %d", code)
+	}
+	code = c.doAuthorized("POST", caseURL+"/resolve", admin, resolveCaseReq{Moderator: "mod-b", Action: "dismiss"}, nil)
+	if code != http.StatusConflict {
+		t.Errorf("was expecting 409 resolving someone else's case but got %d", code)
+	}
+	resolved := moderationCaseRes{}
+	code = c.doAuthorized("POST", caseURL+"/resolve", admin, resolveCaseReq{Moderator: "mod-a", Action: "suspend", Note: "spammer"}, &resolved)
+	if code != http.StatusOK || resolved.Status != "resolved" || resolved.Resolution != "suspend" {
+		t.Fatalf("was expecting the case to be resolved but got %d %+v", code, resolved)
+	}
+
+	//suspended users keep their data but can't log in or change anything
+	code = c.do("POST", "/api/v1/chirps", alice.Token, chirpPostReq{Body: "let me back"}, nil)
+	if code != http.StatusForbidden {
+		t.Errorf("was expecting 403 posting while suspended but got %d", code)
+	}
+	code = c.do("POST", "/api/v1/login", "", email{Email: "alice@example.com", Password: "hunter2"}, nil)
+	if code != http.StatusForbidden {
+		t.Errorf("was expecting 403 logging in while suspended but got %d", code)
+	}
+	writes := []struct {
+		method, path string
+		body         any
+	}{
+		{"PUT", "/api/v1/users", email{Email: "alice@example.com", Password: "hunter3"}},
+		{"DELETE", "/api/v1/chirps/" + chirp.ID.String(), nil},
+		{"POST", "/api/v1/conversations", conversationCreateReq{MemberIDs: []uuid.UUID{bob.ID}}},
+		{"PUT", "/api/v1/conversations/settings", dmSettings{DMPrivacy: "nobody"}},
+		{"POST", "/api/v1/blocks", blockReq{UserID: bob.ID}},
+		{"POST", "/api/v1/mutes", muteReq{UserID: bob.ID}},
+		{"POST", "/api/v1/filters", contentFilterReq{Kind: "word", Value: "stuff"}},
+		{"POST", "/api/v1/notifications/read-all", nil},
+	}
+	for _, write := range writes {
+		code = c.do(write.method, write.path, alice.Token, write.body, nil)
+		if code != http.StatusForbidden {
+			t.Errorf("was expecting 403 for %s %s while suspended but got %d", write.method, write.path, code)
+		}
+	}
+	code = c.doAuthorized("DELETE", "/admin/users/"+alice.ID.String()+"/suspension", admin, nil, nil)
+	if code != http.StatusNoContent {
+		t.Errorf("was expecting 204 lifting the suspension but got %d", code)
+	}
+	code = c.do("POST", "/api/v1/login", "", email{Email: "alice@example.com", Password: "hunter2"}, nil)
+	if code != http.StatusOK {
+		t.Errorf("was expecting alice to log in again but got %d", code)
+	}
+	code = c.do("GET", "/api/v1/chirps/"+chirp.ID.String(), bob.Token, nil, nil)
+	if code != http.StatusNotFound {
+		t.Errorf("was expecting the chirp to stay hidden but got %d", code)
+	}
+
+	list := notificationList{}
+	c.do("GET", "/api/v1/notifications", bob.Token, nil, &list)
+	if len(list.Notifications) != 1 || list.Notifications[0].Type != "report_resolved" {
+		t.Errorf("was expecting bob to hear about the resolution but got %+v", list)
+	}
+}
+
+func TestResolveDelete(t *testing.T) {
+	c := newTestClient(t, Config{AdminKey: "admin-key"})
+	const admin = "ApiKey admin-key"
+	alice := c.login("alice@example.com", "hunter2")
+	bob := c.login("bob@example.com", "password")
+
+	chirp := validChirp{}
+	c.do("POST", "/api/v1/chirps", alice.Token, chirpPostReq{Body: "something awful"}, &chirp)
+	c.do("POST", "/api/v1/chirps/"+chirp.ID.String()+"/reports", bob.Token, reportReq{Reason: "hate"}, nil)
+
+	cases := []moderationCaseRes{}
+	c.doAuthorized("GET", "/admin/moderation/cases?status=open", admin, nil, &cases)
+	if len(cases) != 1 {
+		t.Fatalf("was expecting one open case but got %+v", cases)
+	}
+	code := c.doAuthorized("POST", "/admin/moderation/cases/"+cases[0].ID.String()+"/resolve", admin, resolveCaseReq{Moderator: "mod-a", Action: "delete"}, nil)
+	if code != http.StatusOK {
+		t.Fatalf("was expecting 200 resolving the case but got %d", code)
+	}
+	code = c.do("GET", "/api/v1/chirps/"+chirp.ID.String(), alice.Token, nil, nil)
+	if code != http.StatusNotFound {
+		t.Errorf("was expecting the chirp to be deleted but got %d", code)
+	}
+	list := notificationList{}
+	c.do("GET", "/api/v1/notifications", bob.Token, nil, &list)
+	if len(list.Notifications) != 1 || list.Notifications[0].ChirpID != nil {
+		t.Errorf("was expecting a notification without the deleted chirp but got %+v", list)
+	}
+	c.doAuthorized("GET", "/admin/moderation/cases", admin, nil, &cases)
+	if len(cases) != 0 {
+		t.Errorf("was expecting the queue to be empty but got %+v", cases)
+	}
+	code = c.doAuthorized("GET", "/admin/moderation/cases?status=closed", admin, nil, nil)
+	if code != http.StatusBadRequest {
+		t.Errorf("was expecting 400 for an unknown status but got %d", code)
+	}
+}
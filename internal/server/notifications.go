@@ -0,0 +1,244 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/christianrm0821/Chirpy/internal/filters"
+	"github.com/christianrm0821/Chirpy/internal/notifications"
+	"github.com/christianrm0821/Chirpy/internal/store"
+	"github.com/google/uuid"
+)
+
+type notificationRes struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Type      string     `json:"type"`
+	Message   string     `json:"message"`
+	ActorID   *uuid.UUID `json:"actor_id,omitempty"`
+	ChirpID   *uuid.UUID `json:"chirp_id,omitempty"`
+	Read      bool       `json:"read"`
+	// the user's notification filters that match the chirp
+	Filtered []filterResult `json:"filtered,omitempty"`
+}
+
+// v1 has no envelope so the list comes with its unread count and cursor
+type notificationList struct {
+	Notifications []notificationRes `json:"notifications"`
+	UnreadCount   int               `json:"unread_count"`
+	NextCursor    string            `json:"next_cursor,omitempty"`
+}
+
+type unreadMeta struct {
+	UnreadCount int `json:"unread_count"`
+}
+
+// every notification type with whether the user gets it
+type notificationPreferences map[string]bool
+
+func (prefs notificationPreferences) validate() []fieldError {
+	var errs []fieldError
+	for notificationType := range prefs {
+		if !notifications.IsType(notificationType) {
+			errs = append(errs, fieldError{Field: notificationType, Message: "is not a notification type"})
+		}
+	}
+	return errs
+}
+
+func mapNotification(n store.Notification) notificationRes {
+	res := notificationRes{
+		ID:        n.ID,
+		CreatedAt: n.CreatedAt,
+		UpdatedAt: n.UpdatedAt,
+		Type:      n.Type,
+		Message:   notifications.Message(n),
+		Read:      n.ReadAt.Valid,
+	}
+	if n.ActorID.Valid {
+		res.ActorID = &n.ActorID.UUID
+	}
+	if n.ChirpID.Valid {
+		res.ChirpID = &n.ChirpID.UUID
+	}
+	return res
+}
+
+// the user's notifications, newest activity first, unread=true leaves out the read ones
+func (s *Server) handlerListNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticate(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	page, err := bindPageQuery(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	params := store.ListNotificationsParams{
+		After: store.NotificationCursor{UpdatedAt: page.AfterTime, ID: page.AfterID},
+		//one extra notification tells us if there is another page
+		Limit: page.Limit + 1,
+	}
+	switch unread := r.URL.Query().Get("unread"); unread {
+	case "", "false":
+	case "true":
+		params.UnreadOnly = true
+	default:
+		s.respondWithError(w, r, errInvalidParam("unread", "must be true or false", nil))
+		return
+	}
+
+	list, err := s.store.ListNotifications(r.Context(), userID, params)
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not list notifications: %w", err))
+		return
+	}
+	unreadCount, err := s.store.CountUnreadNotifications(r.Context(), userID)
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not count unread notifications: %w", err))
+		return
+	}
+
+	nextCursor := ""
+	if len(list) > page.Limit {
+		list = list[:page.Limit]
+		last := list[len(list)-1]
+		nextCursor = encodeCursor(last.UpdatedAt, last.ID)
+	}
+	set, err := s.contentFilters(r.Context(), userID, filters.Notifications)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	chirps, err := s.notificationChirps(r, list, set)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	res := []notificationRes{}
+	for _, n := range list {
+		notification := mapNotification(n)
+		if chirp, ok := chirps[n.ChirpID.UUID]; ok && n.ChirpID.Valid {
+			notification.Filtered = filterResults(set, chirp.Body)
+		}
+		res = append(res, notification)
+	}
+	if apiVersion(r) >= apiV2 {
+		respondWithJson(w, 200, envelope{
+			Data:       res,
+			Pagination: &pagination{Limit: page.Limit, NextCursor: nextCursor},
+			Meta:       unreadMeta{UnreadCount: unreadCount},
+		})
+		return
+	}
+	respondWithJson(w, 200, notificationList{Notifications: res, UnreadCount: unreadCount, NextCursor: nextCursor})
+}
+
+// the chirps of the notifications by id, loaded all at once
+// deleted chirps are left out, and there is nothing to load when set has no filters
+func (s *Server) notificationChirps(r *http.Request, list []store.Notification, set *filters.Set) (map[uuid.UUID]store.Chirp, error) {
+	if set.Empty() {
+		return nil, nil
+	}
+	var ids []uuid.UUID
+	for _, n := range list {
+		if n.ChirpID.Valid && !slices.Contains(ids, n.ChirpID.UUID) {
+			ids = append(ids, n.ChirpID.UUID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	chirps, err := s.store.GetChirps(r.Context(), ids)
+	if err != nil {
+		return nil, fmt.Errorf("could not get chirps: %w", err)
+	}
+	byID := make(map[uuid.UUID]store.Chirp, len(chirps))
+	for _, chirp := range chirps {
+		byID[chirp.ID] = chirp
+	}
+	return byID, nil
+}
+
+func (s *Server) handlerMarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticateActive(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	id, err := parseUUID("notificationID", r.PathValue("notificationID"))
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	err = s.store.MarkNotificationRead(r.Context(), userID, id, time.Now().UTC())
+	if errors.Is(err, store.ErrNotFound) {
+		s.respondWithError(w, r, errNotFound("notification", err))
+		return
+	}
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not mark notification read: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handlerMarkAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticateActive(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	err = s.store.MarkAllNotificationsRead(r.Context(), userID, time.Now().UTC())
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not mark notifications read: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handlerGetNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticate(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	prefs, err := s.notifications.Preferences(r.Context(), userID)
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not get notification preferences: %w", err))
+		return
+	}
+	respondWithData(w, r, 200, notificationPreferences(prefs))
+}
+
+// turns the given types on or off, the ones left out don't change
+func (s *Server) handlerUpdateNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticateActive(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	request := notificationPreferences{}
+	err = bindJSON(r, &request)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	err = s.notifications.SetPreferences(r.Context(), userID, request)
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not set notification preferences: %w", err))
+		return
+	}
+	prefs, err := s.notifications.Preferences(r.Context(), userID)
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not get notification preferences: %w", err))
+		return
+	}
+	respondWithData(w, r, 200, notificationPreferences(prefs))
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestNotifications(t *testing.T) {
+	c := newTestClient(t, Config{})
+	alice := c.login("alice@example.com", "hunter2")
+	bob := c.login("bob@example.com", "password")
+
+	handled := userReturnEmail{}
+	code := c.do("PUT", "/api/v1/users/handle", alice.Token, handleReq{Handle: "Alice!"}, nil)
+	if code != http.StatusUnprocessableEntity {
+		t.Errorf("was expecting 422 for a bad handle but got %d", code)
+	}
+	code = c.do("PUT", "/api/v1/users/handle", alice.Token, handleReq{Handle: "alice"}, &handled)
+	if code != http.StatusOK || handled.Handle != "alice" {
+		t.Fatalf("was expecting alice to get her handle but got %d %+v", code, handled)
+	}
+	code = c.do("PUT", "/api/v1/users/handle", bob.Token, handleReq{Handle: "alice"}, nil)
+	if code != http.StatusConflict {
+		t.Errorf("was expecting 409 for a taken handle but got %d", code)
+	}
+
+	c.do("POST", "/api/v1/chirps", bob.Token, chirpPostReq{Body: "hi @alice"}, nil)
+	c.do("POST", "/api/v1/chirps", bob.Token, chirpPostReq{Body: "@Alice again"}, nil)
+	//mentioning yourself does nothing, and emails are not mentions
+	c.do("POST", "/api/v1/chirps", alice.Token, chirpPostReq{Body: "me @alice"}, nil)
+	c.do("POST", "/api/v1/chirps", bob.Token, chirpPostReq{Body: "mail @alice@example.com"}, nil)
+
+	list := notificationList{}
+	code = c.do("GET", "/api/v1/notifications", alice.Token, nil, &list)
+	if code != http.StatusOK || len(list.Notifications) != 2 || list.UnreadCount != 2 {
+		t.Fatalf("was expecting 2 unread mentions but got %d %+v", code, list)
+	}
+	first := list.Notifications[0]
+	if first.Type != "mention" || first.Read || first.ActorID == nil || *first.ActorID != bob.ID || first.ChirpID == nil {
+		t.Errorf("was expecting an unread mention from bob but got %+v", first)
+	}
+
+	page := struct {
+		Data       []notificationRes `json:"data"`
+		Pagination pagination        `json:"pagination"`
+		Meta       unreadMeta        `json:"meta"`
+	}{}
+	c.do("GET", "/api/v2/notifications?limit=1", alice.Token, nil, &page)
+	if len(page.Data) != 1 || page.Data[0].ID != first.ID || page.Pagination.NextCursor == "" || page.Meta.UnreadCount != 2 {
+		t.Fatalf("was expecting the newest mention and a cursor but got %+v", page)
+	}
+	cursor := page.Pagination.NextCursor
+	page.Pagination.NextCursor = ""
+	c.do("GET", "/api/v2/notifications?limit=1&cursor="+cursor, alice.Token, nil, &page)
+	if len(page.Data) != 1 || page.Data[0].ID != list.Notifications[1].ID || page.Pagination.NextCursor != "" {
+		t.Errorf("was expecting the older mention on the last page but got %+v", page)
+	}
+
+	code = c.do("POST", "/api/v1/notifications/"+first.ID.String()+"/read", bob.Token, nil, nil)
+	if code != http.StatusNotFound {
+		t.Errorf("was expecting 404 for someone else's notification but got %d", code)
+	}
+	code = c.do("POST", "/api/v1/notifications/"+first.ID.String()+"/read", alice.Token, nil, nil)
+	if code != http.StatusNoContent {
+		t.Errorf("was expecting 204 but got %d", code)
+	}
+	c.do("GET", "/api/v1/notifications?unread=true", alice.Token, nil, &list)
+	if len(list.Notifications) != 1 || list.UnreadCount != 1 || list.Notifications[0].ID == first.ID {
+		t.Errorf("was expecting only the other mention to be unread but got %+v", list)
+	}
+	c.do("POST", "/api/v1/notifications/read-all", alice.Token, nil, nil)
+	c.do("GET", "/api/v1/notifications", alice.Token, nil, &list)
+	if len(list.Notifications) != 2 || list.UnreadCount != 0 {
+		t.Errorf("was expecting every notification to be read but got %+v", list)
+	}
+
+	prefs := notificationPreferences{}
+	code = c.do("PUT", "/api/v1/notifications/preferences", alice.Token, map[string]bool{"mention": false}, &prefs)
+	if code != http.StatusOK || prefs["mention"] || !prefs["chirpy_red"] {
+		t.Errorf("was expecting only mentions to be off but got %d %v", code, prefs)
+	}
+	c.do("POST", "/api/v1/chirps", bob.Token, chirpPostReq{Body: "@alice are you there"}, nil)
+	c.do("GET", "/api/v1/notifications", alice.Token, nil, &list)
+	if len(list.Notifications) != 2 {
+		t.Errorf("was expecting no new notification with mentions off but got %+v", list)
+	}
+
+	code = c.do("PUT", "/api/v1/notifications/preferences", alice.Token, map[string]bool{"poke": true}, nil)
+	if code != http.StatusUnprocessableEntity {
+		t.Errorf("was expecting 422 for an unknown type but got %d", code)
+	}
+	code = c.do("GET", "/api/v1/notifications", "", nil, nil)
+	if code != http.StatusUnauthorized {
+		t.Errorf("was expecting 401 without a token but got %d", code)
+	}
+}
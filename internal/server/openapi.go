@@ -0,0 +1,49 @@
+package server
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// the openapi document describing every route in routes()
+// TestSpecCoversRoutes fails when a route is added without updating it
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+//go:embed docs
+var docsFS embed.FS
+
+// serves the openapi document
+func (s *Server) handlerOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(openAPISpec)
+}
+
+// serves the documentation page bundled into the binary, it renders /api/openapi.json
+func docsHandler() http.Handler {
+	docs, _ := fs.Sub(docsFS, "docs")
+	return http.StripPrefix("/app/docs", http.FileServer(http.FS(docs)))
+}
+
+// a ServeMux that remembers the patterns registered on it
+// so they can be checked against the openapi document
+type router struct {
+	*http.ServeMux
+	patterns []string
+}
+
+func newRouter() *router {
+	return &router{ServeMux: http.NewServeMux()}
+}
+
+func (rt *router) Handle(pattern string, handler http.Handler) {
+	rt.ServeMux.Handle(pattern, handler)
+	rt.patterns = append(rt.patterns, pattern)
+}
+
+func (rt *router) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	rt.Handle(pattern, http.HandlerFunc(handler))
+}
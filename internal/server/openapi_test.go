@@ -0,0 +1,76 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christianrm0821/Chirpy/internal/health"
+)
+
+type specDoc struct {
+	OpenAPI string                                `json:"openapi"`
+	Paths   map[string]map[string]json.RawMessage `json:"paths"`
+}
+
+func loadSpec(t *testing.T) specDoc {
+	t.Helper()
+	spec := specDoc{}
+	err := json.Unmarshal(openAPISpec, &spec)
+	if err != nil {
+		t.Fatalf("openapi.json is not valid json: %v", err)
+	}
+	return spec
+}
+
+// every route on the mux has to be documented, and every documented route has to exist
+func TestSpecCoversRoutes(t *testing.T) {
+	spec := loadSpec(t)
+	if !strings.HasPrefix(spec.OpenAPI, "3.1") {
+		t.Errorf("was expecting openapi 3.1 but got %q", spec.OpenAPI)
+	}
+
+	s := &Server{
+		cfg:    Config{StaticDir: "."},
+		health: health.NewRegistry(time.Second),
+	}
+	registered := map[string]bool{}
+	for _, pattern := range s.routes().patterns {
+		method, path, found := strings.Cut(pattern, " ")
+		if !found {
+			method, path = "GET", pattern
+		}
+		method = strings.ToLower(method)
+		registered[method+" "+path] = true
+		if _, ok := spec.Paths[path][method]; !ok {
+			t.Errorf("route %q is not in openapi.json", pattern)
+		}
+	}
+
+	for path, ops := range spec.Paths {
+		for method := range ops {
+			if !registered[method+" "+path] {
+				t.Errorf("openapi.json documents %s %s but no such route is registered", method, path)
+			}
+		}
+	}
+}
+
+func TestServeSpecAndDocs(t *testing.T) {
+	c := newTestClient(t, Config{})
+
+	rec := httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, httptest.NewRequest("GET", "/api/openapi.json", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("was expecting the spec but got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	rec = httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, httptest.NewRequest("GET", "/app/docs/", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "/api/openapi.json") {
+		t.Errorf("was expecting the docs page but got %d", rec.Code)
+	}
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/christianrm0821/Chirpy/internal/profanity"
+	"github.com/christianrm0821/Chirpy/internal/store"
+	"github.com/google/uuid"
+)
+
+// runs text through the profanity terms, reject terms fail validation of field
+func (s *Server) checkProfanity(r *http.Request, field, text string) (profanity.Result, error) {
+	matcher, err := s.profanity.Matcher(r.Context())
+	if matcher == nil {
+		return profanity.Result{}, err
+	}
+	//old terms are better than no chirps
+	if err != nil {
+		s.requestLog(r).Error("using the last profanity terms", "error", err)
+	}
+	result := matcher.Apply(text)
+	if len(result.Rejected) > 0 {
+		return result, errValidation(fieldError{Field: field, Message: "contains words that aren't allowed"})
+	}
+	return result, nil
+}
+
+type profanityTermReq struct {
+	Term   string `json:"term"`
+	Action string `json:"action"`
+}
+
+func (req profanityTermReq) validate() []fieldError {
+	var errs []fieldError
+	if _, problem := profanity.ParseTerm(req.Term); problem != "" {
+		errs = append(errs, fieldError{Field: "term", Message: problem})
+	}
+	if !slices.Contains(profanity.Actions, req.Action) {
+		errs = append(errs, fieldError{Field: "action", Message: "must be mask, reject or moderate"})
+	}
+	return errs
+}
+
+type profanityTermRes struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Term      string    `json:"term"`
+	Action    string    `json:"action"`
+}
+
+// changes take effect here right away and on other instances within PROFANITY_RELOAD_INTERVAL
+func (s *Server) reloadProfanity(r *http.Request) {
+	err := s.profanity.Reload(r.Context())
+	if err != nil {
+		s.requestLog(r).Error("could not reload profanity terms", "error", err)
+	}
+}
+
+func (s *Server) handlerListProfanityTerms(w http.ResponseWriter, r *http.Request) {
+	terms, err := s.store.ListProfanityTerms(r.Context())
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not list profanity terms: %w", err))
+		return
+	}
+	res := []profanityTermRes{}
+	for _, term := range terms {
+		res = append(res, profanityTermRes(term))
+	}
+	respondWithJson(w, 200, res)
+}
+
+func (s *Server) handlerCreateProfanityTerm(w http.ResponseWriter, r *http.Request) {
+	request := profanityTermReq{}
+	err := bindJSON(r, &request)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	normalized, _ := profanity.ParseTerm(request.Term)
+	term, err := s.store.CreateProfanityTerm(r.Context(), normalized, request.Action)
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not create profanity term: %w", err))
+		return
+	}
+	s.reloadProfanity(r)
+	respondWithJson(w, 201, profanityTermRes(term))
+}
+
+func (s *Server) handlerUpdateProfanityTerm(w http.ResponseWriter, r *http.Request) {
+	termID, err := parseUUID("termID", r.PathValue("termID"))
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	request := profanityTermReq{}
+	err = bindJSON(r, &request)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	normalized, _ := profanity.ParseTerm(request.Term)
+	term, err := s.store.UpdateProfanityTerm(r.Context(), termID, normalized, request.Action)
+	if errors.Is(err, store.ErrNotFound) {
+		s.respondWithError(w, r, errNotFound("profanity term", err))
+		return
+	}
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not update profanity term: %w", err))
+		return
+	}
+	s.reloadProfanity(r)
+	respondWithJson(w, 200, profanityTermRes(term))
+}
+
+func (s *Server) handlerDeleteProfanityTerm(w http.ResponseWriter, r *http.Request) {
+	termID, err := parseUUID("termID", r.PathValue("termID"))
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	err = s.store.DeleteProfanityTerm(r.Context(), termID)
+	if errors.Is(err, store.ErrNotFound) {
+		s.respondWithError(w, r, errNotFound("profanity term", err))
+		return
+	}
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not delete profanity term: %w", err))
+		return
+	}
+	s.reloadProfanity(r)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// picks up terms changed in the database directly or on another instance without waiting
+func (s *Server) handlerReloadProfanityTerms(w http.ResponseWriter, r *http.Request) {
+	err := s.profanity.Reload(r.Context())
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestProfanityTerms(t *testing.T) {
+	c := newTestClient(t, Config{AdminKey: "admin-key"})
+	alice := c.login("alice@example.com", "hunter2")
+	const admin = "ApiKey admin-key"
+
+	chirp := validChirp{}
+	c.do("POST", "/api/v1/chirps", alice.Token, chirpPostReq{Body: "what a Kerfuffle! ｓｈａｒｂｅｒｔ"}, &chirp)
+	if chirp.Body != "what a ****! ****" {
+		t.Errorf("was expecting the default terms to be masked keeping punctuation but got %q", chirp.Body)
+	}
+
+	code := c.doAuthorized("GET", "/admin/profanity/terms", "ApiKey wrong-key", nil, nil)
+	if code != http.StatusUnauthorized {
+		t.Errorf("was expecting 401 with the wrong key but got %d", code)
+	}
+	code = newTestClient(t, Config{}).doAuthorized("GET", "/admin/profanity/terms", admin, nil, nil)
+	if code != http.StatusForbidden {
+		t.Errorf("was expecting 403 without ADMIN_KEY but got %d", code)
+	}
+
+	reject := profanityTermRes{}
+	code = c.doAuthorized("POST", "/admin/profanity/terms", admin, profanityTermReq{Term: "Darn  IT", Action: "reject"}, &reject)
+	if code != http.StatusCreated || reject.Term != "darn it" {
+		t.Fatalf("was expecting a normalized term but got %d %+v", code, reject)
+	}
+	code = c.doAuthorized("POST", "/admin/profanity/terms", admin, profanityTermReq{Term: "darn it", Action: "mask"}, nil)
+	if code != http.StatusConflict {
+		t.Errorf("was expecting 409 for the same term but got %d", code)
+	}
+	code = c.do("POST", "/api/v1/chirps", alice.Token, chirpPostReq{Body: "darn, it broke"}, nil)
+	if code != http.StatusUnprocessableEntity {
+		t.Errorf("was expecting 422 for a rejected term but got %d", code)
+	}
+
+	c.doAuthorized("POST", "/admin/profanity/terms", admin, profanityTermReq{Term: "scam*", Action: "moderate"}, nil)
+	flaggedChirp := validChirp{}
+	code = c.do("POST", "/api/v1/chirps", alice.Token, chirpPostReq{Body: "totally not a SCAMMER"}, &flaggedChirp)
+	if code != http.StatusCreated || flaggedChirp.Body != "totally not a SCAMMER" {
+		t.Errorf("was expecting a moderated chirp to be posted as written but got %d %+v", code, flaggedChirp)
+	}
+	flagged := []moderationCaseRes{}
+	c.doAuthorized("GET", "/admin/moderation/cases", admin, nil, &flagged)
+	if len(flagged) != 1 || flagged[0].ChirpID != flaggedChirp.ID || flagged[0].FlaggedTerms[0] != "scam*" {
+		t.Errorf("was expecting a moderation case for the chirp but got %+v", flagged)
+	}
+
+	code = c.doAuthorized("PUT", "/admin/profanity/terms/"+reject.ID.String(), admin, profanityTermReq{Term: "darn it", Action: "mask"}, nil)
+	if code != http.StatusOK {
+		t.Errorf("was expecting 200 changing the action but got %d", code)
+	}
+	c.do("POST", "/api/v1/chirps", alice.Token, chirpPostReq{Body: "darn it."}, &chirp)
+	if chirp.Body != "****." {
+		t.Errorf("was expecting the phrase to be masked now but got %q", chirp.Body)
+	}
+	code = c.doAuthorized("DELETE", "/admin/profanity/terms/"+reject.ID.String(), admin, nil, nil)
+	if code != http.StatusNoContent {
+		t.Errorf("was expecting 204 but got %d", code)
+	}
+	c.do("POST", "/api/v1/chirps", alice.Token, chirpPostReq{Body: "darn it."}, &chirp)
+	if chirp.Body != "darn it." {
+		t.Errorf("was expecting the removed term to be allowed but got %q", chirp.Body)
+	}
+
+	code = c.doAuthorized("POST", "/admin/profanity/terms", admin, profanityTermReq{Term: "f-word", Action: "ban"}, nil)
+	if code != http.StatusUnprocessableEntity {
+		t.Errorf("was expecting 422 for a bad term and action but got %d", code)
+	}
+}
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/christianrm0821/Chirpy/internal/store"
+)
+
+func respondWithJson(w http.ResponseWriter, code int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	res, _ := json.Marshal(payload)
+	w.Write(res)
+}
+
+func mapChirpToValidChirp(myChirp store.Chirp) validChirp {
+	valChirp := validChirp{
+		ID:        myChirp.ID,
+		CreatedAt: myChirp.CreatedAt,
+		UpdatedAt: myChirp.UpdatedAt,
+		Body:      myChirp.Body,
+		UserID:    myChirp.UserID,
+		Hidden:    myChirp.HiddenAt.Valid,
+	}
+	return valChirp
+}
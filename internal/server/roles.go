@@ -0,0 +1,173 @@
+package server
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/christianrm0821/Chirpy/internal/auth"
+	"github.com/christianrm0821/Chirpy/internal/store"
+	"github.com/google/uuid"
+)
+
+// a role can do everything the roles below it can
+var roleRank = map[string]int{store.RoleUser: 0, store.RoleModerator: 1, store.RoleAdmin: 2}
+
+func hasRole(role, required string) bool {
+	rank, ok := roleRank[role]
+	return ok && rank >= roleRank[required]
+}
+
+// middleware for the /admin routes, only users with at least role get through
+// a request with ADMIN_KEY gets through as an admin, it is how the first admin is made
+func (s *Server) requireRole(role string, next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := s.authorize(r, role)
+		if err != nil {
+			s.respondWithError(w, r, err)
+			return
+		}
+		next(w, r)
+	})
+}
+
+func (s *Server) authorize(r *http.Request, role string) error {
+	if strings.HasPrefix(r.Header.Get("Authorization"), "ApiKey ") {
+		err := s.authenticateAdminKey(r)
+		if err != nil {
+			return err
+		}
+		getRequestInfo(r.Context()).Role = store.RoleAdmin
+		return nil
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		return errUnauthorized(err)
+	}
+	userID, claims, err := auth.ParseJWT(token, s.cfg.Secret)
+	if err != nil {
+		return errUnauthorized(err)
+	}
+	setRequestUser(r, userID)
+	//most requests are turned away here without a trip to the store
+	if !hasRole(claims.Role, role) {
+		return errForbidden(fmt.Sprintf("needs the %s role", role))
+	}
+	//a role can be revoked before the token expires, the store has the last word
+	user, err := s.activeUser(r, userID)
+	if err != nil {
+		return err
+	}
+	if !hasRole(user.Role, role) {
+		return errForbidden(fmt.Sprintf("needs the %s role", role))
+	}
+	getRequestInfo(r.Context()).Role = user.Role
+	return nil
+}
+
+// ADMIN_KEY is sent like polka's key, without it set only users with roles get in
+func (s *Server) authenticateAdminKey(r *http.Request) error {
+	if s.cfg.AdminKey == "" {
+		return errForbidden("ADMIN_KEY is not set, log in as an admin instead")
+	}
+	key, err := auth.GetAPIKey(r.Header)
+	if err != nil {
+		return errUnauthorized(err)
+	}
+	if subtle.ConstantTimeCompare([]byte(key), []byte(s.cfg.AdminKey)) != 1 {
+		return errUnauthorized(errors.New("wrong admin key"))
+	}
+	return nil
+}
+
+// who is doing something on an admin route: a moderator or admin is their user id,
+// with ADMIN_KEY there is no user so the request names someone
+func staffName(r *http.Request, named string) (string, error) {
+	if userID := getRequestInfo(r.Context()).UserID; userID != uuid.Nil {
+		return userID.String(), nil
+	}
+	if named == "" {
+		return "", errValidation(fieldError{Field: "moderator", Message: "is required with ADMIN_KEY"})
+	}
+	return named, nil
+}
+
+type roleReq struct {
+	Role string `json:"role"`
+}
+
+func (req roleReq) validate() []fieldError {
+	if _, ok := roleRank[req.Role]; !ok {
+		return []fieldError{{Field: "role", Message: "must be user, moderator or admin"}}
+	}
+	return nil
+}
+
+type roleRes struct {
+	UserID uuid.UUID `json:"user_id"`
+	Role   string    `json:"role"`
+}
+
+// gives a user a role, user takes it away again
+// tokens carry the role, the user gets a new one with POST /api/refresh or by logging in
+func (s *Server) handlerSetUserRole(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.roleTarget(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	request := roleReq{}
+	err = bindJSON(r, &request)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	err = s.setRole(r, userID, request.Role)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	respondWithJson(w, 200, roleRes{UserID: userID, Role: request.Role})
+}
+
+// takes the user's role away, they are left with the user role
+func (s *Server) handlerRevokeUserRole(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.roleTarget(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	err = s.setRole(r, userID, store.RoleUser)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// the user whose role changes, admins can't change their own so there is always one left
+func (s *Server) roleTarget(r *http.Request) (uuid.UUID, error) {
+	userID, err := parseUUID("userID", r.PathValue("userID"))
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if userID == getRequestInfo(r.Context()).UserID {
+		return uuid.Nil, errForbidden("you can't change your own role")
+	}
+	return userID, nil
+}
+
+func (s *Server) setRole(r *http.Request, userID uuid.UUID, role string) error {
+	err := s.store.SetUserRole(r.Context(), userID, role)
+	if errors.Is(err, store.ErrNotFound) {
+		return errNotFound("user", err)
+	}
+	if err != nil {
+		return fmt.Errorf("could not set role: %w", err)
+	}
+	s.requestLog(r).Info("changed role", "user_id", userID, "role", role)
+	return nil
+}
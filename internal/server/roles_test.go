@@ -0,0 +1,81 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRoles(t *testing.T) {
+	c := newTestClient(t, Config{AdminKey: "admin-key"})
+	const adminKey = "ApiKey admin-key"
+	alice := c.login("alice@example.com", "hunter2")
+	bob := c.login("bob@example.com", "password")
+	if alice.Role != "user" {
+		t.Errorf("was expecting new users to have the user role but got %q", alice.Role)
+	}
+
+	code := c.do("GET", "/admin/metrics", "", nil, nil)
+	if code != http.StatusUnauthorized {
+		t.Errorf("was expecting 401 without credentials but got %d", code)
+	}
+	code = c.do("GET", "/admin/metrics", alice.Token, nil, nil)
+	if code != http.StatusForbidden {
+		t.Errorf("was expecting 403 for a plain user but got %d", code)
+	}
+
+	//ADMIN_KEY makes the first admin
+	role := roleRes{}
+	code = c.doAuthorized("PUT", "/admin/users/"+alice.ID.String()+"/role", adminKey, roleReq{Role: "admin"}, &role)
+	if code != http.StatusOK || role.Role != "admin" {
+		t.Fatalf("was expecting 200 granting admin but got %d %+v", code, role)
+	}
+	code = c.do("GET", "/admin/metrics", alice.Token, nil, nil)
+	if code != http.StatusForbidden {
+		t.Errorf("was expecting the old token to keep its role but got %d", code)
+	}
+	tokens := tokenResponse{}
+	c.do("POST", "/api/refresh", alice.RefreshToken, nil, &tokens)
+	adminToken := tokens.Token
+	code = c.do("GET", "/admin/metrics", adminToken, nil, nil)
+	if code != http.StatusOK {
+		t.Errorf("was expecting 200 for an admin but got %d", code)
+	}
+
+	code = c.do("PUT", "/admin/users/"+bob.ID.String()+"/role", adminToken, roleReq{Role: "owner"}, nil)
+	if code != http.StatusUnprocessableEntity {
+		t.Errorf("was expecting 422 for an unknown role but got %d", code)
+	}
+	code = c.do("PUT", "/admin/users/"+bob.ID.String()+"/role", adminToken, roleReq{Role: "moderator"}, nil)
+	if code != http.StatusOK {
+		t.Fatalf("was expecting 200 granting moderator but got %d", code)
+	}
+	code = c.do("PUT", "/admin/users/"+alice.ID.String()+"/role", adminToken, roleReq{Role: "user"}, nil)
+	if code != http.StatusForbidden {
+		t.Errorf("was expecting 403 changing your own role but got %d", code)
+	}
+
+	//moderators get the moderation queue but nothing else
+	bobUser := userReturnEmail{}
+	c.do("POST", "/api/login", "", email{Email: "bob@example.com", Password: "password"}, &bobUser)
+	if bobUser.Role != "moderator" {
+		t.Errorf("was expecting bob to be a moderator but got %q", bobUser.Role)
+	}
+	code = c.do("GET", "/admin/moderation/cases", bobUser.Token, nil, nil)
+	if code != http.StatusOK {
+		t.Errorf("was expecting 200 for a moderator but got %d", code)
+	}
+	code = c.do("GET", "/admin/profanity/terms", bobUser.Token, nil, nil)
+	if code != http.StatusForbidden {
+		t.Errorf("was expecting 403 for a moderator on an admin route but got %d", code)
+	}
+
+	//revoking works right away, even with a token that still has the role
+	code = c.do("DELETE", "/admin/users/"+bob.ID.String()+"/role", adminToken, nil, nil)
+	if code != http.StatusNoContent {
+		t.Fatalf("was expecting 204 revoking the role but got %d", code)
+	}
+	code = c.do("GET", "/admin/moderation/cases", bobUser.Token, nil, nil)
+	if code != http.StatusForbidden {
+		t.Errorf("was expecting 403 after the role was revoked but got %d", code)
+	}
+}
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/christianrm0821/Chirpy/internal/apispec"
+	"github.com/christianrm0821/Chirpy/internal/events"
+	"github.com/christianrm0821/Chirpy/internal/health"
+	"github.com/christianrm0821/Chirpy/internal/notifications"
+	"github.com/christianrm0821/Chirpy/internal/profanity"
+	"github.com/christianrm0821/Chirpy/internal/store"
+	"github.com/christianrm0821/Chirpy/internal/tracing"
+)
+
+// settings the handlers need
+type Config struct {
+	// dev enables POST /admin/reset
+	Platform string
+	// secret used to sign and check jwts
+	Secret string
+	// api key polka sends with its webhooks
+	PolkaKey string
+	// api key that counts as an admin on the /admin endpoints, only users with roles get in when empty
+	AdminKey string
+	// how often the profanity terms are reloaded from the store, 0 only reloads them on changes made here
+	ProfanityReloadInterval time.Duration
+	// how many characters a chirp can have, and for Chirpy Red users, 140 and 280 when 0
+	MaxChirpLength    int
+	MaxChirpLengthRed int
+	// how many users have to report a chirp before it is hidden, 5 when 0
+	ReportHideThreshold int
+	// max size of a request body, 0 means no limit
+	MaxBodyBytes int64
+	// directory served under /app/, nothing is served there when empty
+	StaticDir string
+}
+
+// the chirpy api
+type Server struct {
+	cfg    Config
+	store  store.Store
+	logger *slog.Logger
+	health *health.Registry
+
+	notifications *notifications.Service
+	profanity     *profanity.Filter
+
+	//keeps count of how many requests are being made to /app/
+	fileserverHits atomic.Int32
+
+	//chirp events for /api/stream, set by WithEvents
+	events    *events.Hub
+	publisher events.Publisher
+
+	//set by WithSpecValidation
+	spec              *apispec.Spec
+	validateResponses bool
+
+	middleware []func(http.Handler) http.Handler
+	handler    http.Handler
+}
+
+// changes the defaults of NewServer
+type Option func(*Server)
+
+// sets the logger used for the access log and errors, nothing is logged by default
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// serves /livez and /readyz from the given registry
+func WithHealth(registry *health.Registry) Option {
+	return func(s *Server) {
+		s.health = registry
+	}
+}
+
+// adds middleware around the api, the first one is the outermost
+// they run inside the tracing and request log middleware
+func WithMiddleware(middleware ...func(http.Handler) http.Handler) Option {
+	return func(s *Server) {
+		s.middleware = append(s.middleware, middleware...)
+	}
+}
+
+// makes the api handler with every route registered
+// it can be passed straight to http.Server or mounted in another mux
+func NewServer(cfg Config, store store.Store, opts ...Option) http.Handler {
+	s := &Server{
+		cfg:           cfg,
+		store:         store,
+		logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+		notifications: notifications.NewService(store),
+		profanity:     profanity.NewFilter(store, cfg.ProfanityReloadInterval),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.events == nil {
+		hub := events.NewHub(defaultReplaySize)
+		s.events, s.publisher = hub, hub
+	}
+
+	var handler http.Handler = s.routes()
+	for i := len(s.middleware) - 1; i >= 0; i-- {
+		handler = s.middleware[i](handler)
+	}
+	if s.spec != nil {
+		handler = s.middlewareSpecValidation(handler)
+	}
+	if cfg.MaxBodyBytes > 0 {
+		handler = middlewareMaxBody(cfg.MaxBodyBytes, handler)
+	}
+	s.handler = tracing.Middleware(s.middlewareRequestLog(handler))
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+// mux or multiplexer
+// it is a request router
+// it gets incoming http requests and decides which handler function should process the request
+// maps url patterns to handler functions
+func (s *Server) routes() *router {
+	serveMux := newRouter()
+
+	serveMux.HandleFunc("GET /api/healthz", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	})
+
+	//probes for the load balancer / orchestrator
+	//readyz fails when a dependency is down so traffic goes to other instances
+	if s.health != nil {
+		serveMux.Handle("GET /livez", s.health.LiveHandler())
+		serveMux.Handle("GET /readyz", s.health.ReadyHandler())
+	}
+
+	//Strip prefix takes away the prefix "/app" from the handler
+	//FileServer serves static content
+	if s.cfg.StaticDir != "" {
+		appHandler := http.StripPrefix("/app", http.FileServer(http.Dir(s.cfg.StaticDir)))
+		serveMux.Handle("/app/", s.middlewareMetricsInc(appHandler))
+	}
+
+	//live chirp events, like healthz they aren't part of a versioned api
+	serveMux.HandleFunc("GET /api/stream", s.handlerStream)
+	serveMux.HandleFunc("GET /api/ws", s.handlerWebSocket)
+
+	//api description and the page that renders it
+	serveMux.HandleFunc("GET /api/openapi.json", s.handlerOpenAPI)
+	serveMux.Handle("GET /app/docs/", docsHandler())
+
+	//the admin api needs a role, or ADMIN_KEY which counts as an admin
+	admin := func(pattern string, handler http.HandlerFunc) {
+		serveMux.Handle(pattern, s.requireRole(store.RoleAdmin, handler))
+	}
+	moderator := func(pattern string, handler http.HandlerFunc) {
+		serveMux.Handle(pattern, s.requireRole(store.RoleModerator, handler))
+	}
+	admin("GET /admin/metrics", s.handlerMetrics)
+	admin("POST /admin/reset", s.handlerReset)
+	admin("GET /admin/profanity/terms", s.handlerListProfanityTerms)
+	admin("POST /admin/profanity/terms", s.handlerCreateProfanityTerm)
+	admin("PUT /admin/profanity/terms/{termID}", s.handlerUpdateProfanityTerm)
+	admin("DELETE /admin/profanity/terms/{termID}", s.handlerDeleteProfanityTerm)
+	admin("POST /admin/profanity/reload", s.handlerReloadProfanityTerms)
+	admin("PUT /admin/users/{userID}/role", s.handlerSetUserRole)
+	admin("DELETE /admin/users/{userID}/role", s.handlerRevokeUserRole)
+	moderator("GET /admin/moderation/cases", s.handlerListModerationCases)
+	moderator("GET /admin/moderation/cases/{caseID}", s.handlerGetModerationCase)
+	moderator("POST /admin/moderation/cases/{caseID}/claim", s.handlerClaimModerationCase)
+	moderator("POST /admin/moderation/cases/{caseID}/resolve", s.handlerResolveModerationCase)
+	moderator("DELETE /admin/users/{userID}/suspension", s.handlerLiftSuspension)
+
+	//the api, /api/* is the original unversioned api and is kept as a deprecated alias of v1
+	s.versionRoutes(serveMux, "/api/v1", apiV1, false)
+	s.versionRoutes(serveMux, "/api/v2", apiV2, false)
+	s.versionRoutes(serveMux, "/api", apiV1, true)
+
+	return serveMux
+}
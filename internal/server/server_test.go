@@ -0,0 +1,475 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/christianrm0821/Chirpy/internal/store"
+	"github.com/google/uuid"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+type testClient struct {
+	t       *testing.T
+	handler http.Handler
+}
+
+func newTestClient(t *testing.T, cfg Config, opts ...Option) *testClient {
+	if cfg.Secret == "" {
+		cfg.Secret = testSecret
+	}
+	return &testClient{t: t, handler: NewServer(cfg, store.NewMemory(), opts...)}
+}
+
+// sends the request and decodes the json response into out (if out is not nil)
+func (c *testClient) do(method, path, token string, body any, out any) int {
+	c.t.Helper()
+	authorization := ""
+	if token != "" {
+		authorization = "Bearer " + token
+	}
+	return c.doAuthorized(method, path, authorization, body, out)
+}
+
+// like do but the Authorization header is sent as given
+func (c *testClient) doAuthorized(method, path, authorization string, body any, out any) int {
+	c.t.Helper()
+	var reqBody bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&reqBody).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &reqBody)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	rec := httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, req)
+	if out != nil && rec.Body.Len() > 0 {
+		err := json.Unmarshal(rec.Body.Bytes(), out)
+		if err != nil {
+			c.t.Fatalf("%s %s: could not decode response %q: %v", method, path, rec.Body.String(), err)
+		}
+	}
+	return rec.Code
+}
+
+// creates a user and logs them in
+func (c *testClient) login(emailAddr, password string) userReturnEmail {
+	c.t.Helper()
+	code := c.do("POST", "/api/users", "", email{Email: emailAddr, Password: password}, nil)
+	if code != http.StatusCreated {
+		c.t.Fatalf("was expecting 201 creating %s but got %d", emailAddr, code)
+	}
+	user := userReturnEmail{}
+	code = c.do("POST", "/api/login", "", email{Email: emailAddr, Password: password}, &user)
+	if code != http.StatusOK {
+		c.t.Fatalf("was expecting 200 logging in %s but got %d", emailAddr, code)
+	}
+	return user
+}
+
+func TestChirpLifecycle(t *testing.T) {
+	c := newTestClient(t, Config{})
+	alice := c.login("alice@example.com", "hunter2")
+	bob := c.login("bob@example.com", "password")
+
+	chirp := validChirp{}
+	code := c.do("POST", "/api/chirps", alice.Token, chirpPostReq{Body: "what a kerfuffle"}, &chirp)
+	if code != http.StatusCreated {
+		t.Fatalf("was expecting 201 but got %d", code)
+	}
+	if chirp.Body != "what a ****" || chirp.UserID != alice.ID {
+		t.Errorf("chirp was not cleaned or has the wrong author: %+v", chirp)
+	}
+
+	code = c.do("POST", "/api/chirps", "", chirpPostReq{Body: "no token"}, nil)
+	if code != http.StatusUnauthorized {
+		t.Errorf("was expecting 401 without a token but got %d", code)
+	}
+
+	c.do("POST", "/api/chirps", bob.Token, chirpPostReq{Body: "hello from bob"}, nil)
+
+	var chirps []validChirp
+	c.do("GET", "/api/chirps?author_id="+alice.ID.String(), "", nil, &chirps)
+	if len(chirps) != 1 || chirps[0].ID != chirp.ID {
+		t.Errorf("was expecting only alice's chirp but got %+v", chirps)
+	}
+
+	code = c.do("DELETE", "/api/chirps/"+chirp.ID.String(), bob.Token, nil, nil)
+	if code != http.StatusForbidden {
+		t.Errorf("was expecting 403 when bob deletes alice's chirp but got %d", code)
+	}
+	code = c.do("DELETE", "/api/chirps/"+chirp.ID.String(), alice.Token, nil, nil)
+	if code != http.StatusNoContent {
+		t.Errorf("was expecting 204 but got %d", code)
+	}
+	code = c.do("GET", "/api/chirps/"+chirp.ID.String(), "", nil, nil)
+	if code != http.StatusNotFound {
+		t.Errorf("was expecting 404 for a deleted chirp but got %d", code)
+	}
+}
+
+func TestChirpLength(t *testing.T) {
+	c := newTestClient(t, Config{PolkaKey: "polka-key", MaxChirpLength: 10, MaxChirpLengthRed: 20})
+	alice := c.login("alice@example.com", "hunter2")
+
+	tests := []struct {
+		body   string
+		status int
+	}{
+		//ten characters but far more than ten bytes
+		{"こんにちは👨‍👩‍👧🇯🇵🙂ab", http.StatusCreated},
+		{"see https://example.com/a/long/link", http.StatusUnprocessableEntity},
+		{"abcdefghijk", http.StatusUnprocessableEntity},
+		//one character with thousands of combining marks, and a link far too long to count as one
+		{"e" + strings.Repeat("\u0301", maxChirpBytes/2), http.StatusUnprocessableEntity},
+		{"https://example.com/" + strings.Repeat("a", 600), http.StatusUnprocessableEntity},
+	}
+	for _, test := range tests {
+		if code := c.do("POST", "/api/chirps", alice.Token, chirpPostReq{Body: test.body}, nil); code != test.status {
+			t.Errorf("%q: was expecting %d but got %d", test.body, test.status, code)
+		}
+	}
+
+	res := resErr{}
+	c.do("POST", "/api/chirps", alice.Token, chirpPostReq{Body: "abcdefghijk"}, &res)
+	if len(res.Details) != 1 || res.Details[0].Length != 11 || res.Details[0].Limit != 10 {
+		t.Errorf("was expecting the length and limit in the error but got %+v", res)
+	}
+
+	webhook := polkaRequest{Event: "user.upgraded"}
+	webhook.Data.UserID = alice.ID.String()
+	c.doAuthorized("POST", "/api/polka/webhooks", "ApiKey polka-key", webhook, nil)
+	res = resErr{}
+	code := c.do("POST", "/api/chirps", alice.Token, chirpPostReq{Body: strings.Repeat("a", 21)}, &res)
+	if code != http.StatusUnprocessableEntity || res.Details[0].Limit != 20 {
+		t.Errorf("was expecting the chirpy red limit but got %d %+v", code, res)
+	}
+	code = c.do("POST", "/api/chirps", alice.Token, chirpPostReq{Body: "abcdefghijk"}, nil)
+	if code != http.StatusCreated {
+		t.Errorf("was expecting chirpy red users to post longer chirps but got %d", code)
+	}
+}
+
+func TestRefreshAndRevoke(t *testing.T) {
+	c := newTestClient(t, Config{})
+	alice := c.login("alice@example.com", "hunter2")
+
+	refreshed := tokenResponse{}
+	code := c.do("POST", "/api/refresh", alice.RefreshToken, nil, &refreshed)
+	if code != http.StatusOK || refreshed.Token == "" {
+		t.Fatalf("was expecting a new token but got %d %+v", code, refreshed)
+	}
+	code = c.do("POST", "/api/chirps", refreshed.Token, chirpPostReq{Body: "refreshed"}, nil)
+	if code != http.StatusCreated {
+		t.Errorf("refreshed token should work but got %d", code)
+	}
+
+	code = c.do("POST", "/api/revoke", alice.RefreshToken, nil, nil)
+	if code != http.StatusNoContent {
+		t.Errorf("was expecting 204 but got %d", code)
+	}
+	code = c.do("POST", "/api/refresh", alice.RefreshToken, nil, nil)
+	if code != http.StatusUnauthorized {
+		t.Errorf("revoked token should be rejected but got %d", code)
+	}
+}
+
+func TestPolkaWebhook(t *testing.T) {
+	c := newTestClient(t, Config{PolkaKey: "polka-key"})
+	alice := c.login("alice@example.com", "hunter2")
+
+	event := polkaRequest{Event: "user.upgraded"}
+	event.Data.UserID = alice.ID.String()
+
+	req := httptest.NewRequest("POST", "/api/polka/webhooks", nil)
+	req.Header.Set("Authorization", "ApiKey wrong-key")
+	rec := httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("was expecting 401 with the wrong key but got %d", rec.Code)
+	}
+
+	body, _ := json.Marshal(event)
+	req = httptest.NewRequest("POST", "/api/polka/webhooks", bytes.NewReader(body))
+	req.Header.Set("Authorization", "ApiKey polka-key")
+	rec = httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("was expecting 204 but got %d", rec.Code)
+	}
+
+	user := userReturnEmail{}
+	c.do("POST", "/api/login", "", email{Email: "alice@example.com", Password: "hunter2"}, &user)
+	if !user.IsChirpyRed {
+		t.Error("user should be upgraded to chirpy red")
+	}
+}
+
+func TestReset(t *testing.T) {
+	c := newTestClient(t, Config{Platform: "prod", AdminKey: "admin-key"})
+	code := c.doAuthorized("POST", "/admin/reset", "ApiKey admin-key", nil, nil)
+	if code == http.StatusOK {
+		t.Error("reset should not work outside of dev")
+	}
+
+	c = newTestClient(t, Config{Platform: "dev", AdminKey: "admin-key"})
+	c.login("alice@example.com", "hunter2")
+	code = c.do("POST", "/admin/reset", "", nil, nil)
+	if code != http.StatusUnauthorized {
+		t.Errorf("was expecting 401 resetting without credentials but got %d", code)
+	}
+	code = c.doAuthorized("POST", "/admin/reset", "ApiKey admin-key", nil, nil)
+	if code != http.StatusOK {
+		t.Fatalf("was expecting 200 but got %d", code)
+	}
+	code = c.do("POST", "/api/login", "", email{Email: "alice@example.com", Password: "hunter2"}, nil)
+	if code != http.StatusUnauthorized {
+		t.Errorf("users should be gone after a reset but login got %d", code)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	c := newTestClient(t, Config{AdminKey: "admin-key"}, WithLogger(logger))
+	req := httptest.NewRequest("GET", "/api/healthz", nil)
+	req.Header.Set(requestIDHeader, "abc-123")
+	rec := httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, req)
+	if rec.Header().Get(requestIDHeader) != "abc-123" {
+		t.Errorf("request id should be passed back but got %q", rec.Header().Get(requestIDHeader))
+	}
+
+	//without one the server makes one up
+	alice := c.login("alice@example.com", "hunter2")
+	logs.Reset()
+	req = httptest.NewRequest("PUT", "/admin/users/"+alice.ID.String()+"/role", strings.NewReader(`{"role":"moderator"}`))
+	req.Header.Set("Authorization", "ApiKey admin-key")
+	rec = httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, req)
+	requestID := rec.Header().Get(requestIDHeader)
+	if _, err := uuid.Parse(requestID); err != nil {
+		t.Fatalf("was expecting a generated request id but got %q", requestID)
+	}
+
+	//the handler's log and the access log both carry it
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		entry := map[string]any{}
+		json.Unmarshal([]byte(line), &entry)
+		lines = append(lines, entry)
+	}
+	if len(lines) != 2 || lines[0]["msg"] != "changed role" || lines[1]["msg"] != "request" {
+		t.Fatalf("was expecting a handler log and an access log but got %s", logs.String())
+	}
+	for _, entry := range lines {
+		if entry["request_id"] != requestID {
+			t.Errorf("was expecting request id %s but got %v", requestID, entry)
+		}
+	}
+	if strings.Contains(logs.String(), "admin-key") {
+		t.Errorf("the admin key should not be logged but got %s", logs.String())
+	}
+
+	//tokens aren't logged either
+	logs.Reset()
+	c.do("GET", "/api/v1/notifications", alice.Token, nil, nil)
+	c.do("POST", "/api/refresh", alice.RefreshToken, nil, nil)
+	if strings.Contains(logs.String(), alice.Token) || strings.Contains(logs.String(), alice.RefreshToken) {
+		t.Errorf("tokens should not be logged but got %s", logs.String())
+	}
+}
+
+func TestErrorResponses(t *testing.T) {
+	c := newTestClient(t, Config{})
+	c.login("alice@example.com", "hunter2")
+
+	body := resErr{}
+	code := c.do("POST", "/api/users", "", email{Email: "alice@example.com", Password: "other"}, &body)
+	if code != http.StatusConflict || body.Code != codeEmailTaken {
+		t.Errorf("was expecting 409 %s for a duplicate email but got %d %+v", codeEmailTaken, code, body)
+	}
+	if strings.Contains(body.Error, "alice@example.com") {
+		t.Errorf("error message should not leak internal details: %q", body.Error)
+	}
+
+	req := httptest.NewRequest("POST", "/api/users", strings.NewReader("{not json"))
+	rec := httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), codeInvalidJSON) {
+		t.Errorf("was expecting 400 %s but got %d %s", codeInvalidJSON, rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/api/chirps/"+uuid.NewString(), nil)
+	req.Header.Set("Accept", problemContentType)
+	rec = httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, req)
+	problem := problemDetails{}
+	json.Unmarshal(rec.Body.Bytes(), &problem)
+	if rec.Header().Get("Content-Type") != problemContentType {
+		t.Errorf("was expecting %s but got %q", problemContentType, rec.Header().Get("Content-Type"))
+	}
+	if problem.Status != http.StatusNotFound || problem.Code != codeNotFound || problem.Instance != req.URL.Path {
+		t.Errorf("unexpected problem details: %+v", problem)
+	}
+}
+
+func TestInputValidation(t *testing.T) {
+	c := newTestClient(t, Config{MaxBodyBytes: 256, PolkaKey: "polka-key"})
+	alice := c.login("alice@example.com", "hunter2")
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		token   string
+		body    string
+		status  int
+		code    string
+		field   string
+		headers map[string]string
+	}{
+		{"bad chirp id", "GET", "/api/chirps/not-a-uuid", "", "", 400, codeInvalidParam, "chirpID", nil},
+		{"bad chirp id on delete", "DELETE", "/api/chirps/123", alice.Token, "", 400, codeInvalidParam, "chirpID", nil},
+		{"bad author id", "GET", "/api/chirps?author_id=nope", "", "", 400, codeInvalidParam, "author_id", nil},
+		{"bad sort", "GET", "/api/chirps?sort=sideways", "", "", 400, codeInvalidParam, "sort", nil},
+		{"unknown field", "POST", "/api/users", "", `{"email":"bob@example.com","password":"x","hashed_password":"x"}`, 400, codeInvalidJSON, "hashed_password", nil},
+		{"wrong type", "POST", "/api/users", "", `{"email":42,"password":"x"}`, 400, codeInvalidJSON, "email", nil},
+		{"empty body", "POST", "/api/users", "", "", 400, codeInvalidJSON, "", nil},
+		{"bad email", "POST", "/api/users", "", `{"email":"Bob <bob@example.com>","password":"x"}`, 422, codeValidation, "email", nil},
+		{"missing password", "POST", "/api/users", "", `{"email":"bob@example.com"}`, 422, codeValidation, "password", nil},
+		{"empty chirp", "POST", "/api/chirps", alice.Token, `{"body":"  "}`, 422, codeValidation, "body", nil},
+		{"body too large", "POST", "/api/chirps", alice.Token, `{"body":"` + strings.Repeat("a", 300) + `"}`, 413, codeBodyTooLarge, "", nil},
+		{"bad polka user id", "POST", "/api/polka/webhooks", "", `{"event":"user.upgraded","data":{"user_id":"nope"}}`, 400, codeInvalidParam, "data.user_id", map[string]string{"Authorization": "ApiKey polka-key"}},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+		for k, v := range tc.headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		c.handler.ServeHTTP(rec, req)
+
+		body := resErr{}
+		json.Unmarshal(rec.Body.Bytes(), &body)
+		if rec.Code != tc.status || body.Code != tc.code {
+			t.Errorf("%s: was expecting %d %s but got %d %s", tc.name, tc.status, tc.code, rec.Code, rec.Body.String())
+			continue
+		}
+		if tc.field != "" && (len(body.Details) != 1 || body.Details[0].Field != tc.field) {
+			t.Errorf("%s: was expecting details for %s but got %+v", tc.name, tc.field, body.Details)
+		}
+	}
+}
+
+func TestVersions(t *testing.T) {
+	c := newTestClient(t, Config{})
+	alice := c.login("alice@example.com", "hunter2")
+	for _, body := range []string{"one", "two", "three", "four", "five"} {
+		c.do("POST", "/api/v2/chirps", alice.Token, chirpPostReq{Body: body}, nil)
+	}
+
+	//v1 and the old unversioned routes return a bare array with everything
+	for _, path := range []string{"/api/chirps", "/api/v1/chirps"} {
+		var chirps []validChirp
+		c.do("GET", path, "", nil, &chirps)
+		if len(chirps) != 5 {
+			t.Errorf("%s: was expecting 5 chirps but got %d", path, len(chirps))
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/api/chirps", nil)
+	rec := httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, req)
+	if rec.Header().Get("Deprecation") == "" || rec.Header().Get("Sunset") == "" {
+		t.Errorf("unversioned routes should be deprecated but got headers %v", rec.Header())
+	}
+	if link := rec.Header().Get("Link"); link != `</api/v1/chirps>; rel="successor-version"` {
+		t.Errorf("was expecting a link to the v1 route but got %q", link)
+	}
+	rec = httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/chirps", nil))
+	if rec.Header().Get("Deprecation") != "" {
+		t.Error("v1 should not be deprecated")
+	}
+
+	//v2 pages through the chirps
+	type chirpPage struct {
+		Data       []validChirp `json:"data"`
+		Pagination pagination   `json:"pagination"`
+	}
+	var bodies []string
+	path := "/api/v2/chirps?limit=2&sort=desc"
+	for i := 0; i < 5; i++ {
+		page := chirpPage{}
+		code := c.do("GET", path, "", nil, &page)
+		if code != http.StatusOK || page.Pagination.Limit != 2 {
+			t.Fatalf("was expecting a page of 2 but got %d %+v", code, page)
+		}
+		for _, chirp := range page.Data {
+			bodies = append(bodies, chirp.Body)
+		}
+		if page.Pagination.NextCursor == "" {
+			break
+		}
+		path = "/api/v2/chirps?limit=2&sort=desc&cursor=" + page.Pagination.NextCursor
+	}
+	if strings.Join(bodies, ",") != "five,four,three,two,one" {
+		t.Errorf("was expecting every chirp newest first but got %v", bodies)
+	}
+
+	code := c.do("GET", "/api/v2/chirps?cursor=nope", "", nil, nil)
+	if code != http.StatusBadRequest {
+		t.Errorf("was expecting 400 for a bad cursor but got %d", code)
+	}
+
+	single := struct {
+		Data validChirp `json:"data"`
+	}{}
+	c.do("GET", "/api/v2/chirps/"+c.firstChirpID(), "", nil, &single)
+	if single.Data.ID == uuid.Nil {
+		t.Errorf("was expecting the chirp inside data but got %+v", single)
+	}
+}
+
+// v1 keeps the null the original api sent for no chirps, v2 sends an empty list
+func TestEmptyChirpList(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	c := newTestClient(t, Config{}, WithLogger(logger), WithSpecValidation(true))
+	for path, want := range map[string]string{
+		"/api/chirps":    "null",
+		"/api/v1/chirps": "null",
+		"/api/v2/chirps": `{"data":[],"pagination":{"limit":20}}`,
+	} {
+		rec := httptest.NewRecorder()
+		c.handler.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if body := strings.TrimSpace(rec.Body.String()); body != want {
+			t.Errorf("%s: was expecting %s but got %s", path, want, body)
+		}
+	}
+	if logs.Len() > 0 {
+		t.Errorf("responses drifted from openapi.json:\n%s", logs.String())
+	}
+}
+
+// the id of the oldest chirp
+func (c *testClient) firstChirpID() string {
+	c.t.Helper()
+	var chirps []validChirp
+	c.do("GET", "/api/v1/chirps", "", nil, &chirps)
+	if len(chirps) == 0 {
+		c.t.Fatal("there are no chirps")
+	}
+	return chirps[0].ID.String()
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/christianrm0821/Chirpy/internal/events"
+	"github.com/christianrm0821/Chirpy/internal/store"
+)
+
+// how often a comment is sent on an idle stream so proxies don't close it
+const streamKeepAlive = 15 * time.Second
+
+// events kept for clients that reconnect when WithEvents is not used
+const defaultReplaySize = 1000
+
+// sends chirp events to the given hub through publisher
+// with several instances the publisher shares events between them (see events.PostgresRelay)
+func WithEvents(hub *events.Hub, publisher events.Publisher) Option {
+	return func(s *Server) {
+		s.events = hub
+		s.publisher = publisher
+	}
+}
+
+// tells every stream about something that happened to a chirp
+// the change is already saved so a failure is only logged
+func (s *Server) publish(r *http.Request, eventType string, chirp store.Chirp) {
+	event := events.Event{Type: eventType, Chirp: chirp}
+	if event.Removed() {
+		event.Chirp = store.Chirp{ID: chirp.ID, UserID: chirp.UserID}
+	}
+	err := s.publisher.Publish(r.Context(), event)
+	if err != nil {
+		s.requestLog(r).Error("could not publish event", "type", eventType, "chirp_id", chirp.ID, "error", err)
+	}
+}
+
+// the filters of GET /api/stream
+func bindStreamQuery(r *http.Request) (events.Filter, uint64, error) {
+	filter := events.Filter{}
+	query := r.URL.Query()
+	if value := query.Get("author_id"); value != "" {
+		authorID, err := parseUUID("author_id", value)
+		if err != nil {
+			return filter, 0, err
+		}
+		filter.AuthorID = authorID
+	}
+	filter.Hashtag = strings.TrimPrefix(query.Get("hashtag"), "#")
+
+	var lastID uint64
+	if value := r.Header.Get("Last-Event-ID"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return filter, 0, errInvalidParam("Last-Event-ID", "must be an event id from this stream", err)
+		}
+		lastID = id
+	}
+	return filter, lastID, nil
+}
+
+// server-sent events stream of created, deleted, hidden and unhidden chirps
+// clients that reconnect with Last-Event-ID get the events they missed if they are still buffered
+func (s *Server) handlerStream(w http.ResponseWriter, r *http.Request) {
+	filter, lastID, err := bindStreamQuery(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+
+	//the stream outlives WRITE_TIMEOUT so the deadline is cleared
+	rc := http.NewResponseController(w)
+	err = rc.SetWriteDeadline(time.Time{})
+	if err != nil && err != http.ErrNotSupported {
+		s.respondWithError(w, r, fmt.Errorf("could not clear the write deadline: %w", err))
+		return
+	}
+
+	sub, replay, missed := s.events.Subscribe(filter, lastID)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", (3 * time.Second).Milliseconds())
+	if missed {
+		//some events were dropped from the buffer, the client should refetch the chirps it shows
+		fmt.Fprint(w, "event: stream.reset\ndata: {}\n\n")
+	}
+	for _, event := range replay {
+		writeEvent(w, event)
+	}
+	rc.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				//the server is shutting down or the client fell too far behind
+				return
+			}
+			writeEvent(w, event)
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		if rc.Flush() != nil {
+			return
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event events.Event) {
+	var chirp any = mapChirpToValidChirp(event.Chirp)
+	if event.Removed() {
+		chirp = removedChirp{ID: event.Chirp.ID, UserID: event.Chirp.UserID}
+	}
+	data, _ := json.Marshal(chirp)
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// reads one server-sent event, skipping comments and the retry line
+func readEvent(t *testing.T, r *bufio.Reader) map[string]string {
+	t.Helper()
+	event := map[string]string{}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream ended: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			if len(event) > 0 && event["retry"] == "" {
+				return event
+			}
+			event = map[string]string{}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		name, value, _ := strings.Cut(line, ": ")
+		event[name] = value
+	}
+}
+
+func TestStream(t *testing.T) {
+	c := newTestClient(t, Config{})
+	ts := httptest.NewServer(c.handler)
+	defer ts.Close()
+	alice := c.login("alice@example.com", "hunter2")
+	bob := c.login("bob@example.com", "password")
+
+	open := func(query, lastID string) (*bufio.Reader, func()) {
+		req, _ := http.NewRequest("GET", ts.URL+"/api/stream"+query, nil)
+		if lastID != "" {
+			req.Header.Set("Last-Event-ID", lastID)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != "text/event-stream" {
+			t.Fatalf("was expecting an event stream but got %d %s", res.StatusCode, res.Header.Get("Content-Type"))
+		}
+		return bufio.NewReader(res.Body), func() { res.Body.Close() }
+	}
+
+	all, closeAll := open("", "")
+	defer closeAll()
+	tagged, closeTagged := open("?hashtag=%23Go&author_id="+alice.ID.String(), "")
+	defer closeTagged()
+	//give the handlers a moment to subscribe
+	time.Sleep(50 * time.Millisecond)
+
+	chirp := validChirp{}
+	c.do("POST", "/api/chirps", bob.Token, chirpPostReq{Body: "bob likes #go"}, nil)
+	c.do("POST", "/api/chirps", alice.Token, chirpPostReq{Body: "alice likes #go"}, &chirp)
+	c.do("DELETE", "/api/chirps/"+chirp.ID.String(), alice.Token, nil, nil)
+
+	first := readEvent(t, all)
+	if first["event"] != "chirp.created" || first["id"] != "1" || !strings.Contains(first["data"], "bob likes") {
+		t.Errorf("was expecting bob's chirp first but got %v", first)
+	}
+	for _, want := range []string{"chirp.created", "chirp.deleted"} {
+		event := readEvent(t, tagged)
+		if event["event"] != want || !strings.Contains(event["data"], chirp.ID.String()) {
+			t.Errorf("was expecting %s for alice's chirp but got %v", want, event)
+		}
+	}
+
+	//a client reconnecting after event 1 gets the rest again
+	resumed, closeResumed := open("", "1")
+	defer closeResumed()
+	if event := readEvent(t, resumed); event["id"] != "2" {
+		t.Errorf("was expecting the stream to resume at event 2 but got %v", event)
+	}
+
+	code := c.do("GET", "/api/stream?author_id=nope", "", nil, nil)
+	if code != http.StatusBadRequest {
+		t.Errorf("was expecting 400 for a bad author_id but got %d", code)
+	}
+}
+
+func TestStreamHidden(t *testing.T) {
+	c := newTestClient(t, Config{AdminKey: "admin-key", ReportHideThreshold: 1})
+	ts := httptest.NewServer(c.handler)
+	defer ts.Close()
+	alice := c.login("alice@example.com", "hunter2")
+	bob := c.login("bob@example.com", "password")
+
+	res, err := http.Get(ts.URL + "/api/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	stream := bufio.NewReader(res.Body)
+	time.Sleep(50 * time.Millisecond)
+
+	chirp := validChirp{}
+	c.do("POST", "/api/chirps", alice.Token, chirpPostReq{Body: "buy my stuff"}, &chirp)
+	c.do("POST", "/api/v1/chirps/"+chirp.ID.String()+"/reports", bob.Token, reportReq{Reason: "spam"}, nil)
+	cases := []moderationCaseRes{}
+	c.doAuthorized("GET", "/admin/moderation/cases", "ApiKey admin-key", nil, &cases)
+	if len(cases) != 1 {
+		t.Fatalf("was expecting one case but got %+v", cases)
+	}
+	c.doAuthorized("POST", "/admin/moderation/cases/"+cases[0].ID.String()+"/resolve", "ApiKey admin-key", resolveCaseReq{Moderator: "mod-a", Action: "dismiss"}, nil)
+	c.do("DELETE", "/api/chirps/"+chirp.ID.String(), alice.Token, nil, nil)
+
+	for _, want := range []string{"chirp.created", "chirp.hidden", "chirp.unhidden", "chirp.deleted"} {
+		event := readEvent(t, stream)
+		if event["event"] != want || !strings.Contains(event["data"], chirp.ID.String()) {
+			t.Errorf("was expecting %s for the reported chirp but got %v", want, event)
+		}
+		//listeners can't see a hidden or deleted chirp so they don't get what it said
+		removed := want == "chirp.hidden" || want == "chirp.deleted"
+		if removed == strings.Contains(event["data"], "buy my stuff") {
+			t.Errorf("was expecting the body in %s only when the chirp is visible but got %v", want, event)
+		}
+	}
+}
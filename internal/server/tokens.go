@@ -0,0 +1,215 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/christianrm0821/Chirpy/internal/auth"
+	"github.com/christianrm0821/Chirpy/internal/store"
+	"github.com/christianrm0821/Chirpy/internal/tracing"
+	"github.com/google/uuid"
+)
+
+// logs the user in with the given email and password
+// makes an access token that lasts 1 hour and a refresh token that lasts 60 days
+func (s *Server) handlerLogin(w http.ResponseWriter, r *http.Request) {
+	request := email{}
+	err := bindJSON(r, &request)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+
+	user, err := s.store.GetUserByEmail(r.Context(), request.Email)
+	if errors.Is(err, store.ErrNotFound) {
+		s.respondWithError(w, r, errUnauthorized(err))
+		return
+	}
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("error getting user: %w", err))
+		return
+	}
+
+	//checks if the password is correct
+	_, checkSpan := tracing.Tracer().Start(r.Context(), "bcrypt.CheckPasswordHash")
+	err = auth.CheckPasswordHash(user.HashedPassword, request.Password)
+	checkSpan.End()
+	if err != nil {
+		s.respondWithError(w, r, errUnauthorized(err))
+		return
+	}
+	if user.SuspendedAt.Valid {
+		s.respondWithError(w, r, errSuspended())
+		return
+	}
+
+	//This is getting a time of 1 hour which is the token life length
+	expiredTimeDuration, err := time.ParseDuration("1h")
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not convert time to duration: %w", err))
+		return
+	}
+
+	//makes a new token with current user ID, role, secret and expiration time
+	token, err := auth.MakeJWTWithRole(user.ID, user.Role, s.cfg.Secret, expiredTimeDuration)
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not make token: %w", err))
+		return
+	}
+
+	//make a new fresh token
+	freshToken, err := auth.MakeRefreshToken()
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not produce a fresh token: %w", err))
+		return
+	}
+
+	//gets 60 days since that is the expire time of the refresh token
+	expireTimeRefresh := time.Hour * 24 * 60
+
+	//create a struct to input the refresh token into the database
+	refreshTokenDataBase := store.RefreshToken{
+		Token:     freshToken,
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().UTC().Add(expireTimeRefresh),
+		RevokedAt: sql.NullTime{Valid: false},
+	}
+
+	_, err = s.store.CreateRefreshToken(r.Context(), refreshTokenDataBase)
+	if err != nil {
+		s.respondWithError(w, r, errInternal(fmt.Errorf("could not add refresh token to database: %w", err)))
+		return
+	}
+
+	respondWithData(w, r, 200, userReturnEmail{
+		ID:           user.ID,
+		CreatedAt:    user.CreatedAt,
+		UpdatedAt:    user.UpdatedAt,
+		Email:        user.Email,
+		Token:        token,
+		RefreshToken: freshToken,
+		IsChirpyRed:  user.IsChirpyRed,
+		Role:         user.Role,
+		Handle:       user.Handle,
+	})
+}
+
+// gets a new token for the given user and sets the lifespan to 1 hour
+func (s *Server) handlerRefresh(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		s.respondWithError(w, r, errUnauthorized(err))
+		return
+	}
+	user, err := s.store.GetRefreshToken(r.Context(), token)
+	if errors.Is(err, store.ErrNotFound) {
+		s.respondWithError(w, r, errUnauthorized(err))
+		return
+	}
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("error getting refresh token: %w", err))
+		return
+	}
+	if time.Now().After(user.ExpiresAt) {
+		s.respondWithError(w, r, errUnauthorized(errors.New("token has expired")))
+		return
+	}
+
+	if user.RevokedAt.Valid {
+		s.respondWithError(w, r, errUnauthorized(errors.New("token revoked")))
+		return
+	}
+	setRequestUser(r, user.UserID)
+	//the new token has the role the user has now
+	account, err := s.activeUser(r, user.UserID)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+
+	newToken, err := auth.MakeJWTWithRole(user.UserID, account.Role, s.cfg.Secret, time.Hour)
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not make new token: %w", err))
+		return
+	}
+	respondWithData(w, r, 200, tokenResponse{
+		Token: newToken,
+	})
+}
+
+// sets the revoke time of the refresh token to current time
+func (s *Server) handlerRevoke(w http.ResponseWriter, r *http.Request) {
+	//gets token from header
+	refreshToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		s.respondWithError(w, r, errUnauthorized(err))
+		return
+	}
+
+	//get current user
+	user, err := s.store.GetRefreshToken(r.Context(), refreshToken)
+	if err != nil {
+		w.WriteHeader(204)
+		return
+	}
+	if time.Now().After(user.ExpiresAt) {
+		w.WriteHeader(204)
+		return
+	}
+
+	if user.RevokedAt.Valid {
+		w.WriteHeader(204)
+		return
+	}
+
+	//changes the revoke time, updated_at time for the given token to the current time
+	err = s.store.RevokeRefreshToken(r.Context(), refreshToken, time.Now())
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("error updating the database: %w", err))
+		return
+	}
+
+	//Sets header code to 204
+	w.WriteHeader(204)
+}
+
+// checks the access token of the request and returns the user it belongs to
+func (s *Server) authenticate(r *http.Request) (uuid.UUID, error) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		return uuid.Nil, errUnauthorized(err)
+	}
+	userID, err := auth.ValidateJWT(token, s.cfg.Secret)
+	if err != nil {
+		return uuid.Nil, errUnauthorized(err)
+	}
+	setRequestUser(r, userID)
+	return userID, nil
+}
+
+// like authenticate but for requests that change something, suspended users are forbidden
+func (s *Server) authenticateActive(r *http.Request) (uuid.UUID, error) {
+	userID, err := s.authenticate(r)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	_, err = s.activeUser(r, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return userID, nil
+}
+
+// the user of the access token when the request has one, Nil when it has none
+// a token that is there but invalid is still an error
+func (s *Server) optionalUser(r *http.Request) (uuid.UUID, error) {
+	if r.Header.Get("Authorization") == "" {
+		return uuid.Nil, nil
+	}
+	return s.authenticate(r)
+}
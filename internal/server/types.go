@@ -0,0 +1,60 @@
+package server
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type tokenResponse struct {
+	Token string `json:"token"`
+}
+
+type email struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type userReturnEmail struct {
+	ID           uuid.UUID `json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	Email        string    `json:"email"`
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	IsChirpyRed  bool      `json:"is_chirpy_red"`
+	// user, moderator or admin
+	Role string `json:"role"`
+	// what others mention the user by, left out until the user picks one
+	Handle string `json:"handle,omitempty"`
+}
+
+type polkaRequest struct {
+	Event string `json:"event"`
+	Data  struct {
+		UserID string `json:"user_id"`
+	} `json:"Data"`
+}
+
+type chirpPostReq struct {
+	Body string `json:"body"`
+	// UserID uuid.UUID `json:"user_id"`
+}
+
+type validChirp struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Body      string    `json:"body"`
+	UserID    uuid.UUID `json:"user_id"`
+	// hidden by moderators or reports, only its author sees it
+	Hidden bool `json:"hidden,omitempty"`
+	// the viewer's content filters that match the chirp, clients can hide it behind a warning
+	Filtered []filterResult `json:"filtered,omitempty"`
+}
+
+// the data of chirp.hidden and chirp.deleted events, what the chirp said is not sent
+type removedChirp struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
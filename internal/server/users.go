@@ -0,0 +1,160 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/christianrm0821/Chirpy/internal/auth"
+	"github.com/christianrm0821/Chirpy/internal/notifications"
+	"github.com/christianrm0821/Chirpy/internal/store"
+	"github.com/christianrm0821/Chirpy/internal/tracing"
+)
+
+// creates a new user with the email and password in the request body
+func (s *Server) handlerCreateUser(w http.ResponseWriter, r *http.Request) {
+	request := email{}
+	err := bindJSON(r, &request)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+
+	//hash password
+	_, hashSpan := tracing.Tracer().Start(r.Context(), "bcrypt.HashPassword")
+	hashed_password, err := auth.HashPassword(request.Password)
+	hashSpan.End()
+	if err != nil {
+		s.respondWithError(w, r, errInternal(fmt.Errorf("error hashing password: %w", err)))
+		return
+	}
+
+	user, err := s.store.CreateUser(r.Context(), request.Email, hashed_password)
+	if errors.Is(err, store.ErrConflict) {
+		s.respondWithError(w, r, errEmailTaken(err))
+		return
+	}
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("error creating user: %w", err))
+		return
+	}
+	respondWithData(w, r, 201, userReturnEmail{
+		ID:          user.ID,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+		Email:       user.Email,
+		IsChirpyRed: user.IsChirpyRed,
+		Role:        user.Role,
+	})
+}
+
+// changes the email and password of the user the access token belongs to
+func (s *Server) handlerUpdateUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticateActive(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+
+	//decode request
+	request := email{}
+	err = bindJSON(r, &request)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+
+	_, hashSpan := tracing.Tracer().Start(r.Context(), "bcrypt.HashPassword")
+	myHashedPassword, err := auth.HashPassword(request.Password)
+	hashSpan.End()
+	if err != nil {
+		s.respondWithError(w, r, errInternal(fmt.Errorf("error hashing password: %w", err)))
+		return
+	}
+	_, checkSpan := tracing.Tracer().Start(r.Context(), "bcrypt.CheckPasswordHash")
+	err = auth.CheckPasswordHash(myHashedPassword, request.Password)
+	checkSpan.End()
+	if err != nil {
+		s.respondWithError(w, r, errInternal(fmt.Errorf("hashed password and password do not match: %w", err)))
+		return
+	}
+
+	err = s.store.UpdateUserCredentials(r.Context(), userID, request.Email, myHashedPassword)
+	if errors.Is(err, store.ErrConflict) {
+		s.respondWithError(w, r, errEmailTaken(err))
+		return
+	}
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("error updating password: %w", err))
+		return
+	}
+
+	userInfo, err := s.store.GetUserByID(r.Context(), userID)
+	if err != nil {
+		s.respondWithError(w, r, errUnauthorized(err))
+		return
+	}
+
+	respondWithData(w, r, 200, userReturnEmail{
+		ID:        userID,
+		CreatedAt: userInfo.CreatedAt,
+		UpdatedAt: userInfo.UpdatedAt,
+		Email:     userInfo.Email,
+		Role:      userInfo.Role,
+		Handle:    userInfo.Handle,
+	})
+}
+
+type handleReq struct {
+	Handle string `json:"handle"`
+}
+
+func (req handleReq) validate() []fieldError {
+	if req.Handle != "" && !notifications.IsHandle(req.Handle) {
+		return []fieldError{{Field: "handle", Message: "must be 1 to 15 lowercase letters, digits or underscores"}}
+	}
+	return nil
+}
+
+// sets the handle others mention the user by, an empty one removes it
+func (s *Server) handlerSetHandle(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.authenticateActive(r)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	request := handleReq{}
+	err = bindJSON(r, &request)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	err = s.store.SetUserHandle(r.Context(), userID, request.Handle)
+	if errors.Is(err, store.ErrConflict) {
+		s.respondWithError(w, r, &apiError{Status: http.StatusConflict, Code: codeConflict, Message: "handle is already in use", Err: err})
+		return
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		s.respondWithError(w, r, errUnauthorized(err))
+		return
+	}
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not set handle: %w", err))
+		return
+	}
+
+	user, err := s.store.GetUserByID(r.Context(), userID)
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not get user: %w", err))
+		return
+	}
+	respondWithData(w, r, 200, userReturnEmail{
+		ID:          user.ID,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+		Email:       user.Email,
+		IsChirpyRed: user.IsChirpyRed,
+		Role:        user.Role,
+		Handle:      user.Handle,
+	})
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/christianrm0821/Chirpy/internal/apispec"
+)
+
+// checks every request against openapi.json before it reaches the handlers
+// when validateResponses is set the responses are checked too and any drift is logged
+func WithSpecValidation(validateResponses bool) Option {
+	return func(s *Server) {
+		spec, err := apispec.Load(openAPISpec)
+		if err != nil {
+			//openapi.json is embedded and loaded by the tests so this can only be a programming error
+			panic("could not load openapi.json: " + err.Error())
+		}
+		s.spec = spec
+		s.validateResponses = validateResponses
+	}
+}
+
+func toFieldErrors(details []apispec.FieldError) []fieldError {
+	out := make([]fieldError, len(details))
+	for i, d := range details {
+		out[i] = fieldError{Field: d.Field, Message: d.Message}
+	}
+	return out
+}
+
+// keeps a copy of the response so it can be checked once the handler is done
+// event streams and websockets never finish so they are not kept
+type responseCapture struct {
+	http.ResponseWriter
+	status    int
+	body      bytes.Buffer
+	streaming bool
+}
+
+func (rec *responseCapture) WriteHeader(code int) {
+	rec.status = code
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *responseCapture) Write(b []byte) (int, error) {
+	n, err := rec.ResponseWriter.Write(b)
+	if strings.HasPrefix(rec.Header().Get("Content-Type"), "text/event-stream") {
+		rec.streaming = true
+	}
+	if !rec.streaming {
+		rec.body.Write(b[:n])
+	}
+	return n, err
+}
+
+func (rec *responseCapture) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// websockets take over the connection, there is no response to check
+func (rec *responseCapture) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := http.NewResponseController(rec.ResponseWriter).Hijack()
+	if err == nil {
+		rec.streaming = true
+	}
+	return conn, brw, err
+}
+
+// middleware that rejects requests that don't match openapi.json
+// requests the document does not describe are left for the mux to 404 or 405
+func (s *Server) middlewareSpecValidation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		op, pathValues := s.spec.Find(r.Method, r.URL.Path)
+		if op == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if details := op.ValidateParams(r, pathValues); len(details) > 0 {
+			s.respondWithError(w, r, &apiError{
+				Status:  http.StatusBadRequest,
+				Code:    codeInvalidParam,
+				Message: "invalid " + details[0].Field,
+				Details: toFieldErrors(details),
+			})
+			return
+		}
+
+		if op.Secured && r.Header.Get("Authorization") == "" {
+			s.respondWithError(w, r, errUnauthorized(errors.New("missing Authorization header")))
+			return
+		}
+
+		//read the body so it can be checked and then hand the handler a copy
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			if apiErr := errBodyTooLarge(err); apiErr != nil {
+				s.respondWithError(w, r, apiErr)
+				return
+			}
+			s.respondWithError(w, r, &apiError{Status: http.StatusBadRequest, Code: codeInvalidJSON, Message: "could not read request body", Err: err})
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		details, err := op.ValidateBody(body)
+		if err != nil {
+			s.respondWithError(w, r, &apiError{Status: http.StatusBadRequest, Code: codeInvalidJSON, Message: "request body is not valid json", Err: err})
+			return
+		}
+		if len(details) > 0 {
+			//wrong types and unknown fields are a 400 like in bindJSON, everything else is a 422
+			apiErr := errValidation(toFieldErrors(details)...)
+			for _, d := range details {
+				if d.Keyword == "type" || d.Keyword == "additionalProperties" {
+					apiErr.Status = http.StatusBadRequest
+					apiErr.Code = codeInvalidJSON
+					apiErr.Message = "request body does not match the schema"
+				}
+			}
+			s.respondWithError(w, r, apiErr)
+			return
+		}
+
+		if !s.validateResponses {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &responseCapture{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		if rec.streaming {
+			return
+		}
+		drift := op.ValidateResponse(rec.status, w.Header().Get("Content-Type"), rec.body.Bytes())
+		if len(drift) > 0 {
+			problems := make([]string, len(drift))
+			for i, d := range drift {
+				problems[i] = d.Message
+				if d.Field != "" {
+					problems[i] = d.Field + ": " + d.Message
+				}
+			}
+			s.requestLog(r).Warn("response does not match openapi.json",
+				"operation", op.ID,
+				"status", rec.status,
+				"problems", problems,
+			)
+		}
+	})
+}
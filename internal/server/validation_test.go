@@ -0,0 +1,90 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSpecValidationRejectsRequests(t *testing.T) {
+	c := newTestClient(t, Config{}, WithSpecValidation(false))
+	alice := c.login("alice@example.com", "hunter2")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		body   string
+		status int
+		code   string
+	}{
+		{"bad sort", "GET", "/api/chirps?sort=up", "", "", 400, codeInvalidParam},
+		{"bad chirp id", "GET", "/api/chirps/42", "", "", 400, codeInvalidParam},
+		{"missing token", "POST", "/api/chirps", "", `{"body":"hi"}`, 401, codeUnauthorized},
+		{"not json", "POST", "/api/chirps", alice.Token, `{"body":`, 400, codeInvalidJSON},
+		{"wrong type", "POST", "/api/chirps", alice.Token, `{"body":5}`, 400, codeInvalidJSON},
+		{"chirp too long", "POST", "/api/chirps", alice.Token, `{"body":"` + strings.Repeat("a", 141) + `"}`, 422, codeValidation},
+		{"bad webhook user", "POST", "/api/polka/webhooks", "", `{"event":"user.upgraded","data":{"user_id":"x"}}`, 401, codeUnauthorized},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+		rec := httptest.NewRecorder()
+		c.handler.ServeHTTP(rec, req)
+
+		body := resErr{}
+		json.Unmarshal(rec.Body.Bytes(), &body)
+		if rec.Code != tc.status || body.Code != tc.code {
+			t.Errorf("%s: was expecting %d %s but got %d %s", tc.name, tc.status, tc.code, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+// runs the normal flows with response validation on, any drift from openapi.json is logged as a warning
+func TestResponsesMatchSpec(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	c := newTestClient(t, Config{Platform: "dev", PolkaKey: "polka-key", StaticDir: "."}, WithLogger(logger), WithSpecValidation(true))
+
+	alice := c.login("alice@example.com", "hunter2")
+	chirp := validChirp{}
+	c.do("POST", "/api/chirps", alice.Token, chirpPostReq{Body: "hello"}, &chirp)
+	c.do("GET", "/api/chirps", "", nil, nil)
+	c.do("GET", "/api/chirps/"+chirp.ID.String(), "", nil, nil)
+	c.do("PUT", "/api/users", alice.Token, email{Email: "alice@new.example.com", Password: "hunter3"}, nil)
+	c.do("POST", "/api/users", "", email{Email: "alice@new.example.com", Password: "x"}, nil)
+	c.do("POST", "/api/refresh", alice.RefreshToken, nil, nil)
+	c.do("POST", "/api/revoke", alice.RefreshToken, nil, nil)
+	c.do("DELETE", "/api/chirps/"+chirp.ID.String(), alice.Token, nil, nil)
+	c.do("GET", "/api/chirps/"+chirp.ID.String(), "", nil, nil)
+	c.do("POST", "/api/v2/chirps", alice.Token, chirpPostReq{Body: "hello v2"}, &chirp)
+	c.do("GET", "/api/v2/chirps?limit=1", "", nil, nil)
+	c.do("POST", "/api/v2/login", "", email{Email: "alice@new.example.com", Password: "hunter3"}, nil)
+	c.do("POST", "/api/v2/chirps", "", chirpPostReq{Body: "no token"}, nil)
+	c.do("GET", "/api/healthz", "", nil, nil)
+	c.do("GET", "/api/openapi.json", "", nil, nil)
+	c.do("GET", "/admin/metrics", "", nil, nil)
+	c.do("POST", "/admin/reset", "", nil, nil)
+
+	req := httptest.NewRequest("GET", "/app/docs/", nil)
+	c.handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest("GET", "/api/chirps/"+chirp.ID.String(), nil)
+	req.Header.Set("Accept", problemContentType)
+	rec := httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("was expecting 404 but got %d", rec.Code)
+	}
+
+	if logs.Len() > 0 {
+		t.Errorf("responses drifted from openapi.json:\n%s", logs.String())
+	}
+}
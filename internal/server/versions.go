@@ -0,0 +1,160 @@
+package server
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	apiV1 = 1
+	apiV2 = 2
+)
+
+// when the unversioned /api/* aliases were deprecated and when they go away
+var (
+	legacyDeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	legacySunsetAt     = time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC)
+)
+
+// registers the routes of one api version under prefix
+// v1 and the unversioned aliases share the handlers, v2 changes the response shapes
+func (s *Server) versionRoutes(mux *router, prefix string, version int, deprecated bool) {
+	handle := func(method, path string, handler http.HandlerFunc) {
+		h := middlewareAPIVersion(version, handler)
+		if deprecated {
+			h = middlewareDeprecated(h)
+		}
+		mux.Handle(method+" "+prefix+path, h)
+	}
+
+	handle("POST", "/users", s.handlerCreateUser)
+	handle("PUT", "/users", s.handlerUpdateUser)
+	handle("PUT", "/users/handle", s.handlerSetHandle)
+
+	handle("POST", "/login", s.handlerLogin)
+	handle("POST", "/refresh", s.handlerRefresh)
+	handle("POST", "/revoke", s.handlerRevoke)
+
+	handle("POST", "/chirps", s.handlerCreateChirp)
+	handle("GET", "/chirps", s.handlerListChirps)
+	handle("GET", "/chirps/{chirpID}", s.handlerGetChirp)
+	handle("DELETE", "/chirps/{chirpID}", s.handlerDeleteChirp)
+	handle("POST", "/chirps/{chirpID}/reports", s.handlerCreateReport)
+
+	handle("GET", "/notifications", s.handlerListNotifications)
+	handle("POST", "/notifications/{notificationID}/read", s.handlerMarkNotificationRead)
+	handle("POST", "/notifications/read-all", s.handlerMarkAllNotificationsRead)
+	handle("GET", "/notifications/preferences", s.handlerGetNotificationPreferences)
+	handle("PUT", "/notifications/preferences", s.handlerUpdateNotificationPreferences)
+
+	handle("POST", "/conversations", s.handlerCreateConversation)
+	handle("GET", "/conversations", s.handlerListConversations)
+	handle("GET", "/conversations/settings", s.handlerGetDMSettings)
+	handle("PUT", "/conversations/settings", s.handlerUpdateDMSettings)
+	handle("GET", "/conversations/{conversationID}", s.handlerGetConversation)
+	handle("POST", "/conversations/{conversationID}/messages", s.handlerCreateMessage)
+	handle("GET", "/conversations/{conversationID}/messages", s.handlerListMessages)
+	handle("POST", "/conversations/{conversationID}/read", s.handlerMarkConversationRead)
+
+	handle("POST", "/blocks", s.handlerBlockUser)
+	handle("GET", "/blocks", s.handlerListBlocks)
+	handle("DELETE", "/blocks/{userID}", s.handlerUnblockUser)
+	handle("POST", "/mutes", s.handlerMuteUser)
+	handle("GET", "/mutes", s.handlerListMutes)
+	handle("DELETE", "/mutes/{userID}", s.handlerUnmuteUser)
+
+	handle("POST", "/filters", s.handlerCreateContentFilter)
+	handle("GET", "/filters", s.handlerListContentFilters)
+	handle("DELETE", "/filters/{filterID}", s.handlerDeleteContentFilter)
+
+	//polka is configured with a single url, it isn't part of the versioned api
+	if version == apiV1 {
+		handle("POST", "/polka/webhooks", s.handlerPolkaWebhook)
+	}
+}
+
+// records which api version the request is for so handlers can pick the response shape
+func middlewareAPIVersion(version int, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		getRequestInfo(r.Context()).APIVersion = version
+		next.ServeHTTP(w, r)
+	})
+}
+
+// tells clients of an unversioned /api/* route where to go and when it stops working
+// Deprecation is RFC 9745, Sunset is RFC 8594
+func middlewareDeprecated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		successor := "/api/v1" + strings.TrimPrefix(r.URL.Path, "/api")
+		w.Header().Set("Deprecation", fmt.Sprintf("@%d", legacyDeprecatedAt.Unix()))
+		w.Header().Set("Sunset", legacySunsetAt.Format(http.TimeFormat))
+		w.Header().Add("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+		next.ServeHTTP(w, r)
+	})
+}
+
+func apiVersion(r *http.Request) int {
+	version := getRequestInfo(r.Context()).APIVersion
+	if version == 0 {
+		return apiV1
+	}
+	return version
+}
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// v2 wraps every response in {"data": ...}
+type envelope struct {
+	Data       any         `json:"data"`
+	Pagination *pagination `json:"pagination,omitempty"`
+	// extra information about the whole list, like the unread count of notifications
+	Meta any `json:"meta,omitempty"`
+}
+
+type pagination struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// writes payload the way the api version of the request expects it
+func respondWithData(w http.ResponseWriter, r *http.Request, code int, payload any) {
+	if apiVersion(r) >= apiV2 {
+		respondWithJson(w, code, envelope{Data: payload})
+		return
+	}
+	respondWithJson(w, code, payload)
+}
+
+// cursors are opaque to clients, they hold the sort time and id of the last item on the page
+func encodeCursor(at time.Time, id uuid.UUID) string {
+	raw := at.UTC().Format(time.RFC3339Nano) + "|" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(value string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	rawTime, rawID, found := strings.Cut(string(raw), "|")
+	if !found {
+		return time.Time{}, uuid.Nil, fmt.Errorf("cursor is missing the id")
+	}
+	at, err := time.Parse(time.RFC3339Nano, rawTime)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	return at, id, nil
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/christianrm0821/Chirpy/internal/auth"
+	"github.com/christianrm0821/Chirpy/internal/store"
+	"github.com/christianrm0821/Chirpy/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// upgrades a user to chirpy red when polka tells us they paid
+func (s *Server) handlerPolkaWebhook(w http.ResponseWriter, r *http.Request) {
+	requestAPIKey, err := auth.GetAPIKey(r.Header)
+	if err != nil {
+		s.respondWithError(w, r, errUnauthorized(err))
+		return
+	}
+	if requestAPIKey != s.cfg.PolkaKey {
+		s.respondWithError(w, r, errUnauthorized(errors.New("wrong api key")))
+		return
+	}
+
+	ctx, span := tracing.Tracer().Start(r.Context(), "polka.ProcessWebhook")
+	defer span.End()
+	r = r.WithContext(ctx)
+
+	request := polkaRequest{}
+	err = decodeJSON(r, &request)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	span.SetAttributes(attribute.String("polka.event", request.Event))
+	if request.Event != "user.upgraded" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	userID, err := parseUUID("data.user_id", request.Data.UserID)
+	if err != nil {
+		s.respondWithError(w, r, err)
+		return
+	}
+	user, err := s.store.GetUserByID(r.Context(), userID)
+	if errors.Is(err, store.ErrNotFound) {
+		s.respondWithError(w, r, errNotFound("user", err))
+		return
+	}
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("user cannot be found: %w", err))
+		return
+	}
+
+	err = s.store.UpgradeUserToChirpyRed(r.Context(), user.ID)
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("error updating subscription: %w", err))
+		return
+	}
+	//the user is already upgraded, polka would retry the webhook if this failed the request
+	err = s.notifications.UserUpgraded(r.Context(), user.ID)
+	if err != nil {
+		s.requestLog(r).Error("could not notify upgraded user", "user_id", user.ID, "error", err)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,337 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/christianrm0821/Chirpy/internal/auth"
+	"github.com/christianrm0821/Chirpy/internal/events"
+	"github.com/christianrm0821/Chirpy/internal/filters"
+	"github.com/christianrm0821/Chirpy/internal/notifications"
+	"github.com/christianrm0821/Chirpy/internal/store"
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/google/uuid"
+)
+
+const (
+	// how often the server pings and how long the client has to answer
+	wsPingInterval = 30 * time.Second
+	wsPingTimeout  = 10 * time.Second
+	// a client that can't take a message in this long is disconnected
+	wsWriteTimeout = 10 * time.Second
+	// client messages are small, anything bigger is a mistake or abuse
+	wsReadLimit = 4096
+	// channels one connection can subscribe to
+	wsMaxChannels = 50
+)
+
+// close codes in the range reserved for applications
+const (
+	wsCloseTokenExpired websocket.StatusCode = 4001
+	wsCloseTooSlow      websocket.StatusCode = 4008
+)
+
+// what the client sends
+// subscribe and unsubscribe take a channel, auth takes a fresh access token
+type wsClientMessage struct {
+	Type    string `json:"type"`
+	Channel string `json:"channel,omitempty"`
+	Token   string `json:"token,omitempty"`
+}
+
+// what the server sends
+type wsServerMessage struct {
+	Type      string     `json:"type"`
+	Channel   string     `json:"channel,omitempty"`
+	Event     string     `json:"event,omitempty"`
+	ID        uint64     `json:"id,omitempty"`
+	Data      any        `json:"data,omitempty"`
+	Code      string     `json:"code,omitempty"`
+	Message   string     `json:"message,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// a channel a connection can subscribe to
+//
+//	home         every chirp, until there are follows this is the same as the public timeline
+//	mentions     chirps that mention the user's handle, nothing until they have one
+//	chirp:<id>   changes to one chirp
+type wsChannel struct {
+	name    string
+	chirpID uuid.UUID
+}
+
+func parseChannel(name string) (wsChannel, error) {
+	switch {
+	case name == "home" || name == "mentions":
+		return wsChannel{name: name}, nil
+	case strings.HasPrefix(name, "chirp:"):
+		chirpID, err := uuid.Parse(strings.TrimPrefix(name, "chirp:"))
+		if err != nil {
+			return wsChannel{}, fmt.Errorf("%q does not have a valid chirp id", name)
+		}
+		return wsChannel{name: name, chirpID: chirpID}, nil
+	}
+	return wsChannel{}, fmt.Errorf("unknown channel %q", name)
+}
+
+func (c wsChannel) match(event events.Event, user store.User) bool {
+	switch c.name {
+	case "home":
+		return true
+	case "mentions":
+		return event.Chirp.UserID != user.ID && user.Handle != "" &&
+			slices.Contains(notifications.Mentions(event.Chirp.Body), user.Handle)
+	}
+	return event.Chirp.ID == c.chirpID
+}
+
+// the access token of a websocket request
+// browsers can't set headers on a websocket so ?access_token= works too
+func wsToken(r *http.Request) (string, error) {
+	if token := r.URL.Query().Get("access_token"); token != "" {
+		return token, nil
+	}
+	return auth.GetBearerToken(r.Header)
+}
+
+// bidirectional real-time api, clients subscribe to channels and get chirp events on them
+// the connection is closed when the access token expires unless the client sends a new one
+func (s *Server) handlerWebSocket(w http.ResponseWriter, r *http.Request) {
+	token, err := wsToken(r)
+	if err != nil {
+		s.respondWithError(w, r, errUnauthorized(err))
+		return
+	}
+	userID, expiresAt, err := auth.ValidateJWTExpiry(token, s.cfg.Secret)
+	if err != nil {
+		s.respondWithError(w, r, errUnauthorized(err))
+		return
+	}
+	setRequestUser(r, userID)
+	user, err := s.store.GetUserByID(r.Context(), userID)
+	if errors.Is(err, store.ErrNotFound) {
+		s.respondWithError(w, r, errUnauthorized(err))
+		return
+	}
+	if err != nil {
+		s.respondWithError(w, r, fmt.Errorf("could not get user: %w", err))
+		return
+	}
+
+	//the connection outlives READ_TIMEOUT and WRITE_TIMEOUT, its own timeouts are below
+	rc := http.NewResponseController(w)
+	rc.SetReadDeadline(time.Time{})
+	rc.SetWriteDeadline(time.Time{})
+
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		//Accept has already written the error response
+		s.requestLog(r).Debug("websocket handshake failed", "error", err)
+		return
+	}
+	conn.SetReadLimit(wsReadLimit)
+
+	ws := &wsSession{
+		s:         s,
+		r:         r,
+		conn:      conn,
+		user:      user,
+		expiresAt: expiresAt,
+		channels:  map[string]wsChannel{},
+	}
+	status, reason := ws.run(r.Context())
+	conn.Close(status, reason)
+}
+
+// one open websocket connection
+type wsSession struct {
+	s         *Server
+	r         *http.Request
+	conn      *websocket.Conn
+	user      store.User
+	expiresAt time.Time
+	channels  map[string]wsChannel
+	// authors the user blocked, was blocked by or muted, their chirps aren't sent
+	hidden map[uuid.UUID]bool
+	// the user's timeline filters, chirps that match are sent marked as filtered
+	filters *filters.Set
+}
+
+// serves the connection until one side is done, returns how the connection should be closed
+func (ws *wsSession) run(parent context.Context) (websocket.StatusCode, string) {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	sub, _, _ := ws.s.events.Subscribe(events.Filter{}, 0)
+	defer sub.Close()
+	ws.loadViewerSettings(ctx)
+
+	//reads happen on their own goroutine so the loop below can write while waiting
+	//cancelling a read closes the connection without a status, so it only stops once the connection is closed
+	incoming := make(chan wsClientMessage)
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			msg := wsClientMessage{}
+			err := wsjson.Read(parent, ws.conn, &msg)
+			if err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case incoming <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	//pings wait for the pong, they run on their own so events keep flowing
+	pingFailed := make(chan error, 1)
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	expiry := time.NewTimer(time.Until(ws.expiresAt))
+	defer expiry.Stop()
+
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return websocket.StatusGoingAway, "server is shutting down"
+		case err := <-readErr:
+			if websocket.CloseStatus(err) != -1 {
+				return websocket.StatusNormalClosure, ""
+			}
+			ws.s.requestLog(ws.r).Debug("websocket read failed", "error", err)
+			return websocket.StatusUnsupportedData, "messages must be json"
+		case err := <-pingFailed:
+			ws.s.requestLog(ws.r).Debug("websocket ping failed", "error", err)
+			return websocket.StatusPolicyViolation, "ping timed out"
+		case <-ping.C:
+			//blocks and mutes change, and mutes expire
+			ws.loadViewerSettings(ctx)
+			go func() {
+				pingCtx, cancel := context.WithTimeout(ctx, wsPingTimeout)
+				defer cancel()
+				if err := ws.conn.Ping(pingCtx); err != nil {
+					select {
+					case pingFailed <- err:
+					case <-ctx.Done():
+					}
+				}
+			}()
+		case <-expiry.C:
+			ws.write(ctx, wsServerMessage{Type: "error", Code: "token_expired", Message: "the access token expired"})
+			return wsCloseTokenExpired, "token expired"
+		case event, ok := <-sub.C:
+			if !ok {
+				if ctx.Err() == nil && !ws.s.events.Closed() {
+					return wsCloseTooSlow, "too slow to keep up with events"
+				}
+				return websocket.StatusGoingAway, "server is shutting down"
+			}
+			err = ws.deliver(ctx, event)
+		case msg := <-incoming:
+			err = ws.handle(ctx, msg, expiry)
+		}
+		if err != nil {
+			ws.s.requestLog(ws.r).Debug("websocket write failed", "error", err)
+			return wsCloseTooSlow, "too slow to keep up with events"
+		}
+	}
+}
+
+func (ws *wsSession) write(ctx context.Context, msg wsServerMessage) error {
+	ctx, cancel := context.WithTimeout(ctx, wsWriteTimeout)
+	defer cancel()
+	return wsjson.Write(ctx, ws.conn, msg)
+}
+
+// loads the hidden authors and content filters of the user
+// keeps the last ones when the store fails, the next ping tries again
+func (ws *wsSession) loadViewerSettings(ctx context.Context) {
+	ids, err := ws.s.store.HiddenUserIDs(ctx, ws.user.ID, time.Now())
+	if err != nil {
+		ws.s.requestLog(ws.r).Error("could not get hidden users", "error", err)
+	} else {
+		ws.hidden = map[uuid.UUID]bool{}
+		for _, id := range ids {
+			ws.hidden[id] = true
+		}
+	}
+	set, err := ws.s.contentFilters(ctx, ws.user.ID, filters.Timeline)
+	if err != nil {
+		ws.s.requestLog(ws.r).Error("could not get content filters", "error", err)
+	} else {
+		ws.filters = set
+	}
+}
+
+// sends the event once on every subscribed channel it belongs to
+func (ws *wsSession) deliver(ctx context.Context, event events.Event) error {
+	if ws.hidden[event.Chirp.UserID] {
+		return nil
+	}
+	var chirp any = mapFilteredChirp(event.Chirp, ws.user.ID, ws.filters)
+	if event.Removed() {
+		chirp = removedChirp{ID: event.Chirp.ID, UserID: event.Chirp.UserID}
+	}
+	for name, channel := range ws.channels {
+		if !channel.match(event, ws.user) {
+			continue
+		}
+		err := ws.write(ctx, wsServerMessage{
+			Type:    "event",
+			Channel: name,
+			Event:   event.Type,
+			ID:      event.ID,
+			Data:    chirp,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ws *wsSession) handle(ctx context.Context, msg wsClientMessage, expiry *time.Timer) error {
+	fail := func(code, message string) error {
+		return ws.write(ctx, wsServerMessage{Type: "error", Channel: msg.Channel, Code: code, Message: message})
+	}
+
+	switch msg.Type {
+	case "ping":
+		return ws.write(ctx, wsServerMessage{Type: "pong"})
+	case "subscribe":
+		channel, err := parseChannel(msg.Channel)
+		if err != nil {
+			return fail("invalid_channel", err.Error())
+		}
+		if _, ok := ws.channels[msg.Channel]; !ok && len(ws.channels) >= wsMaxChannels {
+			return fail("too_many_channels", fmt.Sprintf("at most %d channels per connection", wsMaxChannels))
+		}
+		ws.channels[msg.Channel] = channel
+		return ws.write(ctx, wsServerMessage{Type: "subscribed", Channel: msg.Channel})
+	case "unsubscribe":
+		delete(ws.channels, msg.Channel)
+		return ws.write(ctx, wsServerMessage{Type: "unsubscribed", Channel: msg.Channel})
+	case "auth":
+		//a fresh access token keeps the connection open past the old one's expiry
+		userID, expiresAt, err := auth.ValidateJWTExpiry(msg.Token, ws.s.cfg.Secret)
+		if err != nil || userID != ws.user.ID {
+			return fail(codeUnauthorized, "token is invalid or for another user")
+		}
+		ws.expiresAt = expiresAt
+		expiry.Reset(time.Until(expiresAt))
+		return ws.write(ctx, wsServerMessage{Type: "authenticated", ExpiresAt: &expiresAt})
+	}
+	return fail("invalid_message", fmt.Sprintf("unknown message type %q", msg.Type))
+}
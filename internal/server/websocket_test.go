@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christianrm0821/Chirpy/internal/auth"
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+)
+
+func TestWebSocket(t *testing.T) {
+	c := newTestClient(t, Config{})
+	ts := httptest.NewServer(c.handler)
+	defer ts.Close()
+	alice := c.login("alice@example.com", "hunter2")
+	bob := c.login("bob@example.com", "password")
+	c.do("PUT", "/api/v1/users/handle", alice.Token, handleReq{Handle: "alice"}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/api/ws"
+
+	_, res, err := websocket.Dial(ctx, wsURL, nil)
+	if err == nil || res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("was expecting 401 without a token but got %v", err)
+	}
+
+	conn, _, err := websocket.Dial(ctx, wsURL+"?access_token="+alice.Token, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.CloseNow()
+	send := func(msg wsClientMessage) {
+		t.Helper()
+		if err := wsjson.Write(ctx, conn, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	receive := func() wsServerMessage {
+		t.Helper()
+		msg := wsServerMessage{}
+		if err := wsjson.Read(ctx, conn, &msg); err != nil {
+			t.Fatal(err)
+		}
+		return msg
+	}
+
+	send(wsClientMessage{Type: "subscribe", Channel: "nope"})
+	if msg := receive(); msg.Type != "error" || msg.Code != "invalid_channel" {
+		t.Errorf("was expecting an invalid_channel error but got %+v", msg)
+	}
+	send(wsClientMessage{Type: "subscribe", Channel: "mentions"})
+	if msg := receive(); msg.Type != "subscribed" || msg.Channel != "mentions" {
+		t.Errorf("was expecting to be subscribed to mentions but got %+v", msg)
+	}
+
+	c.do("POST", "/api/chirps", bob.Token, chirpPostReq{Body: "nothing for alice"}, nil)
+	chirp := validChirp{}
+	c.do("POST", "/api/chirps", bob.Token, chirpPostReq{Body: "hey @alice"}, &chirp)
+	msg := receive()
+	if msg.Type != "event" || msg.Event != "chirp.created" || msg.Channel != "mentions" {
+		t.Fatalf("was expecting the mention but got %+v", msg)
+	}
+	if data, _ := msg.Data.(map[string]any); data["id"] != chirp.ID.String() {
+		t.Errorf("was expecting chirp %s but got %v", chirp.ID, msg.Data)
+	}
+
+	send(wsClientMessage{Type: "auth", Token: bob.Token})
+	if msg := receive(); msg.Code != codeUnauthorized {
+		t.Errorf("was expecting another user's token to be rejected but got %+v", msg)
+	}
+	send(wsClientMessage{Type: "ping"})
+	if msg := receive(); msg.Type != "pong" {
+		t.Errorf("was expecting a pong but got %+v", msg)
+	}
+	conn.Close(websocket.StatusNormalClosure, "")
+
+	//the connection is closed when the token runs out
+	//jwt expiry is in whole seconds so this lasts between 0.5s and 1.5s
+	shortToken, _ := auth.MakeJWT(alice.ID, testSecret, 1500*time.Millisecond)
+	conn, _, err = websocket.Dial(ctx, wsURL, &websocket.DialOptions{HTTPHeader: http.Header{"Authorization": {"Bearer " + shortToken}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.CloseNow()
+	if msg := receive(); msg.Code != "token_expired" {
+		t.Errorf("was expecting token_expired but got %+v", msg)
+	}
+	_, _, err = conn.Read(ctx)
+	if websocket.CloseStatus(err) != wsCloseTokenExpired {
+		t.Errorf("was expecting close status %d but got %v", wsCloseTokenExpired, err)
+	}
+}
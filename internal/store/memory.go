@@ -0,0 +1,1024 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// keeps everything in maps, used for tests and running the api without a database
+// it follows the same rules as the postgres schema (unique emails, chirps and
+// tokens are removed with their user)
+type memoryStore struct {
+	mu            sync.RWMutex
+	users         map[uuid.UUID]User
+	chirps        map[uuid.UUID]Chirp
+	refreshTokens map[string]RefreshToken
+	notifications map[uuid.UUID]Notification
+	// user id -> notification type -> enabled
+	notificationPrefs map[uuid.UUID]map[string]bool
+	conversations     map[uuid.UUID]Conversation
+	messages          map[uuid.UUID]Message
+	dmPrivacy         map[uuid.UUID]string
+	blocks            map[userPair]Block
+	mutes             map[userPair]Mute
+	contentFilters    map[uuid.UUID]ContentFilter
+	profanityTerms    map[uuid.UUID]ProfanityTerm
+	moderationCases   map[uuid.UUID]ModerationCase
+	reports           map[uuid.UUID]Report
+}
+
+// who did it and to whom, the key of blocks and mutes
+type userPair struct {
+	from, to uuid.UUID
+}
+
+// the terms the migrations start with
+var defaultProfanityTerms = []string{"kerfuffle", "sharbert", "fornax"}
+
+// returns an empty in-memory Store that is safe to use from many goroutines
+// like a migrated database it starts with the default profanity terms
+func NewMemory() Store {
+	s := &memoryStore{
+		users:             map[uuid.UUID]User{},
+		chirps:            map[uuid.UUID]Chirp{},
+		refreshTokens:     map[string]RefreshToken{},
+		notifications:     map[uuid.UUID]Notification{},
+		notificationPrefs: map[uuid.UUID]map[string]bool{},
+		conversations:     map[uuid.UUID]Conversation{},
+		messages:          map[uuid.UUID]Message{},
+		dmPrivacy:         map[uuid.UUID]string{},
+		blocks:            map[userPair]Block{},
+		mutes:             map[userPair]Mute{},
+		contentFilters:    map[uuid.UUID]ContentFilter{},
+		profanityTerms:    map[uuid.UUID]ProfanityTerm{},
+		moderationCases:   map[uuid.UUID]ModerationCase{},
+		reports:           map[uuid.UUID]Report{},
+	}
+	now := time.Now().UTC()
+	for _, term := range defaultProfanityTerms {
+		id := uuid.New()
+		s.profanityTerms[id] = ProfanityTerm{ID: id, CreatedAt: now, UpdatedAt: now, Term: term, Action: "mask"}
+	}
+	return s
+}
+
+func (s *memoryStore) CreateUser(ctx context.Context, email, hashedPassword string) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, user := range s.users {
+		if user.Email == email {
+			return User{}, fmt.Errorf("%w: user with email %q", ErrConflict, email)
+		}
+	}
+	now := time.Now().UTC()
+	user := User{
+		ID:             uuid.New(),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		Email:          email,
+		HashedPassword: hashedPassword,
+		Role:           RoleUser,
+	}
+	s.users[user.ID] = user
+	return user, nil
+}
+
+func (s *memoryStore) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, user := range s.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return User{}, ErrNotFound
+}
+
+func (s *memoryStore) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	user, ok := s.users[id]
+	if !ok {
+		return User{}, ErrNotFound
+	}
+	return user, nil
+}
+
+func (s *memoryStore) GetUserByHandle(ctx context.Context, handle string) (User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, user := range s.users {
+		if handle != "" && user.Handle == handle {
+			return user, nil
+		}
+	}
+	return User{}, ErrNotFound
+}
+
+func (s *memoryStore) UpdateUserCredentials(ctx context.Context, id uuid.UUID, email, hashedPassword string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.users[id]
+	if !ok {
+		return nil
+	}
+	for _, other := range s.users {
+		if other.Email == email && other.ID != id {
+			return fmt.Errorf("%w: user with email %q", ErrConflict, email)
+		}
+	}
+	user.Email = email
+	user.HashedPassword = hashedPassword
+	s.users[id] = user
+	return nil
+}
+
+func (s *memoryStore) UpgradeUserToChirpyRed(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.users[id]
+	if !ok {
+		return nil
+	}
+	user.IsChirpyRed = true
+	s.users[id] = user
+	return nil
+}
+
+func (s *memoryStore) SetUserSuspended(ctx context.Context, id uuid.UUID, suspended bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.users[id]
+	if !ok {
+		return ErrNotFound
+	}
+	user.SuspendedAt = sql.NullTime{Time: time.Now().UTC(), Valid: suspended}
+	s.users[id] = user
+	return nil
+}
+
+func (s *memoryStore) SetUserRole(ctx context.Context, id uuid.UUID, role string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	//the databases check this too
+	if role != RoleUser && role != RoleModerator && role != RoleAdmin {
+		return fmt.Errorf("unknown role %q", role)
+	}
+	user, ok := s.users[id]
+	if !ok {
+		return ErrNotFound
+	}
+	user.Role = role
+	user.UpdatedAt = time.Now().UTC()
+	s.users[id] = user
+	return nil
+}
+
+func (s *memoryStore) SetUserHandle(ctx context.Context, id uuid.UUID, handle string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.users[id]
+	if !ok {
+		return ErrNotFound
+	}
+	for _, other := range s.users {
+		if handle != "" && other.Handle == handle && other.ID != id {
+			return fmt.Errorf("%w: user with handle %q", ErrConflict, handle)
+		}
+	}
+	user.Handle = handle
+	user.UpdatedAt = time.Now().UTC()
+	s.users[id] = user
+	return nil
+}
+
+func (s *memoryStore) DeleteAllUsers(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users = map[uuid.UUID]User{}
+	s.chirps = map[uuid.UUID]Chirp{}
+	s.refreshTokens = map[string]RefreshToken{}
+	s.notifications = map[uuid.UUID]Notification{}
+	s.notificationPrefs = map[uuid.UUID]map[string]bool{}
+	s.conversations = map[uuid.UUID]Conversation{}
+	s.messages = map[uuid.UUID]Message{}
+	s.dmPrivacy = map[uuid.UUID]string{}
+	s.blocks = map[userPair]Block{}
+	s.mutes = map[userPair]Mute{}
+	s.contentFilters = map[uuid.UUID]ContentFilter{}
+	s.moderationCases = map[uuid.UUID]ModerationCase{}
+	s.reports = map[uuid.UUID]Report{}
+	return nil
+}
+
+func (s *memoryStore) CreateChirp(ctx context.Context, userID uuid.UUID, body string) (Chirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[userID]; !ok {
+		return Chirp{}, fmt.Errorf("user %v does not exist", userID)
+	}
+	now := time.Now().UTC()
+	chirp := Chirp{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Body:      body,
+		UserID:    userID,
+	}
+	s.chirps[chirp.ID] = chirp
+	return chirp, nil
+}
+
+func (s *memoryStore) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	chirp, ok := s.chirps[id]
+	if !ok {
+		return Chirp{}, ErrNotFound
+	}
+	return chirp, nil
+}
+
+func (s *memoryStore) GetChirps(ctx context.Context, ids []uuid.UUID) ([]Chirp, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var chirps []Chirp
+	for _, id := range ids {
+		if chirp, ok := s.chirps[id]; ok {
+			chirps = append(chirps, chirp)
+		}
+	}
+	return chirps, nil
+}
+
+func (s *memoryStore) ListChirps(ctx context.Context, params ListChirpsParams) ([]Chirp, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	hidden := map[uuid.UUID]bool{}
+	if params.ViewerID != uuid.Nil {
+		for _, id := range s.hiddenUserIDs(params.ViewerID, time.Now(), params.AuthorID == uuid.Nil) {
+			hidden[id] = true
+		}
+	}
+	chirps := []Chirp{}
+	for _, chirp := range s.chirps {
+		if params.AuthorID != uuid.Nil && chirp.UserID != params.AuthorID {
+			continue
+		}
+		if hidden[chirp.UserID] {
+			continue
+		}
+		if chirp.HiddenAt.Valid && (params.ViewerID == uuid.Nil || chirp.UserID != params.ViewerID) {
+			continue
+		}
+		chirps = append(chirps, chirp)
+	}
+	sort.Slice(chirps, func(i, j int) bool {
+		a, b := chirps[i], chirps[j]
+		if params.Desc {
+			a, b = b, a
+		}
+		if a.CreatedAt.Equal(b.CreatedAt) {
+			return a.ID.String() < b.ID.String()
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+	if !params.After.IsZero() {
+		start := len(chirps)
+		for i, chirp := range chirps {
+			if afterCursor(chirp, params.After, params.Desc) {
+				start = i
+				break
+			}
+		}
+		chirps = chirps[start:]
+	}
+	if params.Limit > 0 && len(chirps) > params.Limit {
+		chirps = chirps[:params.Limit]
+	}
+	return chirps, nil
+}
+
+// reports if the chirp comes after the cursor in the created_at, id ordering
+func afterCursor(chirp Chirp, cursor ChirpCursor, desc bool) bool {
+	var after bool
+	if chirp.CreatedAt.Equal(cursor.CreatedAt) {
+		after = chirp.ID.String() > cursor.ID.String()
+	} else {
+		after = chirp.CreatedAt.After(cursor.CreatedAt)
+	}
+	if desc {
+		return !after && chirp.ID != cursor.ID
+	}
+	return after
+}
+
+func (s *memoryStore) DeleteChirp(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.chirps, id)
+	for notificationID, n := range s.notifications {
+		if n.ChirpID.Valid && n.ChirpID.UUID == id {
+			delete(s.notifications, notificationID)
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) SetChirpHidden(ctx context.Context, id uuid.UUID, hidden bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	chirp, ok := s.chirps[id]
+	if !ok {
+		return ErrNotFound
+	}
+	chirp.HiddenAt = sql.NullTime{Time: time.Now().UTC(), Valid: hidden}
+	s.chirps[id] = chirp
+	return nil
+}
+
+func (s *memoryStore) CreateRefreshToken(ctx context.Context, token RefreshToken) (RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[token.UserID]; !ok {
+		return RefreshToken{}, fmt.Errorf("user %v does not exist", token.UserID)
+	}
+	if _, ok := s.refreshTokens[token.Token]; ok {
+		return RefreshToken{}, fmt.Errorf("%w: refresh token", ErrConflict)
+	}
+	s.refreshTokens[token.Token] = token
+	return token, nil
+}
+
+func (s *memoryStore) GetRefreshToken(ctx context.Context, token string) (RefreshToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	row, ok := s.refreshTokens[token]
+	if !ok {
+		return RefreshToken{}, ErrNotFound
+	}
+	return row, nil
+}
+
+func (s *memoryStore) RevokeRefreshToken(ctx context.Context, token string, revokedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	row, ok := s.refreshTokens[token]
+	if !ok {
+		return nil
+	}
+	row.RevokedAt = sql.NullTime{Time: revokedAt, Valid: true}
+	row.UpdatedAt = revokedAt
+	s.refreshTokens[token] = row
+	return nil
+}
+
+func (s *memoryStore) AddNotification(ctx context.Context, n Notification) (Notification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[n.UserID]; !ok {
+		return Notification{}, fmt.Errorf("user %v does not exist", n.UserID)
+	}
+	now := time.Now().UTC()
+	n.ID = uuid.New()
+	n.CreatedAt = now
+	n.UpdatedAt = now
+	n.ReadAt = sql.NullTime{}
+	s.notifications[n.ID] = n
+	return n, nil
+}
+
+func (s *memoryStore) ListNotifications(ctx context.Context, userID uuid.UUID, params ListNotificationsParams) ([]Notification, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	notifications := []Notification{}
+	for _, n := range s.notifications {
+		if n.UserID != userID || (params.UnreadOnly && n.ReadAt.Valid) {
+			continue
+		}
+		if !params.After.IsZero() && !notificationBefore(n, params.After) {
+			continue
+		}
+		notifications = append(notifications, n)
+	}
+	sort.Slice(notifications, func(i, j int) bool {
+		return notificationBefore(notifications[j], NotificationCursor{UpdatedAt: notifications[i].UpdatedAt, ID: notifications[i].ID})
+	})
+	if params.Limit > 0 && len(notifications) > params.Limit {
+		notifications = notifications[:params.Limit]
+	}
+	return notifications, nil
+}
+
+// reports if the notification comes after the cursor in the updated_at desc, id desc ordering
+func notificationBefore(n Notification, cursor NotificationCursor) bool {
+	if n.UpdatedAt.Equal(cursor.UpdatedAt) {
+		return n.ID.String() < cursor.ID.String()
+	}
+	return n.UpdatedAt.Before(cursor.UpdatedAt)
+}
+
+func (s *memoryStore) CountUnreadNotifications(ctx context.Context, userID uuid.UUID) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	count := 0
+	for _, n := range s.notifications {
+		if n.UserID == userID && !n.ReadAt.Valid {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *memoryStore) MarkNotificationRead(ctx context.Context, userID, id uuid.UUID, readAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n, ok := s.notifications[id]
+	if !ok || n.UserID != userID {
+		return ErrNotFound
+	}
+	if !n.ReadAt.Valid {
+		n.ReadAt = sql.NullTime{Time: readAt.UTC(), Valid: true}
+		s.notifications[id] = n
+	}
+	return nil
+}
+
+func (s *memoryStore) MarkAllNotificationsRead(ctx context.Context, userID uuid.UUID, readAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, n := range s.notifications {
+		if n.UserID == userID && !n.ReadAt.Valid {
+			n.ReadAt = sql.NullTime{Time: readAt.UTC(), Valid: true}
+			s.notifications[id] = n
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) GetNotificationPreferences(ctx context.Context, userID uuid.UUID) (map[string]bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	prefs := map[string]bool{}
+	for notificationType, enabled := range s.notificationPrefs[userID] {
+		prefs[notificationType] = enabled
+	}
+	return prefs, nil
+}
+
+func (s *memoryStore) SetNotificationPreference(ctx context.Context, userID uuid.UUID, notificationType string, enabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[userID]; !ok {
+		return fmt.Errorf("user %v does not exist", userID)
+	}
+	if s.notificationPrefs[userID] == nil {
+		s.notificationPrefs[userID] = map[string]bool{}
+	}
+	s.notificationPrefs[userID][notificationType] = enabled
+	return nil
+}
+
+func (s *memoryStore) CreateConversation(ctx context.Context, createdBy uuid.UUID, memberIDs []uuid.UUID) (Conversation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UTC()
+	conversation := Conversation{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, CreatedBy: createdBy}
+	for _, userID := range memberIDs {
+		if _, ok := s.users[userID]; !ok {
+			return Conversation{}, fmt.Errorf("user %s does not exist", userID)
+		}
+		conversation.Members = append(conversation.Members, ConversationMember{UserID: userID, JoinedAt: now})
+	}
+	s.conversations[conversation.ID] = conversation
+	return copyConversation(conversation), nil
+}
+
+// the members are copied so callers can't change them under the lock
+func copyConversation(c Conversation) Conversation {
+	c.Members = append([]ConversationMember(nil), c.Members...)
+	return c
+}
+
+func (s *memoryStore) GetConversation(ctx context.Context, id uuid.UUID) (Conversation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	conversation, ok := s.conversations[id]
+	if !ok {
+		return Conversation{}, ErrNotFound
+	}
+	return copyConversation(conversation), nil
+}
+
+func (s *memoryStore) FindDirectConversation(ctx context.Context, a, b uuid.UUID) (Conversation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	found, ok := Conversation{}, false
+	for _, conversation := range s.conversations {
+		if len(conversation.Members) != 2 || !isMember(conversation, a) || !isMember(conversation, b) {
+			continue
+		}
+		if !ok || conversationBefore(conversation, found) {
+			found, ok = conversation, true
+		}
+	}
+	if !ok {
+		return Conversation{}, ErrNotFound
+	}
+	return copyConversation(found), nil
+}
+
+func conversationBefore(a, b Conversation) bool {
+	if a.CreatedAt.Equal(b.CreatedAt) {
+		return a.ID.String() < b.ID.String()
+	}
+	return a.CreatedAt.Before(b.CreatedAt)
+}
+
+func isMember(conversation Conversation, userID uuid.UUID) bool {
+	for _, member := range conversation.Members {
+		if member.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *memoryStore) ListConversations(ctx context.Context, userID uuid.UUID) ([]Conversation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	conversations := []Conversation{}
+	for _, conversation := range s.conversations {
+		if isMember(conversation, userID) {
+			conversations = append(conversations, copyConversation(conversation))
+		}
+	}
+	sort.Slice(conversations, func(i, j int) bool {
+		if conversations[i].UpdatedAt.Equal(conversations[j].UpdatedAt) {
+			return conversations[i].ID.String() > conversations[j].ID.String()
+		}
+		return conversations[i].UpdatedAt.After(conversations[j].UpdatedAt)
+	})
+	return conversations, nil
+}
+
+func (s *memoryStore) CreateMessage(ctx context.Context, conversationID, senderID uuid.UUID, body string) (Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conversation, ok := s.conversations[conversationID]
+	if !ok {
+		return Message{}, fmt.Errorf("conversation %s does not exist", conversationID)
+	}
+	message := Message{
+		ID:             uuid.New(),
+		CreatedAt:      time.Now().UTC(),
+		ConversationID: conversationID,
+		SenderID:       senderID,
+		Body:           body,
+	}
+	s.messages[message.ID] = message
+	conversation.UpdatedAt = message.CreatedAt
+	for i, member := range conversation.Members {
+		if member.UserID == senderID {
+			conversation.Members[i].LastReadAt = sql.NullTime{Time: message.CreatedAt, Valid: true}
+		}
+	}
+	s.conversations[conversationID] = conversation
+	return message, nil
+}
+
+func (s *memoryStore) ListMessages(ctx context.Context, conversationID uuid.UUID, params ListMessagesParams) ([]Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	messages := []Message{}
+	for _, message := range s.messages {
+		if message.ConversationID != conversationID {
+			continue
+		}
+		if !params.After.IsZero() && !messageBefore(message, params.After) {
+			continue
+		}
+		messages = append(messages, message)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messageBefore(messages[j], MessageCursor{CreatedAt: messages[i].CreatedAt, ID: messages[i].ID})
+	})
+	if params.Limit > 0 && len(messages) > params.Limit {
+		messages = messages[:params.Limit]
+	}
+	return messages, nil
+}
+
+// reports if the message comes after the cursor in the created_at desc, id desc ordering
+func messageBefore(m Message, cursor MessageCursor) bool {
+	if m.CreatedAt.Equal(cursor.CreatedAt) {
+		return m.ID.String() < cursor.ID.String()
+	}
+	return m.CreatedAt.Before(cursor.CreatedAt)
+}
+
+func (s *memoryStore) MarkConversationRead(ctx context.Context, conversationID, userID uuid.UUID, readAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conversation, ok := s.conversations[conversationID]
+	if !ok {
+		return ErrNotFound
+	}
+	for i, member := range conversation.Members {
+		if member.UserID == userID {
+			conversation.Members[i].LastReadAt = sql.NullTime{Time: readAt.UTC(), Valid: true}
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (s *memoryStore) GetDMPrivacy(ctx context.Context, userID uuid.UUID) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.dmPrivacy[userID], nil
+}
+
+func (s *memoryStore) SetDMPrivacy(ctx context.Context, userID uuid.UUID, privacy string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dmPrivacy[userID] = privacy
+	return nil
+}
+
+func (s *memoryStore) BlockUser(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := userPair{blockerID, blockedID}
+	if _, ok := s.blocks[key]; !ok {
+		s.blocks[key] = Block{BlockerID: blockerID, BlockedID: blockedID, CreatedAt: time.Now().UTC()}
+	}
+	return nil
+}
+
+func (s *memoryStore) UnblockUser(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := userPair{blockerID, blockedID}
+	if _, ok := s.blocks[key]; !ok {
+		return ErrNotFound
+	}
+	delete(s.blocks, key)
+	return nil
+}
+
+func (s *memoryStore) ListBlocks(ctx context.Context, blockerID uuid.UUID) ([]Block, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	blocks := []Block{}
+	for _, block := range s.blocks {
+		if block.BlockerID == blockerID {
+			blocks = append(blocks, block)
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].CreatedAt.Equal(blocks[j].CreatedAt) {
+			return blocks[i].BlockedID.String() < blocks[j].BlockedID.String()
+		}
+		return blocks[i].CreatedAt.After(blocks[j].CreatedAt)
+	})
+	return blocks, nil
+}
+
+func (s *memoryStore) IsBlocked(ctx context.Context, a, b uuid.UUID) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ab := s.blocks[userPair{a, b}]
+	_, ba := s.blocks[userPair{b, a}]
+	return ab || ba, nil
+}
+
+func (s *memoryStore) MuteUser(ctx context.Context, mute Mute) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	mute.CreatedAt = time.Now().UTC()
+	if mute.ExpiresAt.Valid {
+		mute.ExpiresAt.Time = mute.ExpiresAt.Time.UTC()
+	}
+	s.mutes[userPair{mute.MuterID, mute.MutedID}] = mute
+	return nil
+}
+
+func (s *memoryStore) UnmuteUser(ctx context.Context, muterID, mutedID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := userPair{muterID, mutedID}
+	if _, ok := s.mutes[key]; !ok {
+		return ErrNotFound
+	}
+	delete(s.mutes, key)
+	return nil
+}
+
+func (s *memoryStore) ListMutes(ctx context.Context, muterID uuid.UUID, now time.Time) ([]Mute, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	mutes := []Mute{}
+	for _, mute := range s.mutes {
+		if mute.MuterID == muterID && muteActive(mute, now) {
+			mutes = append(mutes, mute)
+		}
+	}
+	sort.Slice(mutes, func(i, j int) bool {
+		if mutes[i].CreatedAt.Equal(mutes[j].CreatedAt) {
+			return mutes[i].MutedID.String() < mutes[j].MutedID.String()
+		}
+		return mutes[i].CreatedAt.After(mutes[j].CreatedAt)
+	})
+	return mutes, nil
+}
+
+func muteActive(mute Mute, now time.Time) bool {
+	return !mute.ExpiresAt.Valid || mute.ExpiresAt.Time.After(now)
+}
+
+func (s *memoryStore) HiddenUserIDs(ctx context.Context, viewerID uuid.UUID, now time.Time) ([]uuid.UUID, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.hiddenUserIDs(viewerID, now, true), nil
+}
+
+// callers hold the lock
+func (s *memoryStore) hiddenUserIDs(viewerID uuid.UUID, now time.Time, withMutes bool) []uuid.UUID {
+	seen := map[uuid.UUID]bool{}
+	var ids []uuid.UUID
+	add := func(id uuid.UUID) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for key := range s.blocks {
+		if key.from == viewerID {
+			add(key.to)
+		}
+		if key.to == viewerID {
+			add(key.from)
+		}
+	}
+	if withMutes {
+		for key, mute := range s.mutes {
+			if key.from == viewerID && muteActive(mute, now) {
+				add(key.to)
+			}
+		}
+	}
+	return ids
+}
+
+func (s *memoryStore) CreateContentFilter(ctx context.Context, filter ContentFilter) (ContentFilter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[filter.UserID]; !ok {
+		return ContentFilter{}, fmt.Errorf("user %v does not exist", filter.UserID)
+	}
+	filter.ID = uuid.New()
+	filter.CreatedAt = time.Now().UTC()
+	if filter.ExpiresAt.Valid {
+		filter.ExpiresAt.Time = filter.ExpiresAt.Time.UTC()
+	}
+	s.contentFilters[filter.ID] = filter
+	return filter, nil
+}
+
+func (s *memoryStore) ListContentFilters(ctx context.Context, userID uuid.UUID, now time.Time) ([]ContentFilter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	filters := []ContentFilter{}
+	for _, filter := range s.contentFilters {
+		if filter.UserID == userID && (!filter.ExpiresAt.Valid || filter.ExpiresAt.Time.After(now)) {
+			filters = append(filters, filter)
+		}
+	}
+	sort.Slice(filters, func(i, j int) bool {
+		if filters[i].CreatedAt.Equal(filters[j].CreatedAt) {
+			return filters[i].ID.String() < filters[j].ID.String()
+		}
+		return filters[i].CreatedAt.After(filters[j].CreatedAt)
+	})
+	return filters, nil
+}
+
+func (s *memoryStore) DeleteContentFilter(ctx context.Context, userID, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	filter, ok := s.contentFilters[id]
+	if !ok || filter.UserID != userID {
+		return ErrNotFound
+	}
+	delete(s.contentFilters, id)
+	return nil
+}
+
+func (s *memoryStore) ListProfanityTerms(ctx context.Context) ([]ProfanityTerm, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	terms := []ProfanityTerm{}
+	for _, term := range s.profanityTerms {
+		terms = append(terms, term)
+	}
+	sort.Slice(terms, func(i, j int) bool { return terms[i].Term < terms[j].Term })
+	return terms, nil
+}
+
+// callers hold the lock
+func (s *memoryStore) profanityTermTaken(id uuid.UUID, term string) bool {
+	for _, existing := range s.profanityTerms {
+		if existing.Term == term && existing.ID != id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *memoryStore) CreateProfanityTerm(ctx context.Context, term, action string) (ProfanityTerm, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.profanityTermTaken(uuid.Nil, term) {
+		return ProfanityTerm{}, fmt.Errorf("%w: profanity term %q", ErrConflict, term)
+	}
+	now := time.Now().UTC()
+	created := ProfanityTerm{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Term: term, Action: action}
+	s.profanityTerms[created.ID] = created
+	return created, nil
+}
+
+func (s *memoryStore) UpdateProfanityTerm(ctx context.Context, id uuid.UUID, term, action string) (ProfanityTerm, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.profanityTerms[id]
+	if !ok {
+		return ProfanityTerm{}, ErrNotFound
+	}
+	if s.profanityTermTaken(id, term) {
+		return ProfanityTerm{}, fmt.Errorf("%w: profanity term %q", ErrConflict, term)
+	}
+	existing.Term, existing.Action, existing.UpdatedAt = term, action, time.Now().UTC()
+	s.profanityTerms[id] = existing
+	return existing, nil
+}
+
+func (s *memoryStore) DeleteProfanityTerm(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.profanityTerms[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.profanityTerms, id)
+	return nil
+}
+
+func (s *memoryStore) OpenModerationCase(ctx context.Context, chirp Chirp) (ModerationCase, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.moderationCases {
+		if c.ChirpID == chirp.ID && !c.ResolvedAt.Valid {
+			return copyCase(c), nil
+		}
+	}
+	if _, ok := s.users[chirp.UserID]; !ok {
+		return ModerationCase{}, fmt.Errorf("user %v does not exist", chirp.UserID)
+	}
+	now := time.Now().UTC()
+	c := ModerationCase{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, ChirpID: chirp.ID, AuthorID: chirp.UserID, Body: chirp.Body}
+	s.moderationCases[c.ID] = c
+	return c, nil
+}
+
+func copyCase(c ModerationCase) ModerationCase {
+	c.FlaggedTerms = append([]string(nil), c.FlaggedTerms...)
+	return c
+}
+
+func (s *memoryStore) AddReport(ctx context.Context, report Report) (ModerationCase, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.moderationCases[report.CaseID]
+	if !ok {
+		return ModerationCase{}, ErrNotFound
+	}
+	if _, ok := s.users[report.ReporterID]; !ok {
+		return ModerationCase{}, fmt.Errorf("user %v does not exist", report.ReporterID)
+	}
+	for _, r := range s.reports {
+		if r.CaseID == report.CaseID && r.ReporterID == report.ReporterID {
+			return ModerationCase{}, fmt.Errorf("%w: reports_case_id_reporter_id_key", ErrConflict)
+		}
+	}
+	now := time.Now().UTC()
+	report.ID, report.CreatedAt = uuid.New(), now
+	s.reports[report.ID] = report
+	c.Reports++
+	c.UpdatedAt = now
+	s.moderationCases[c.ID] = c
+	return copyCase(c), nil
+}
+
+func (s *memoryStore) FlagModerationCase(ctx context.Context, id uuid.UUID, terms []string) (ModerationCase, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.moderationCases[id]
+	if !ok {
+		return ModerationCase{}, ErrNotFound
+	}
+	c.FlaggedTerms = append([]string(nil), terms...)
+	c.UpdatedAt = time.Now().UTC()
+	s.moderationCases[id] = c
+	return copyCase(c), nil
+}
+
+func (s *memoryStore) GetModerationCase(ctx context.Context, id uuid.UUID) (ModerationCase, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.moderationCases[id]
+	if !ok {
+		return ModerationCase{}, ErrNotFound
+	}
+	return copyCase(c), nil
+}
+
+func (s *memoryStore) ListModerationCases(ctx context.Context, status string) ([]ModerationCase, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	cases := []ModerationCase{}
+	for _, c := range s.moderationCases {
+		if c.Status() == status || (status == "" && c.Status() != CaseResolved) {
+			cases = append(cases, copyCase(c))
+		}
+	}
+	sort.Slice(cases, func(i, j int) bool {
+		if cases[i].CreatedAt.Equal(cases[j].CreatedAt) {
+			return cases[i].ID.String() < cases[j].ID.String()
+		}
+		return cases[i].CreatedAt.Before(cases[j].CreatedAt)
+	})
+	return cases, nil
+}
+
+func (s *memoryStore) ListReports(ctx context.Context, caseID uuid.UUID) ([]Report, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	reports := []Report{}
+	for _, r := range s.reports {
+		if r.CaseID == caseID {
+			reports = append(reports, r)
+		}
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].CreatedAt.Equal(reports[j].CreatedAt) {
+			return reports[i].ID.String() < reports[j].ID.String()
+		}
+		return reports[i].CreatedAt.Before(reports[j].CreatedAt)
+	})
+	return reports, nil
+}
+
+func (s *memoryStore) ClaimModerationCase(ctx context.Context, id uuid.UUID, moderator string) (ModerationCase, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.changeableCase(id, moderator)
+	if err != nil {
+		return ModerationCase{}, err
+	}
+	now := time.Now().UTC()
+	c.ClaimedBy, c.ClaimedAt, c.UpdatedAt = moderator, sql.NullTime{Time: now, Valid: true}, now
+	s.moderationCases[id] = c
+	return copyCase(c), nil
+}
+
+func (s *memoryStore) ResolveModerationCase(ctx context.Context, id uuid.UUID, moderator, resolution, note string) (ModerationCase, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.changeableCase(id, moderator)
+	if err != nil {
+		return ModerationCase{}, err
+	}
+	now := time.Now().UTC()
+	c.Resolution, c.Note, c.ResolvedBy = resolution, note, moderator
+	c.ResolvedAt, c.UpdatedAt = sql.NullTime{Time: now, Valid: true}, now
+	s.moderationCases[id] = c
+	return copyCase(c), nil
+}
+
+// callers hold the lock
+func (s *memoryStore) changeableCase(id uuid.UUID, moderator string) (ModerationCase, error) {
+	c, ok := s.moderationCases[id]
+	if !ok {
+		return ModerationCase{}, ErrNotFound
+	}
+	if c.ResolvedAt.Valid || (c.ClaimedBy != "" && c.ClaimedBy != moderator) {
+		return ModerationCase{}, fmt.Errorf("%w: the case is resolved or claimed by someone else", ErrConflict)
+	}
+	return c, nil
+}
@@ -0,0 +1,937 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/christianrm0821/Chirpy/internal/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// adapts the sqlc queries to the Store interface
+type postgresStore struct {
+	db   *sql.DB
+	q    *database.Queries
+	wrap func(database.DBTX) database.DBTX
+}
+
+// returns a Store backed by the postgres sqlc queries on db
+// wrap is put around db and every transaction the queries run on (for tracing), nil leaves them as they are
+func NewPostgres(db *sql.DB, wrap func(database.DBTX) database.DBTX) Store {
+	if wrap == nil {
+		wrap = func(db database.DBTX) database.DBTX { return db }
+	}
+	return &postgresStore{db: db, q: database.New(wrap(db)), wrap: wrap}
+}
+
+// runs fn on queries in one transaction, which is rolled back when fn returns an error
+func (s *postgresStore) inTx(ctx context.Context, fn func(q *database.Queries) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	err = fn(database.New(s.wrap(tx)))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// turns sql.ErrNoRows into ErrNotFound so callers don't depend on database/sql
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// turns unique violations (23505) into ErrConflict
+func conflict(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return fmt.Errorf("%w: %s", ErrConflict, pqErr.Constraint)
+	}
+	return err
+}
+
+func userFromDB(u database.User) User {
+	return User{
+		ID:             u.ID,
+		CreatedAt:      u.CreatedAt,
+		UpdatedAt:      u.UpdatedAt,
+		Email:          u.Email,
+		HashedPassword: u.HashedPassword,
+		IsChirpyRed:    u.IsChirpyRed,
+		SuspendedAt:    u.SuspendedAt,
+		Role:           u.Role,
+		Handle:         u.Handle.String,
+	}
+}
+
+func chirpFromDB(c database.Chirp) Chirp {
+	return Chirp{
+		ID:        c.ID,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+		Body:      c.Body,
+		UserID:    c.UserID,
+		HiddenAt:  c.HiddenAt,
+	}
+}
+
+func refreshTokenFromDB(t database.RefreshToken) RefreshToken {
+	return RefreshToken{
+		Token:     t.Token,
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+		UserID:    t.UserID,
+		ExpiresAt: t.ExpiresAt,
+		RevokedAt: t.RevokedAt,
+	}
+}
+
+func (s *postgresStore) CreateUser(ctx context.Context, email, hashedPassword string) (User, error) {
+	user, err := s.q.CreateUser(ctx, database.CreateUserParams{
+		Email:          email,
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		return User{}, conflict(err)
+	}
+	return userFromDB(user), nil
+}
+
+func (s *postgresStore) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	user, err := s.q.GetUserByEmail(ctx, email)
+	if err != nil {
+		return User{}, notFound(err)
+	}
+	return userFromDB(user), nil
+}
+
+func (s *postgresStore) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
+	user, err := s.q.GetUserFromID(ctx, id)
+	if err != nil {
+		return User{}, notFound(err)
+	}
+	return userFromDB(user), nil
+}
+
+func (s *postgresStore) GetUserByHandle(ctx context.Context, handle string) (User, error) {
+	user, err := s.q.GetUserByHandle(ctx, sql.NullString{String: handle, Valid: true})
+	if err != nil {
+		return User{}, notFound(err)
+	}
+	return userFromDB(user), nil
+}
+
+func (s *postgresStore) UpdateUserCredentials(ctx context.Context, id uuid.UUID, email, hashedPassword string) error {
+	err := s.q.UpdatePasswordEmailFromUserID(ctx, database.UpdatePasswordEmailFromUserIDParams{
+		HashedPassword: hashedPassword,
+		Email:          email,
+		ID:             id,
+	})
+	return conflict(err)
+}
+
+func (s *postgresStore) UpgradeUserToChirpyRed(ctx context.Context, id uuid.UUID) error {
+	return s.q.UpdateUserSubWithID(ctx, id)
+}
+
+func (s *postgresStore) SetUserSuspended(ctx context.Context, id uuid.UUID, suspended bool) error {
+	n, err := s.q.SetUserSuspended(ctx, database.SetUserSuspendedParams{
+		SuspendedAt: sql.NullTime{Time: time.Now().UTC(), Valid: suspended},
+		ID:          id,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *postgresStore) SetUserRole(ctx context.Context, id uuid.UUID, role string) error {
+	n, err := s.q.SetUserRole(ctx, database.SetUserRoleParams{Role: role, UpdatedAt: time.Now().UTC(), ID: id})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *postgresStore) SetUserHandle(ctx context.Context, id uuid.UUID, handle string) error {
+	n, err := s.q.SetUserHandle(ctx, database.SetUserHandleParams{
+		Handle:    sql.NullString{String: handle, Valid: handle != ""},
+		UpdatedAt: time.Now().UTC(),
+		ID:        id,
+	})
+	if err != nil {
+		return conflict(err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *postgresStore) DeleteAllUsers(ctx context.Context) error {
+	return s.q.DeleteUsers(ctx)
+}
+
+func (s *postgresStore) CreateChirp(ctx context.Context, userID uuid.UUID, body string) (Chirp, error) {
+	chirp, err := s.q.CreateChirp(ctx, database.CreateChirpParams{
+		Body:   body,
+		UserID: userID,
+	})
+	if err != nil {
+		return Chirp{}, err
+	}
+	return chirpFromDB(chirp), nil
+}
+
+func (s *postgresStore) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	chirp, err := s.q.GetChirpWithID(ctx, id)
+	if err != nil {
+		return Chirp{}, notFound(err)
+	}
+	return chirpFromDB(chirp), nil
+}
+
+func (s *postgresStore) GetChirps(ctx context.Context, ids []uuid.UUID) ([]Chirp, error) {
+	rows, err := s.q.GetChirpsWithIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	chirps := make([]Chirp, 0, len(rows))
+	for _, row := range rows {
+		chirps = append(chirps, chirpFromDB(row))
+	}
+	return chirps, nil
+}
+
+func (s *postgresStore) ListChirps(ctx context.Context, params ListChirpsParams) ([]Chirp, error) {
+	var rows []database.Chirp
+	var err error
+	switch {
+	case params.Limit > 0 || !params.After.IsZero() || params.ViewerID != uuid.Nil:
+		rows, err = s.listChirpsPage(ctx, params)
+	case params.AuthorID == uuid.Nil && params.Desc:
+		rows, err = s.q.GetAllChirpsDesc(ctx)
+	case params.AuthorID == uuid.Nil:
+		rows, err = s.q.GetAllChirps(ctx)
+	case params.Desc:
+		rows, err = s.q.GetAllChirpFromIDDesc(ctx, params.AuthorID)
+	default:
+		rows, err = s.q.GetAllChripsFromID(ctx, params.AuthorID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	chirps := make([]Chirp, 0, len(rows))
+	for _, row := range rows {
+		chirps = append(chirps, chirpFromDB(row))
+	}
+	return chirps, nil
+}
+
+// uses the keyset queries, they order by created_at then id so pages never skip or repeat a chirp
+func (s *postgresStore) listChirpsPage(ctx context.Context, params ListChirpsParams) ([]database.Chirp, error) {
+	pageSize := int32(math.MaxInt32)
+	if params.Limit > 0 {
+		pageSize = int32(params.Limit)
+	}
+	arg := database.ListChirpsPageParams{
+		AuthorID: uuid.NullUUID{UUID: params.AuthorID, Valid: params.AuthorID != uuid.Nil},
+		ViewerID: uuid.NullUUID{UUID: params.ViewerID, Valid: params.ViewerID != uuid.Nil},
+		Now:      time.Now().UTC(),
+		PageSize: pageSize,
+	}
+	if !params.After.IsZero() {
+		arg.AfterCreatedAt = sql.NullTime{Time: params.After.CreatedAt, Valid: true}
+		arg.AfterID = uuid.NullUUID{UUID: params.After.ID, Valid: true}
+	}
+	if params.Desc {
+		return s.q.ListChirpsPageDesc(ctx, database.ListChirpsPageDescParams(arg))
+	}
+	return s.q.ListChirpsPage(ctx, arg)
+}
+
+func (s *postgresStore) DeleteChirp(ctx context.Context, id uuid.UUID) error {
+	return s.q.DeleteChirpWithID(ctx, id)
+}
+
+func (s *postgresStore) SetChirpHidden(ctx context.Context, id uuid.UUID, hidden bool) error {
+	n, err := s.q.SetChirpHidden(ctx, database.SetChirpHiddenParams{
+		HiddenAt: sql.NullTime{Time: time.Now().UTC(), Valid: hidden},
+		ID:       id,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *postgresStore) CreateRefreshToken(ctx context.Context, token RefreshToken) (RefreshToken, error) {
+	row, err := s.q.CreateRefreshToken(ctx, database.CreateRefreshTokenParams{
+		Token:     token.Token,
+		CreatedAt: token.CreatedAt,
+		UpdatedAt: token.UpdatedAt,
+		UserID:    token.UserID,
+		ExpiresAt: token.ExpiresAt,
+		RevokedAt: token.RevokedAt,
+	})
+	if err != nil {
+		return RefreshToken{}, conflict(err)
+	}
+	return refreshTokenFromDB(row), nil
+}
+
+func (s *postgresStore) GetRefreshToken(ctx context.Context, token string) (RefreshToken, error) {
+	row, err := s.q.GetUserFromRefreshToken(ctx, token)
+	if err != nil {
+		return RefreshToken{}, notFound(err)
+	}
+	return refreshTokenFromDB(row), nil
+}
+
+func (s *postgresStore) RevokeRefreshToken(ctx context.Context, token string, revokedAt time.Time) error {
+	return s.q.RevokeRefreshToken(ctx, database.RevokeRefreshTokenParams{
+		RevokedAt: sql.NullTime{Time: revokedAt, Valid: true},
+		UpdatedAt: revokedAt,
+		Token:     token,
+	})
+}
+
+func notificationFromDB(n database.Notification) Notification {
+	return Notification{
+		ID:        n.ID,
+		CreatedAt: n.CreatedAt,
+		UpdatedAt: n.UpdatedAt,
+		UserID:    n.UserID,
+		Type:      n.Type,
+		ActorID:   n.ActorID,
+		ChirpID:   n.ChirpID,
+		ReadAt:    n.ReadAt,
+	}
+}
+
+func (s *postgresStore) AddNotification(ctx context.Context, n Notification) (Notification, error) {
+	now := time.Now().UTC()
+	row, err := s.q.CreateNotification(ctx, database.CreateNotificationParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UserID:    n.UserID,
+		Type:      n.Type,
+		ActorID:   n.ActorID,
+		ChirpID:   n.ChirpID,
+	})
+	if err != nil {
+		return Notification{}, err
+	}
+	return notificationFromDB(row), nil
+}
+
+func (s *postgresStore) ListNotifications(ctx context.Context, userID uuid.UUID, params ListNotificationsParams) ([]Notification, error) {
+	pageSize := int32(math.MaxInt32)
+	if params.Limit > 0 {
+		pageSize = int32(params.Limit)
+	}
+	arg := database.ListNotificationsParams{UserID: userID, PageSize: pageSize}
+	if !params.After.IsZero() {
+		arg.AfterUpdatedAt = sql.NullTime{Time: params.After.UpdatedAt, Valid: true}
+		arg.AfterID = uuid.NullUUID{UUID: params.After.ID, Valid: true}
+	}
+	var rows []database.Notification
+	var err error
+	if params.UnreadOnly {
+		rows, err = s.q.ListUnreadNotifications(ctx, database.ListUnreadNotificationsParams(arg))
+	} else {
+		rows, err = s.q.ListNotifications(ctx, arg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	notifications := make([]Notification, 0, len(rows))
+	for _, row := range rows {
+		notifications = append(notifications, notificationFromDB(row))
+	}
+	return notifications, nil
+}
+
+func (s *postgresStore) CountUnreadNotifications(ctx context.Context, userID uuid.UUID) (int, error) {
+	count, err := s.q.CountUnreadNotifications(ctx, userID)
+	return int(count), err
+}
+
+func (s *postgresStore) MarkNotificationRead(ctx context.Context, userID, id uuid.UUID, readAt time.Time) error {
+	n, err := s.q.MarkNotificationRead(ctx, database.MarkNotificationReadParams{
+		ReadAt: sql.NullTime{Time: readAt.UTC(), Valid: true},
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *postgresStore) MarkAllNotificationsRead(ctx context.Context, userID uuid.UUID, readAt time.Time) error {
+	return s.q.MarkAllNotificationsRead(ctx, database.MarkAllNotificationsReadParams{
+		ReadAt: sql.NullTime{Time: readAt.UTC(), Valid: true},
+		UserID: userID,
+	})
+}
+
+func (s *postgresStore) GetNotificationPreferences(ctx context.Context, userID uuid.UUID) (map[string]bool, error) {
+	rows, err := s.q.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	prefs := map[string]bool{}
+	for _, row := range rows {
+		prefs[row.Type] = row.Enabled
+	}
+	return prefs, nil
+}
+
+func (s *postgresStore) SetNotificationPreference(ctx context.Context, userID uuid.UUID, notificationType string, enabled bool) error {
+	return s.q.SetNotificationPreference(ctx, database.SetNotificationPreferenceParams{
+		UserID:  userID,
+		Type:    notificationType,
+		Enabled: enabled,
+	})
+}
+
+func conversationFromDB(c database.Conversation, members []database.ConversationMember) Conversation {
+	conversation := Conversation{
+		ID:        c.ID,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+		CreatedBy: c.CreatedBy,
+	}
+	for _, m := range members {
+		if m.ConversationID != c.ID {
+			continue
+		}
+		conversation.Members = append(conversation.Members, ConversationMember{
+			UserID:     m.UserID,
+			JoinedAt:   m.JoinedAt,
+			LastReadAt: m.LastReadAt,
+		})
+	}
+	return conversation
+}
+
+func messageFromDB(m database.Message) Message {
+	return Message{
+		ID:             m.ID,
+		CreatedAt:      m.CreatedAt,
+		ConversationID: m.ConversationID,
+		SenderID:       m.SenderID,
+		Body:           m.Body,
+	}
+}
+
+func (s *postgresStore) CreateConversation(ctx context.Context, createdBy uuid.UUID, memberIDs []uuid.UUID) (Conversation, error) {
+	now := time.Now().UTC()
+	var row database.Conversation
+	err := s.inTx(ctx, func(q *database.Queries) error {
+		var err error
+		row, err = q.CreateConversation(ctx, database.CreateConversationParams{
+			ID:        uuid.New(),
+			CreatedAt: now,
+			CreatedBy: createdBy,
+		})
+		if err != nil {
+			return err
+		}
+		for _, userID := range memberIDs {
+			err := q.AddConversationMember(ctx, database.AddConversationMemberParams{
+				ConversationID: row.ID,
+				UserID:         userID,
+				JoinedAt:       now,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return Conversation{}, err
+	}
+	return s.conversationWithMembers(ctx, row)
+}
+
+func (s *postgresStore) conversationWithMembers(ctx context.Context, row database.Conversation) (Conversation, error) {
+	members, err := s.q.ListConversationMembers(ctx, row.ID)
+	if err != nil {
+		return Conversation{}, err
+	}
+	return conversationFromDB(row, members), nil
+}
+
+func (s *postgresStore) GetConversation(ctx context.Context, id uuid.UUID) (Conversation, error) {
+	row, err := s.q.GetConversation(ctx, id)
+	if err != nil {
+		return Conversation{}, notFound(err)
+	}
+	return s.conversationWithMembers(ctx, row)
+}
+
+func (s *postgresStore) FindDirectConversation(ctx context.Context, a, b uuid.UUID) (Conversation, error) {
+	row, err := s.q.FindDirectConversation(ctx, database.FindDirectConversationParams{UserA: a, UserB: b})
+	if err != nil {
+		return Conversation{}, notFound(err)
+	}
+	return s.conversationWithMembers(ctx, row)
+}
+
+func (s *postgresStore) ListConversations(ctx context.Context, userID uuid.UUID) ([]Conversation, error) {
+	rows, err := s.q.ListConversations(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.q.ListMembersOfUserConversations(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	conversations := make([]Conversation, 0, len(rows))
+	for _, row := range rows {
+		conversations = append(conversations, conversationFromDB(row, members))
+	}
+	return conversations, nil
+}
+
+func (s *postgresStore) CreateMessage(ctx context.Context, conversationID, senderID uuid.UUID, body string) (Message, error) {
+	var row database.Message
+	err := s.inTx(ctx, func(q *database.Queries) error {
+		var err error
+		row, err = q.CreateMessage(ctx, database.CreateMessageParams{
+			ID:             uuid.New(),
+			CreatedAt:      time.Now().UTC(),
+			ConversationID: conversationID,
+			SenderID:       senderID,
+			Body:           body,
+		})
+		if err != nil {
+			return err
+		}
+		err = q.TouchConversation(ctx, database.TouchConversationParams{UpdatedAt: row.CreatedAt, ID: conversationID})
+		if err != nil {
+			return err
+		}
+		//the sender has read everything up to their own message
+		_, err = q.MarkConversationRead(ctx, database.MarkConversationReadParams{
+			LastReadAt:     sql.NullTime{Time: row.CreatedAt, Valid: true},
+			ConversationID: conversationID,
+			UserID:         senderID,
+		})
+		return err
+	})
+	if err != nil {
+		return Message{}, err
+	}
+	return messageFromDB(row), nil
+}
+
+func (s *postgresStore) ListMessages(ctx context.Context, conversationID uuid.UUID, params ListMessagesParams) ([]Message, error) {
+	pageSize := int32(math.MaxInt32)
+	if params.Limit > 0 {
+		pageSize = int32(params.Limit)
+	}
+	arg := database.ListMessagesParams{ConversationID: conversationID, PageSize: pageSize}
+	if !params.After.IsZero() {
+		arg.AfterCreatedAt = sql.NullTime{Time: params.After.CreatedAt.UTC(), Valid: true}
+		arg.AfterID = uuid.NullUUID{UUID: params.After.ID, Valid: true}
+	}
+	rows, err := s.q.ListMessages(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]Message, 0, len(rows))
+	for _, row := range rows {
+		messages = append(messages, messageFromDB(row))
+	}
+	return messages, nil
+}
+
+func (s *postgresStore) MarkConversationRead(ctx context.Context, conversationID, userID uuid.UUID, readAt time.Time) error {
+	n, err := s.q.MarkConversationRead(ctx, database.MarkConversationReadParams{
+		LastReadAt:     sql.NullTime{Time: readAt.UTC(), Valid: true},
+		ConversationID: conversationID,
+		UserID:         userID,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *postgresStore) GetDMPrivacy(ctx context.Context, userID uuid.UUID) (string, error) {
+	privacy, err := s.q.GetDMPrivacy(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return privacy, err
+}
+
+func (s *postgresStore) SetDMPrivacy(ctx context.Context, userID uuid.UUID, privacy string) error {
+	return s.q.SetDMPrivacy(ctx, database.SetDMPrivacyParams{UserID: userID, Privacy: privacy})
+}
+
+func (s *postgresStore) BlockUser(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	return s.q.BlockUser(ctx, database.BlockUserParams{
+		BlockerID: blockerID,
+		BlockedID: blockedID,
+		CreatedAt: time.Now().UTC(),
+	})
+}
+
+func (s *postgresStore) UnblockUser(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	n, err := s.q.UnblockUser(ctx, database.UnblockUserParams{BlockerID: blockerID, BlockedID: blockedID})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *postgresStore) ListBlocks(ctx context.Context, blockerID uuid.UUID) ([]Block, error) {
+	rows, err := s.q.ListBlocks(ctx, blockerID)
+	if err != nil {
+		return nil, err
+	}
+	blocks := make([]Block, 0, len(rows))
+	for _, row := range rows {
+		blocks = append(blocks, Block(row))
+	}
+	return blocks, nil
+}
+
+func (s *postgresStore) IsBlocked(ctx context.Context, a, b uuid.UUID) (bool, error) {
+	count, err := s.q.IsBlocked(ctx, database.IsBlockedParams{UserA: a, UserB: b})
+	return count > 0, err
+}
+
+func (s *postgresStore) MuteUser(ctx context.Context, mute Mute) error {
+	if mute.ExpiresAt.Valid {
+		mute.ExpiresAt.Time = mute.ExpiresAt.Time.UTC()
+	}
+	return s.q.MuteUser(ctx, database.MuteUserParams{
+		MuterID:   mute.MuterID,
+		MutedID:   mute.MutedID,
+		CreatedAt: time.Now().UTC(),
+		ExpiresAt: mute.ExpiresAt,
+	})
+}
+
+func (s *postgresStore) UnmuteUser(ctx context.Context, muterID, mutedID uuid.UUID) error {
+	n, err := s.q.UnmuteUser(ctx, database.UnmuteUserParams{MuterID: muterID, MutedID: mutedID})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *postgresStore) ListMutes(ctx context.Context, muterID uuid.UUID, now time.Time) ([]Mute, error) {
+	rows, err := s.q.ListMutes(ctx, database.ListMutesParams{MuterID: muterID, Now: now.UTC()})
+	if err != nil {
+		return nil, err
+	}
+	mutes := make([]Mute, 0, len(rows))
+	for _, row := range rows {
+		mutes = append(mutes, Mute(row))
+	}
+	return mutes, nil
+}
+
+func (s *postgresStore) HiddenUserIDs(ctx context.Context, viewerID uuid.UUID, now time.Time) ([]uuid.UUID, error) {
+	return s.q.HiddenUserIDs(ctx, database.HiddenUserIDsParams{ViewerID: viewerID, Now: now.UTC()})
+}
+
+func contentFilterFromDB(f database.ContentFilter) ContentFilter {
+	return ContentFilter{
+		ID:            f.ID,
+		CreatedAt:     f.CreatedAt,
+		UserID:        f.UserID,
+		Kind:          f.Kind,
+		Value:         f.Value,
+		Timeline:      f.ScopeTimeline,
+		Notifications: f.ScopeNotifications,
+		ExpiresAt:     f.ExpiresAt,
+	}
+}
+
+func (s *postgresStore) CreateContentFilter(ctx context.Context, filter ContentFilter) (ContentFilter, error) {
+	if filter.ExpiresAt.Valid {
+		filter.ExpiresAt.Time = filter.ExpiresAt.Time.UTC()
+	}
+	row, err := s.q.CreateContentFilter(ctx, database.CreateContentFilterParams{
+		ID:                 uuid.New(),
+		CreatedAt:          time.Now().UTC(),
+		UserID:             filter.UserID,
+		Kind:               filter.Kind,
+		Value:              filter.Value,
+		ScopeTimeline:      filter.Timeline,
+		ScopeNotifications: filter.Notifications,
+		ExpiresAt:          filter.ExpiresAt,
+	})
+	if err != nil {
+		return ContentFilter{}, err
+	}
+	return contentFilterFromDB(row), nil
+}
+
+func (s *postgresStore) ListContentFilters(ctx context.Context, userID uuid.UUID, now time.Time) ([]ContentFilter, error) {
+	rows, err := s.q.ListContentFilters(ctx, database.ListContentFiltersParams{UserID: userID, Now: now.UTC()})
+	if err != nil {
+		return nil, err
+	}
+	filters := make([]ContentFilter, 0, len(rows))
+	for _, row := range rows {
+		filters = append(filters, contentFilterFromDB(row))
+	}
+	return filters, nil
+}
+
+func (s *postgresStore) DeleteContentFilter(ctx context.Context, userID, id uuid.UUID) error {
+	n, err := s.q.DeleteContentFilter(ctx, database.DeleteContentFilterParams{ID: id, UserID: userID})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func profanityTermFromDB(t database.ProfanityTerm) ProfanityTerm {
+	return ProfanityTerm(t)
+}
+
+func (s *postgresStore) ListProfanityTerms(ctx context.Context) ([]ProfanityTerm, error) {
+	rows, err := s.q.ListProfanityTerms(ctx)
+	if err != nil {
+		return nil, err
+	}
+	terms := make([]ProfanityTerm, 0, len(rows))
+	for _, row := range rows {
+		terms = append(terms, profanityTermFromDB(row))
+	}
+	return terms, nil
+}
+
+func (s *postgresStore) CreateProfanityTerm(ctx context.Context, term, action string) (ProfanityTerm, error) {
+	now := time.Now().UTC()
+	row, err := s.q.CreateProfanityTerm(ctx, database.CreateProfanityTermParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Term:      term,
+		Action:    action,
+	})
+	if err != nil {
+		return ProfanityTerm{}, conflict(err)
+	}
+	return profanityTermFromDB(row), nil
+}
+
+func (s *postgresStore) UpdateProfanityTerm(ctx context.Context, id uuid.UUID, term, action string) (ProfanityTerm, error) {
+	row, err := s.q.UpdateProfanityTerm(ctx, database.UpdateProfanityTermParams{
+		Term:      term,
+		Action:    action,
+		UpdatedAt: time.Now().UTC(),
+		ID:        id,
+	})
+	if err != nil {
+		return ProfanityTerm{}, conflict(notFound(err))
+	}
+	return profanityTermFromDB(row), nil
+}
+
+func (s *postgresStore) DeleteProfanityTerm(ctx context.Context, id uuid.UUID) error {
+	n, err := s.q.DeleteProfanityTerm(ctx, id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func moderationCaseFromDB(c database.ModerationCase) ModerationCase {
+	res := ModerationCase{
+		ID:         c.ID,
+		CreatedAt:  c.CreatedAt,
+		UpdatedAt:  c.UpdatedAt,
+		ChirpID:    c.ChirpID,
+		AuthorID:   c.AuthorID,
+		Body:       c.Body,
+		Reports:    int(c.ReportCount),
+		ClaimedBy:  c.ClaimedBy,
+		ClaimedAt:  c.ClaimedAt,
+		Resolution: c.Resolution,
+		Note:       c.Note,
+		ResolvedBy: c.ResolvedBy,
+		ResolvedAt: c.ResolvedAt,
+	}
+	//the terms are kept as one comma separated column, terms can't have commas
+	if c.FlaggedTerms != "" {
+		res.FlaggedTerms = strings.Split(c.FlaggedTerms, ",")
+	}
+	return res
+}
+
+func (s *postgresStore) OpenModerationCase(ctx context.Context, chirp Chirp) (ModerationCase, error) {
+	row, err := s.q.OpenModerationCase(ctx, database.OpenModerationCaseParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		ChirpID:   chirp.ID,
+		AuthorID:  chirp.UserID,
+		Body:      chirp.Body,
+	})
+	if err != nil {
+		return ModerationCase{}, err
+	}
+	return moderationCaseFromDB(row), nil
+}
+
+func (s *postgresStore) AddReport(ctx context.Context, report Report) (ModerationCase, error) {
+	now := time.Now().UTC()
+	var row database.ModerationCase
+	err := s.inTx(ctx, func(q *database.Queries) error {
+		// holds the case until the report is counted so concurrent reports can't race the count
+		_, err := q.LockModerationCase(ctx, report.CaseID)
+		if err != nil {
+			return notFound(err)
+		}
+		err = q.CreateReport(ctx, database.CreateReportParams{
+			ID:         uuid.New(),
+			CreatedAt:  now,
+			CaseID:     report.CaseID,
+			ChirpID:    report.ChirpID,
+			ReporterID: report.ReporterID,
+			Reason:     report.Reason,
+			Comment:    report.Comment,
+		})
+		if err != nil {
+			return conflict(err)
+		}
+		row, err = q.CountCaseReport(ctx, database.CountCaseReportParams{UpdatedAt: now, ID: report.CaseID})
+		return notFound(err)
+	})
+	if err != nil {
+		return ModerationCase{}, err
+	}
+	return moderationCaseFromDB(row), nil
+}
+
+func (s *postgresStore) FlagModerationCase(ctx context.Context, id uuid.UUID, terms []string) (ModerationCase, error) {
+	row, err := s.q.FlagModerationCase(ctx, database.FlagModerationCaseParams{
+		FlaggedTerms: strings.Join(terms, ","),
+		UpdatedAt:    time.Now().UTC(),
+		ID:           id,
+	})
+	if err != nil {
+		return ModerationCase{}, notFound(err)
+	}
+	return moderationCaseFromDB(row), nil
+}
+
+func (s *postgresStore) GetModerationCase(ctx context.Context, id uuid.UUID) (ModerationCase, error) {
+	row, err := s.q.GetModerationCase(ctx, id)
+	if err != nil {
+		return ModerationCase{}, notFound(err)
+	}
+	return moderationCaseFromDB(row), nil
+}
+
+func (s *postgresStore) ListModerationCases(ctx context.Context, status string) ([]ModerationCase, error) {
+	rows, err := s.q.ListModerationCases(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+	cases := make([]ModerationCase, 0, len(rows))
+	for _, row := range rows {
+		cases = append(cases, moderationCaseFromDB(row))
+	}
+	return cases, nil
+}
+
+func (s *postgresStore) ListReports(ctx context.Context, caseID uuid.UUID) ([]Report, error) {
+	rows, err := s.q.ListReports(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	reports := make([]Report, 0, len(rows))
+	for _, row := range rows {
+		reports = append(reports, Report(row))
+	}
+	return reports, nil
+}
+
+func (s *postgresStore) ClaimModerationCase(ctx context.Context, id uuid.UUID, moderator string) (ModerationCase, error) {
+	row, err := s.q.ClaimModerationCase(ctx, database.ClaimModerationCaseParams{
+		Moderator: moderator,
+		Now:       time.Now().UTC(),
+		ID:        id,
+	})
+	if err != nil {
+		return ModerationCase{}, s.caseNotChanged(ctx, id, err)
+	}
+	return moderationCaseFromDB(row), nil
+}
+
+func (s *postgresStore) ResolveModerationCase(ctx context.Context, id uuid.UUID, moderator, resolution, note string) (ModerationCase, error) {
+	row, err := s.q.ResolveModerationCase(ctx, database.ResolveModerationCaseParams{
+		Resolution: resolution,
+		Note:       note,
+		Moderator:  moderator,
+		Now:        time.Now().UTC(),
+		ID:         id,
+	})
+	if err != nil {
+		return ModerationCase{}, s.caseNotChanged(ctx, id, err)
+	}
+	return moderationCaseFromDB(row), nil
+}
+
+// claiming and resolving only update cases they are allowed to,
+// when no row comes back the case is either missing or in the way
+func (s *postgresStore) caseNotChanged(ctx context.Context, id uuid.UUID, err error) error {
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	_, err = s.q.GetModerationCase(ctx, id)
+	if err != nil {
+		return notFound(err)
+	}
+	return fmt.Errorf("%w: the case is resolved or claimed by someone else", ErrConflict)
+}
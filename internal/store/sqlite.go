@@ -0,0 +1,956 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/christianrm0821/Chirpy/internal/database/sqlitedb"
+	"github.com/google/uuid"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// opens the sqlite database file at path with foreign keys turned on
+// sqlite only allows one writer so the pool is kept to a single connection
+func OpenSQLite(path string) (*sql.DB, error) {
+	if path == "" {
+		return nil, fmt.Errorf("sqlite needs a file path")
+	}
+	pragmas := url.Values{}
+	pragmas.Add("_pragma", "foreign_keys(1)")
+	pragmas.Add("_pragma", "busy_timeout(5000)")
+	pragmas.Add("_pragma", "journal_mode(WAL)")
+	db, err := sql.Open("sqlite", "file:"+path+"?"+pragmas.Encode())
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
+
+// adapts the sqlite sqlc queries to the Store interface
+// sqlite has no gen_random_uuid() so ids and timestamps are made here
+type sqliteStore struct {
+	db   *sql.DB
+	q    *sqlitedb.Queries
+	wrap func(sqlitedb.DBTX) sqlitedb.DBTX
+}
+
+// returns a Store backed by the sqlite sqlc queries on db
+// wrap is put around db and every transaction the queries run on (for tracing), nil leaves them as they are
+func NewSQLite(db *sql.DB, wrap func(sqlitedb.DBTX) sqlitedb.DBTX) Store {
+	if wrap == nil {
+		wrap = func(db sqlitedb.DBTX) sqlitedb.DBTX { return db }
+	}
+	return &sqliteStore{db: db, q: sqlitedb.New(wrap(db)), wrap: wrap}
+}
+
+// runs fn on queries in one transaction, which is rolled back when fn returns an error
+func (s *sqliteStore) inTx(ctx context.Context, fn func(q *sqlitedb.Queries) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	err = fn(sqlitedb.New(s.wrap(tx)))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// turns unique and primary key violations into ErrConflict
+func sqliteConflict(err error) error {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+			return fmt.Errorf("%w: %v", ErrConflict, err)
+		}
+	}
+	return err
+}
+
+func userFromSQLite(u sqlitedb.User) User {
+	return User{
+		ID:             u.ID,
+		CreatedAt:      u.CreatedAt,
+		UpdatedAt:      u.UpdatedAt,
+		Email:          u.Email,
+		HashedPassword: u.HashedPassword,
+		IsChirpyRed:    u.IsChirpyRed,
+		SuspendedAt:    u.SuspendedAt,
+		Role:           u.Role,
+		Handle:         u.Handle.String,
+	}
+}
+
+func chirpFromSQLite(c sqlitedb.Chirp) Chirp {
+	return Chirp{
+		ID:        c.ID,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+		Body:      c.Body,
+		UserID:    c.UserID,
+		HiddenAt:  c.HiddenAt,
+	}
+}
+
+func refreshTokenFromSQLite(t sqlitedb.RefreshToken) RefreshToken {
+	return RefreshToken{
+		Token:     t.Token,
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+		UserID:    t.UserID,
+		ExpiresAt: t.ExpiresAt,
+		RevokedAt: t.RevokedAt,
+	}
+}
+
+func (s *sqliteStore) CreateUser(ctx context.Context, email, hashedPassword string) (User, error) {
+	now := time.Now().UTC()
+	user, err := s.q.CreateUser(ctx, sqlitedb.CreateUserParams{
+		ID:             uuid.New(),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		Email:          email,
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		return User{}, sqliteConflict(err)
+	}
+	return userFromSQLite(user), nil
+}
+
+func (s *sqliteStore) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	user, err := s.q.GetUserByEmail(ctx, email)
+	if err != nil {
+		return User{}, notFound(err)
+	}
+	return userFromSQLite(user), nil
+}
+
+func (s *sqliteStore) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
+	user, err := s.q.GetUserFromID(ctx, id)
+	if err != nil {
+		return User{}, notFound(err)
+	}
+	return userFromSQLite(user), nil
+}
+
+func (s *sqliteStore) GetUserByHandle(ctx context.Context, handle string) (User, error) {
+	user, err := s.q.GetUserByHandle(ctx, sql.NullString{String: handle, Valid: true})
+	if err != nil {
+		return User{}, notFound(err)
+	}
+	return userFromSQLite(user), nil
+}
+
+func (s *sqliteStore) UpdateUserCredentials(ctx context.Context, id uuid.UUID, email, hashedPassword string) error {
+	err := s.q.UpdatePasswordEmailFromUserID(ctx, sqlitedb.UpdatePasswordEmailFromUserIDParams{
+		HashedPassword: hashedPassword,
+		Email:          email,
+		ID:             id,
+	})
+	return sqliteConflict(err)
+}
+
+func (s *sqliteStore) UpgradeUserToChirpyRed(ctx context.Context, id uuid.UUID) error {
+	return s.q.UpdateUserSubWithID(ctx, id)
+}
+
+func (s *sqliteStore) SetUserSuspended(ctx context.Context, id uuid.UUID, suspended bool) error {
+	n, err := s.q.SetUserSuspended(ctx, sqlitedb.SetUserSuspendedParams{
+		SuspendedAt: sql.NullTime{Time: time.Now().UTC(), Valid: suspended},
+		ID:          id,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *sqliteStore) SetUserRole(ctx context.Context, id uuid.UUID, role string) error {
+	n, err := s.q.SetUserRole(ctx, sqlitedb.SetUserRoleParams{Role: role, UpdatedAt: time.Now().UTC(), ID: id})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *sqliteStore) SetUserHandle(ctx context.Context, id uuid.UUID, handle string) error {
+	n, err := s.q.SetUserHandle(ctx, sqlitedb.SetUserHandleParams{
+		Handle:    sql.NullString{String: handle, Valid: handle != ""},
+		UpdatedAt: time.Now().UTC(),
+		ID:        id,
+	})
+	if err != nil {
+		return sqliteConflict(err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *sqliteStore) DeleteAllUsers(ctx context.Context) error {
+	return s.q.DeleteUsers(ctx)
+}
+
+func (s *sqliteStore) CreateChirp(ctx context.Context, userID uuid.UUID, body string) (Chirp, error) {
+	now := time.Now().UTC()
+	chirp, err := s.q.CreateChirp(ctx, sqlitedb.CreateChirpParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Body:      body,
+		UserID:    userID,
+	})
+	if err != nil {
+		return Chirp{}, err
+	}
+	return chirpFromSQLite(chirp), nil
+}
+
+func (s *sqliteStore) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	chirp, err := s.q.GetChirpWithID(ctx, id)
+	if err != nil {
+		return Chirp{}, notFound(err)
+	}
+	return chirpFromSQLite(chirp), nil
+}
+
+func (s *sqliteStore) GetChirps(ctx context.Context, ids []uuid.UUID) ([]Chirp, error) {
+	rows, err := s.q.GetChirpsWithIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	chirps := make([]Chirp, 0, len(rows))
+	for _, row := range rows {
+		chirps = append(chirps, chirpFromSQLite(row))
+	}
+	return chirps, nil
+}
+
+func (s *sqliteStore) ListChirps(ctx context.Context, params ListChirpsParams) ([]Chirp, error) {
+	var rows []sqlitedb.Chirp
+	var err error
+	switch {
+	case params.Limit > 0 || !params.After.IsZero() || params.ViewerID != uuid.Nil:
+		rows, err = s.listChirpsPage(ctx, params)
+	case params.AuthorID == uuid.Nil && params.Desc:
+		rows, err = s.q.GetAllChirpsDesc(ctx)
+	case params.AuthorID == uuid.Nil:
+		rows, err = s.q.GetAllChirps(ctx)
+	case params.Desc:
+		rows, err = s.q.GetAllChirpsFromIDDesc(ctx, params.AuthorID)
+	default:
+		rows, err = s.q.GetAllChirpsFromID(ctx, params.AuthorID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	chirps := make([]Chirp, 0, len(rows))
+	for _, row := range rows {
+		chirps = append(chirps, chirpFromSQLite(row))
+	}
+	return chirps, nil
+}
+
+// uses the keyset queries, they order by created_at then id so pages never skip or repeat a chirp
+func (s *sqliteStore) listChirpsPage(ctx context.Context, params ListChirpsParams) ([]sqlitedb.Chirp, error) {
+	pageSize := int64(math.MaxInt32)
+	if params.Limit > 0 {
+		pageSize = int64(params.Limit)
+	}
+	arg := sqlitedb.ListChirpsPageParams{
+		AuthorID: uuid.NullUUID{UUID: params.AuthorID, Valid: params.AuthorID != uuid.Nil},
+		ViewerID: uuid.NullUUID{UUID: params.ViewerID, Valid: params.ViewerID != uuid.Nil},
+		Now:      sql.NullTime{Time: time.Now().UTC(), Valid: true},
+		PageSize: pageSize,
+	}
+	if !params.After.IsZero() {
+		arg.AfterCreatedAt = sql.NullTime{Time: params.After.CreatedAt.UTC(), Valid: true}
+		arg.AfterID = params.After.ID
+	}
+	if params.Desc {
+		return s.q.ListChirpsPageDesc(ctx, sqlitedb.ListChirpsPageDescParams(arg))
+	}
+	return s.q.ListChirpsPage(ctx, arg)
+}
+
+func (s *sqliteStore) DeleteChirp(ctx context.Context, id uuid.UUID) error {
+	return s.q.DeleteChirpWithID(ctx, id)
+}
+
+func (s *sqliteStore) SetChirpHidden(ctx context.Context, id uuid.UUID, hidden bool) error {
+	n, err := s.q.SetChirpHidden(ctx, sqlitedb.SetChirpHiddenParams{
+		HiddenAt: sql.NullTime{Time: time.Now().UTC(), Valid: hidden},
+		ID:       id,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *sqliteStore) CreateRefreshToken(ctx context.Context, token RefreshToken) (RefreshToken, error) {
+	row, err := s.q.CreateRefreshToken(ctx, sqlitedb.CreateRefreshTokenParams{
+		Token:     token.Token,
+		CreatedAt: token.CreatedAt.UTC(),
+		UpdatedAt: token.UpdatedAt.UTC(),
+		UserID:    token.UserID,
+		ExpiresAt: token.ExpiresAt.UTC(),
+		RevokedAt: token.RevokedAt,
+	})
+	if err != nil {
+		return RefreshToken{}, sqliteConflict(err)
+	}
+	return refreshTokenFromSQLite(row), nil
+}
+
+func (s *sqliteStore) GetRefreshToken(ctx context.Context, token string) (RefreshToken, error) {
+	row, err := s.q.GetUserFromRefreshToken(ctx, token)
+	if err != nil {
+		return RefreshToken{}, notFound(err)
+	}
+	return refreshTokenFromSQLite(row), nil
+}
+
+func (s *sqliteStore) RevokeRefreshToken(ctx context.Context, token string, revokedAt time.Time) error {
+	return s.q.RevokeRefreshToken(ctx, sqlitedb.RevokeRefreshTokenParams{
+		RevokedAt: sql.NullTime{Time: revokedAt.UTC(), Valid: true},
+		UpdatedAt: revokedAt.UTC(),
+		Token:     token,
+	})
+}
+
+func notificationFromSQLite(n sqlitedb.Notification) Notification {
+	return Notification{
+		ID:        n.ID,
+		CreatedAt: n.CreatedAt,
+		UpdatedAt: n.UpdatedAt,
+		UserID:    n.UserID,
+		Type:      n.Type,
+		ActorID:   n.ActorID,
+		ChirpID:   n.ChirpID,
+		ReadAt:    n.ReadAt,
+	}
+}
+
+func (s *sqliteStore) AddNotification(ctx context.Context, n Notification) (Notification, error) {
+	now := time.Now().UTC()
+	row, err := s.q.CreateNotification(ctx, sqlitedb.CreateNotificationParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UserID:    n.UserID,
+		Type:      n.Type,
+		ActorID:   n.ActorID,
+		ChirpID:   n.ChirpID,
+	})
+	if err != nil {
+		return Notification{}, err
+	}
+	return notificationFromSQLite(row), nil
+}
+
+func (s *sqliteStore) ListNotifications(ctx context.Context, userID uuid.UUID, params ListNotificationsParams) ([]Notification, error) {
+	pageSize := int64(math.MaxInt32)
+	if params.Limit > 0 {
+		pageSize = int64(params.Limit)
+	}
+	arg := sqlitedb.ListNotificationsParams{UserID: userID, PageSize: pageSize}
+	if !params.After.IsZero() {
+		arg.AfterUpdatedAt = sql.NullTime{Time: params.After.UpdatedAt.UTC(), Valid: true}
+		arg.AfterID = params.After.ID
+	}
+	var rows []sqlitedb.Notification
+	var err error
+	if params.UnreadOnly {
+		rows, err = s.q.ListUnreadNotifications(ctx, sqlitedb.ListUnreadNotificationsParams(arg))
+	} else {
+		rows, err = s.q.ListNotifications(ctx, arg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	notifications := make([]Notification, 0, len(rows))
+	for _, row := range rows {
+		notifications = append(notifications, notificationFromSQLite(row))
+	}
+	return notifications, nil
+}
+
+func (s *sqliteStore) CountUnreadNotifications(ctx context.Context, userID uuid.UUID) (int, error) {
+	count, err := s.q.CountUnreadNotifications(ctx, userID)
+	return int(count), err
+}
+
+func (s *sqliteStore) MarkNotificationRead(ctx context.Context, userID, id uuid.UUID, readAt time.Time) error {
+	n, err := s.q.MarkNotificationRead(ctx, sqlitedb.MarkNotificationReadParams{
+		ReadAt: sql.NullTime{Time: readAt.UTC(), Valid: true},
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *sqliteStore) MarkAllNotificationsRead(ctx context.Context, userID uuid.UUID, readAt time.Time) error {
+	return s.q.MarkAllNotificationsRead(ctx, sqlitedb.MarkAllNotificationsReadParams{
+		ReadAt: sql.NullTime{Time: readAt.UTC(), Valid: true},
+		UserID: userID,
+	})
+}
+
+func (s *sqliteStore) GetNotificationPreferences(ctx context.Context, userID uuid.UUID) (map[string]bool, error) {
+	rows, err := s.q.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	prefs := map[string]bool{}
+	for _, row := range rows {
+		prefs[row.Type] = row.Enabled
+	}
+	return prefs, nil
+}
+
+func (s *sqliteStore) SetNotificationPreference(ctx context.Context, userID uuid.UUID, notificationType string, enabled bool) error {
+	return s.q.SetNotificationPreference(ctx, sqlitedb.SetNotificationPreferenceParams{
+		UserID:  userID,
+		Type:    notificationType,
+		Enabled: enabled,
+	})
+}
+
+func conversationFromSQLite(c sqlitedb.Conversation, members []sqlitedb.ConversationMember) Conversation {
+	conversation := Conversation{
+		ID:        c.ID,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+		CreatedBy: c.CreatedBy,
+	}
+	for _, m := range members {
+		if m.ConversationID != c.ID {
+			continue
+		}
+		conversation.Members = append(conversation.Members, ConversationMember{
+			UserID:     m.UserID,
+			JoinedAt:   m.JoinedAt,
+			LastReadAt: m.LastReadAt,
+		})
+	}
+	return conversation
+}
+
+func messageFromSQLite(m sqlitedb.Message) Message {
+	return Message{
+		ID:             m.ID,
+		CreatedAt:      m.CreatedAt,
+		ConversationID: m.ConversationID,
+		SenderID:       m.SenderID,
+		Body:           m.Body,
+	}
+}
+
+func (s *sqliteStore) CreateConversation(ctx context.Context, createdBy uuid.UUID, memberIDs []uuid.UUID) (Conversation, error) {
+	now := time.Now().UTC()
+	var row sqlitedb.Conversation
+	err := s.inTx(ctx, func(q *sqlitedb.Queries) error {
+		var err error
+		row, err = q.CreateConversation(ctx, sqlitedb.CreateConversationParams{
+			ID:        uuid.New(),
+			CreatedAt: now,
+			CreatedBy: createdBy,
+		})
+		if err != nil {
+			return err
+		}
+		for _, userID := range memberIDs {
+			err := q.AddConversationMember(ctx, sqlitedb.AddConversationMemberParams{
+				ConversationID: row.ID,
+				UserID:         userID,
+				JoinedAt:       now,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return Conversation{}, err
+	}
+	return s.conversationWithMembers(ctx, row)
+}
+
+func (s *sqliteStore) conversationWithMembers(ctx context.Context, row sqlitedb.Conversation) (Conversation, error) {
+	members, err := s.q.ListConversationMembers(ctx, row.ID)
+	if err != nil {
+		return Conversation{}, err
+	}
+	return conversationFromSQLite(row, members), nil
+}
+
+func (s *sqliteStore) GetConversation(ctx context.Context, id uuid.UUID) (Conversation, error) {
+	row, err := s.q.GetConversation(ctx, id)
+	if err != nil {
+		return Conversation{}, notFound(err)
+	}
+	return s.conversationWithMembers(ctx, row)
+}
+
+func (s *sqliteStore) FindDirectConversation(ctx context.Context, a, b uuid.UUID) (Conversation, error) {
+	row, err := s.q.FindDirectConversation(ctx, sqlitedb.FindDirectConversationParams{UserA: a, UserB: b})
+	if err != nil {
+		return Conversation{}, notFound(err)
+	}
+	return s.conversationWithMembers(ctx, row)
+}
+
+func (s *sqliteStore) ListConversations(ctx context.Context, userID uuid.UUID) ([]Conversation, error) {
+	rows, err := s.q.ListConversations(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.q.ListMembersOfUserConversations(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	conversations := make([]Conversation, 0, len(rows))
+	for _, row := range rows {
+		conversations = append(conversations, conversationFromSQLite(row, members))
+	}
+	return conversations, nil
+}
+
+func (s *sqliteStore) CreateMessage(ctx context.Context, conversationID, senderID uuid.UUID, body string) (Message, error) {
+	var row sqlitedb.Message
+	err := s.inTx(ctx, func(q *sqlitedb.Queries) error {
+		var err error
+		row, err = q.CreateMessage(ctx, sqlitedb.CreateMessageParams{
+			ID:             uuid.New(),
+			CreatedAt:      time.Now().UTC(),
+			ConversationID: conversationID,
+			SenderID:       senderID,
+			Body:           body,
+		})
+		if err != nil {
+			return err
+		}
+		err = q.TouchConversation(ctx, sqlitedb.TouchConversationParams{UpdatedAt: row.CreatedAt, ID: conversationID})
+		if err != nil {
+			return err
+		}
+		//the sender has read everything up to their own message
+		_, err = q.MarkConversationRead(ctx, sqlitedb.MarkConversationReadParams{
+			LastReadAt:     sql.NullTime{Time: row.CreatedAt, Valid: true},
+			ConversationID: conversationID,
+			UserID:         senderID,
+		})
+		return err
+	})
+	if err != nil {
+		return Message{}, err
+	}
+	return messageFromSQLite(row), nil
+}
+
+func (s *sqliteStore) ListMessages(ctx context.Context, conversationID uuid.UUID, params ListMessagesParams) ([]Message, error) {
+	pageSize := int64(math.MaxInt32)
+	if params.Limit > 0 {
+		pageSize = int64(params.Limit)
+	}
+	arg := sqlitedb.ListMessagesParams{ConversationID: conversationID, PageSize: pageSize}
+	if !params.After.IsZero() {
+		arg.AfterCreatedAt = sql.NullTime{Time: params.After.CreatedAt.UTC(), Valid: true}
+		arg.AfterID = params.After.ID
+	}
+	rows, err := s.q.ListMessages(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]Message, 0, len(rows))
+	for _, row := range rows {
+		messages = append(messages, messageFromSQLite(row))
+	}
+	return messages, nil
+}
+
+func (s *sqliteStore) MarkConversationRead(ctx context.Context, conversationID, userID uuid.UUID, readAt time.Time) error {
+	n, err := s.q.MarkConversationRead(ctx, sqlitedb.MarkConversationReadParams{
+		LastReadAt:     sql.NullTime{Time: readAt.UTC(), Valid: true},
+		ConversationID: conversationID,
+		UserID:         userID,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *sqliteStore) GetDMPrivacy(ctx context.Context, userID uuid.UUID) (string, error) {
+	privacy, err := s.q.GetDMPrivacy(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return privacy, err
+}
+
+func (s *sqliteStore) SetDMPrivacy(ctx context.Context, userID uuid.UUID, privacy string) error {
+	return s.q.SetDMPrivacy(ctx, sqlitedb.SetDMPrivacyParams{UserID: userID, Privacy: privacy})
+}
+
+func (s *sqliteStore) BlockUser(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	return s.q.BlockUser(ctx, sqlitedb.BlockUserParams{
+		BlockerID: blockerID,
+		BlockedID: blockedID,
+		CreatedAt: time.Now().UTC(),
+	})
+}
+
+func (s *sqliteStore) UnblockUser(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	n, err := s.q.UnblockUser(ctx, sqlitedb.UnblockUserParams{BlockerID: blockerID, BlockedID: blockedID})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *sqliteStore) ListBlocks(ctx context.Context, blockerID uuid.UUID) ([]Block, error) {
+	rows, err := s.q.ListBlocks(ctx, blockerID)
+	if err != nil {
+		return nil, err
+	}
+	blocks := make([]Block, 0, len(rows))
+	for _, row := range rows {
+		blocks = append(blocks, Block(row))
+	}
+	return blocks, nil
+}
+
+func (s *sqliteStore) IsBlocked(ctx context.Context, a, b uuid.UUID) (bool, error) {
+	count, err := s.q.IsBlocked(ctx, sqlitedb.IsBlockedParams{UserA: a, UserB: b})
+	return count > 0, err
+}
+
+func (s *sqliteStore) MuteUser(ctx context.Context, mute Mute) error {
+	if mute.ExpiresAt.Valid {
+		mute.ExpiresAt.Time = mute.ExpiresAt.Time.UTC()
+	}
+	return s.q.MuteUser(ctx, sqlitedb.MuteUserParams{
+		MuterID:   mute.MuterID,
+		MutedID:   mute.MutedID,
+		CreatedAt: time.Now().UTC(),
+		ExpiresAt: mute.ExpiresAt,
+	})
+}
+
+func (s *sqliteStore) UnmuteUser(ctx context.Context, muterID, mutedID uuid.UUID) error {
+	n, err := s.q.UnmuteUser(ctx, sqlitedb.UnmuteUserParams{MuterID: muterID, MutedID: mutedID})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *sqliteStore) ListMutes(ctx context.Context, muterID uuid.UUID, now time.Time) ([]Mute, error) {
+	rows, err := s.q.ListMutes(ctx, sqlitedb.ListMutesParams{MuterID: muterID, Now: sql.NullTime{Time: now.UTC(), Valid: true}})
+	if err != nil {
+		return nil, err
+	}
+	mutes := make([]Mute, 0, len(rows))
+	for _, row := range rows {
+		mutes = append(mutes, Mute(row))
+	}
+	return mutes, nil
+}
+
+func (s *sqliteStore) HiddenUserIDs(ctx context.Context, viewerID uuid.UUID, now time.Time) ([]uuid.UUID, error) {
+	return s.q.HiddenUserIDs(ctx, sqlitedb.HiddenUserIDsParams{ViewerID: viewerID, Now: sql.NullTime{Time: now.UTC(), Valid: true}})
+}
+
+func contentFilterFromSQLite(f sqlitedb.ContentFilter) ContentFilter {
+	return ContentFilter{
+		ID:            f.ID,
+		CreatedAt:     f.CreatedAt,
+		UserID:        f.UserID,
+		Kind:          f.Kind,
+		Value:         f.Value,
+		Timeline:      f.ScopeTimeline,
+		Notifications: f.ScopeNotifications,
+		ExpiresAt:     f.ExpiresAt,
+	}
+}
+
+func (s *sqliteStore) CreateContentFilter(ctx context.Context, filter ContentFilter) (ContentFilter, error) {
+	if filter.ExpiresAt.Valid {
+		filter.ExpiresAt.Time = filter.ExpiresAt.Time.UTC()
+	}
+	row, err := s.q.CreateContentFilter(ctx, sqlitedb.CreateContentFilterParams{
+		ID:                 uuid.New(),
+		CreatedAt:          time.Now().UTC(),
+		UserID:             filter.UserID,
+		Kind:               filter.Kind,
+		Value:              filter.Value,
+		ScopeTimeline:      filter.Timeline,
+		ScopeNotifications: filter.Notifications,
+		ExpiresAt:          filter.ExpiresAt,
+	})
+	if err != nil {
+		return ContentFilter{}, err
+	}
+	return contentFilterFromSQLite(row), nil
+}
+
+func (s *sqliteStore) ListContentFilters(ctx context.Context, userID uuid.UUID, now time.Time) ([]ContentFilter, error) {
+	rows, err := s.q.ListContentFilters(ctx, sqlitedb.ListContentFiltersParams{UserID: userID, Now: sql.NullTime{Time: now.UTC(), Valid: true}})
+	if err != nil {
+		return nil, err
+	}
+	filters := make([]ContentFilter, 0, len(rows))
+	for _, row := range rows {
+		filters = append(filters, contentFilterFromSQLite(row))
+	}
+	return filters, nil
+}
+
+func (s *sqliteStore) DeleteContentFilter(ctx context.Context, userID, id uuid.UUID) error {
+	n, err := s.q.DeleteContentFilter(ctx, sqlitedb.DeleteContentFilterParams{ID: id, UserID: userID})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func profanityTermFromSQLite(t sqlitedb.ProfanityTerm) ProfanityTerm {
+	return ProfanityTerm(t)
+}
+
+func (s *sqliteStore) ListProfanityTerms(ctx context.Context) ([]ProfanityTerm, error) {
+	rows, err := s.q.ListProfanityTerms(ctx)
+	if err != nil {
+		return nil, err
+	}
+	terms := make([]ProfanityTerm, 0, len(rows))
+	for _, row := range rows {
+		terms = append(terms, profanityTermFromSQLite(row))
+	}
+	return terms, nil
+}
+
+func (s *sqliteStore) CreateProfanityTerm(ctx context.Context, term, action string) (ProfanityTerm, error) {
+	now := time.Now().UTC()
+	row, err := s.q.CreateProfanityTerm(ctx, sqlitedb.CreateProfanityTermParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Term:      term,
+		Action:    action,
+	})
+	if err != nil {
+		return ProfanityTerm{}, sqliteConflict(err)
+	}
+	return profanityTermFromSQLite(row), nil
+}
+
+func (s *sqliteStore) UpdateProfanityTerm(ctx context.Context, id uuid.UUID, term, action string) (ProfanityTerm, error) {
+	row, err := s.q.UpdateProfanityTerm(ctx, sqlitedb.UpdateProfanityTermParams{
+		Term:      term,
+		Action:    action,
+		UpdatedAt: time.Now().UTC(),
+		ID:        id,
+	})
+	if err != nil {
+		return ProfanityTerm{}, sqliteConflict(notFound(err))
+	}
+	return profanityTermFromSQLite(row), nil
+}
+
+func (s *sqliteStore) DeleteProfanityTerm(ctx context.Context, id uuid.UUID) error {
+	n, err := s.q.DeleteProfanityTerm(ctx, id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func moderationCaseFromSQLite(c sqlitedb.ModerationCase) ModerationCase {
+	res := ModerationCase{
+		ID:         c.ID,
+		CreatedAt:  c.CreatedAt,
+		UpdatedAt:  c.UpdatedAt,
+		ChirpID:    c.ChirpID,
+		AuthorID:   c.AuthorID,
+		Body:       c.Body,
+		Reports:    int(c.ReportCount),
+		ClaimedBy:  c.ClaimedBy,
+		ClaimedAt:  c.ClaimedAt,
+		Resolution: c.Resolution,
+		Note:       c.Note,
+		ResolvedBy: c.ResolvedBy,
+		ResolvedAt: c.ResolvedAt,
+	}
+	//the terms are kept as one comma separated column, terms can't have commas
+	if c.FlaggedTerms != "" {
+		res.FlaggedTerms = strings.Split(c.FlaggedTerms, ",")
+	}
+	return res
+}
+
+func (s *sqliteStore) OpenModerationCase(ctx context.Context, chirp Chirp) (ModerationCase, error) {
+	row, err := s.q.OpenModerationCase(ctx, sqlitedb.OpenModerationCaseParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		ChirpID:   chirp.ID,
+		AuthorID:  chirp.UserID,
+		Body:      chirp.Body,
+	})
+	if err != nil {
+		return ModerationCase{}, err
+	}
+	return moderationCaseFromSQLite(row), nil
+}
+
+func (s *sqliteStore) AddReport(ctx context.Context, report Report) (ModerationCase, error) {
+	now := time.Now().UTC()
+	var row sqlitedb.ModerationCase
+	err := s.inTx(ctx, func(q *sqlitedb.Queries) error {
+		err := q.CreateReport(ctx, sqlitedb.CreateReportParams{
+			ID:         uuid.New(),
+			CreatedAt:  now,
+			CaseID:     report.CaseID,
+			ChirpID:    report.ChirpID,
+			ReporterID: report.ReporterID,
+			Reason:     report.Reason,
+			Comment:    report.Comment,
+		})
+		if err != nil {
+			return sqliteConflict(err)
+		}
+		row, err = q.CountCaseReport(ctx, sqlitedb.CountCaseReportParams{UpdatedAt: now, ID: report.CaseID})
+		return notFound(err)
+	})
+	if err != nil {
+		return ModerationCase{}, err
+	}
+	return moderationCaseFromSQLite(row), nil
+}
+
+func (s *sqliteStore) FlagModerationCase(ctx context.Context, id uuid.UUID, terms []string) (ModerationCase, error) {
+	row, err := s.q.FlagModerationCase(ctx, sqlitedb.FlagModerationCaseParams{
+		FlaggedTerms: strings.Join(terms, ","),
+		UpdatedAt:    time.Now().UTC(),
+		ID:           id,
+	})
+	if err != nil {
+		return ModerationCase{}, notFound(err)
+	}
+	return moderationCaseFromSQLite(row), nil
+}
+
+func (s *sqliteStore) GetModerationCase(ctx context.Context, id uuid.UUID) (ModerationCase, error) {
+	row, err := s.q.GetModerationCase(ctx, id)
+	if err != nil {
+		return ModerationCase{}, notFound(err)
+	}
+	return moderationCaseFromSQLite(row), nil
+}
+
+func (s *sqliteStore) ListModerationCases(ctx context.Context, status string) ([]ModerationCase, error) {
+	rows, err := s.q.ListModerationCases(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+	cases := make([]ModerationCase, 0, len(rows))
+	for _, row := range rows {
+		cases = append(cases, moderationCaseFromSQLite(row))
+	}
+	return cases, nil
+}
+
+func (s *sqliteStore) ListReports(ctx context.Context, caseID uuid.UUID) ([]Report, error) {
+	rows, err := s.q.ListReports(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	reports := make([]Report, 0, len(rows))
+	for _, row := range rows {
+		reports = append(reports, Report(row))
+	}
+	return reports, nil
+}
+
+func (s *sqliteStore) ClaimModerationCase(ctx context.Context, id uuid.UUID, moderator string) (ModerationCase, error) {
+	row, err := s.q.ClaimModerationCase(ctx, sqlitedb.ClaimModerationCaseParams{
+		Moderator: moderator,
+		Now:       time.Now().UTC(),
+		ID:        id,
+	})
+	if err != nil {
+		return ModerationCase{}, s.caseNotChanged(ctx, id, err)
+	}
+	return moderationCaseFromSQLite(row), nil
+}
+
+func (s *sqliteStore) ResolveModerationCase(ctx context.Context, id uuid.UUID, moderator, resolution, note string) (ModerationCase, error) {
+	row, err := s.q.ResolveModerationCase(ctx, sqlitedb.ResolveModerationCaseParams{
+		Resolution: resolution,
+		Note:       note,
+		Moderator:  moderator,
+		Now:        time.Now().UTC(),
+		ID:         id,
+	})
+	if err != nil {
+		return ModerationCase{}, s.caseNotChanged(ctx, id, err)
+	}
+	return moderationCaseFromSQLite(row), nil
+}
+
+// claiming and resolving only update cases they are allowed to,
+// when no row comes back the case is either missing or in the way
+func (s *sqliteStore) caseNotChanged(ctx context.Context, id uuid.UUID, err error) error {
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	_, err = s.q.GetModerationCase(ctx, id)
+	if err != nil {
+		return notFound(err)
+	}
+	return fmt.Errorf("%w: the case is resolved or claimed by someone else", ErrConflict)
+}
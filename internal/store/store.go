@@ -0,0 +1,391 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// returned when the row being looked up does not exist
+var ErrNotFound = errors.New("not found")
+
+// returned when a write would break a unique constraint, like two users with the same email
+var ErrConflict = errors.New("already exists")
+
+type User struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Email          string
+	HashedPassword string
+	IsChirpyRed    bool
+	// suspended users can't log in or post, set by moderators
+	SuspendedAt sql.NullTime
+	// RoleUser, RoleModerator or RoleAdmin
+	Role string
+	// what others mention the user by, lowercase and empty until the user picks one
+	Handle string
+}
+
+// what a user is allowed to do, every user starts as RoleUser
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+type Chirp struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Body      string
+	UserID    uuid.UUID
+	// hidden chirps are only shown to their author, set by moderators or enough reports
+	HiddenAt sql.NullTime
+}
+
+type RefreshToken struct {
+	Token     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	ExpiresAt time.Time
+	RevokedAt sql.NullTime
+}
+
+type UserStore interface {
+	CreateUser(ctx context.Context, email, hashedPassword string) (User, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserByID(ctx context.Context, id uuid.UUID) (User, error)
+	// returns ErrNotFound if nobody has the handle
+	GetUserByHandle(ctx context.Context, handle string) (User, error)
+	// changes the email and password of the user, does nothing if the user does not exist
+	UpdateUserCredentials(ctx context.Context, id uuid.UUID, email, hashedPassword string) error
+	// sets is_chirpy_red, does nothing if the user does not exist
+	UpgradeUserToChirpyRed(ctx context.Context, id uuid.UUID) error
+	// sets or clears suspended_at, returns ErrNotFound if the user does not exist
+	SetUserSuspended(ctx context.Context, id uuid.UUID, suspended bool) error
+	// returns ErrNotFound if the user does not exist
+	SetUserRole(ctx context.Context, id uuid.UUID, role string) error
+	// an empty handle removes it, returns ErrConflict if someone else has it
+	// and ErrNotFound if the user does not exist
+	SetUserHandle(ctx context.Context, id uuid.UUID, handle string) error
+	// removes every user along with their chirps and refresh tokens
+	DeleteAllUsers(ctx context.Context) error
+}
+
+// filters for listing chirps, a Nil AuthorID lists chirps from everyone
+type ListChirpsParams struct {
+	AuthorID uuid.UUID
+	Desc     bool
+	// leaves out chirps from users the viewer blocked or was blocked by,
+	// and from users the viewer muted when AuthorID is Nil (mutes only hide people from timelines)
+	// hidden chirps are always left out, unless the viewer wrote them
+	ViewerID uuid.UUID
+	// only chirps that come after this one in the sort order, the zero value starts at the beginning
+	After ChirpCursor
+	// max number of chirps returned, 0 means no limit
+	Limit int
+}
+
+// position of a chirp in the created_at, id ordering, used for keyset pagination
+type ChirpCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+func (c ChirpCursor) IsZero() bool {
+	return c.CreatedAt.IsZero() && c.ID == uuid.Nil
+}
+
+// the cursor pointing at the given chirp
+func CursorFor(chirp Chirp) ChirpCursor {
+	return ChirpCursor{CreatedAt: chirp.CreatedAt, ID: chirp.ID}
+}
+
+type ChirpStore interface {
+	CreateChirp(ctx context.Context, userID uuid.UUID, body string) (Chirp, error)
+	GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error)
+	// the chirps of ids that exist, in no particular order
+	GetChirps(ctx context.Context, ids []uuid.UUID) ([]Chirp, error)
+	// chirps ordered by created_at
+	ListChirps(ctx context.Context, params ListChirpsParams) ([]Chirp, error)
+	// does nothing if the chirp does not exist
+	DeleteChirp(ctx context.Context, id uuid.UUID) error
+	// sets or clears hidden_at, returns ErrNotFound if the chirp does not exist
+	SetChirpHidden(ctx context.Context, id uuid.UUID, hidden bool) error
+}
+
+type RefreshTokenStore interface {
+	CreateRefreshToken(ctx context.Context, token RefreshToken) (RefreshToken, error)
+	GetRefreshToken(ctx context.Context, token string) (RefreshToken, error)
+	// sets revoked_at and updated_at to the given time
+	RevokeRefreshToken(ctx context.Context, token string, revokedAt time.Time) error
+}
+
+// something that happened that a user should know about
+type Notification struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	Type      string
+	ActorID   uuid.NullUUID
+	ChirpID   uuid.NullUUID
+	ReadAt    sql.NullTime
+}
+
+// filters for listing notifications, newest activity first
+type ListNotificationsParams struct {
+	UnreadOnly bool
+	// only notifications after this one, the zero value starts at the newest
+	After NotificationCursor
+	// max number of notifications returned, 0 means no limit
+	Limit int
+}
+
+// position of a notification in the updated_at desc, id desc ordering
+type NotificationCursor struct {
+	UpdatedAt time.Time
+	ID        uuid.UUID
+}
+
+func (c NotificationCursor) IsZero() bool {
+	return c.UpdatedAt.IsZero() && c.ID == uuid.Nil
+}
+
+type NotificationStore interface {
+	// saves n and returns it with its id and timestamps
+	AddNotification(ctx context.Context, n Notification) (Notification, error)
+	ListNotifications(ctx context.Context, userID uuid.UUID, params ListNotificationsParams) ([]Notification, error)
+	CountUnreadNotifications(ctx context.Context, userID uuid.UUID) (int, error)
+	// returns ErrNotFound if the notification does not exist or belongs to someone else
+	MarkNotificationRead(ctx context.Context, userID, id uuid.UUID, readAt time.Time) error
+	MarkAllNotificationsRead(ctx context.Context, userID uuid.UUID, readAt time.Time) error
+	// the types the user has turned on or off, types that were never set are missing
+	GetNotificationPreferences(ctx context.Context, userID uuid.UUID) (map[string]bool, error)
+	SetNotificationPreference(ctx context.Context, userID uuid.UUID, notificationType string, enabled bool) error
+}
+
+// a private conversation between two or more users
+type Conversation struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	// when the last message was sent
+	UpdatedAt time.Time
+	CreatedBy uuid.UUID
+	Members   []ConversationMember
+}
+
+type ConversationMember struct {
+	UserID   uuid.UUID
+	JoinedAt time.Time
+	// the member has read every message sent up to this time
+	LastReadAt sql.NullTime
+}
+
+type Message struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	ConversationID uuid.UUID
+	SenderID       uuid.UUID
+	Body           string
+}
+
+// filters for listing messages, newest first
+type ListMessagesParams struct {
+	// only messages older than this one, the zero value starts at the newest
+	After MessageCursor
+	// max number of messages returned, 0 means no limit
+	Limit int
+}
+
+// position of a message in the created_at desc, id desc ordering
+type MessageCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+func (c MessageCursor) IsZero() bool {
+	return c.CreatedAt.IsZero() && c.ID == uuid.Nil
+}
+
+type MessageStore interface {
+	// creates a conversation between memberIDs, createdBy should be one of them
+	CreateConversation(ctx context.Context, createdBy uuid.UUID, memberIDs []uuid.UUID) (Conversation, error)
+	GetConversation(ctx context.Context, id uuid.UUID) (Conversation, error)
+	// the oldest conversation that has exactly these two members, ErrNotFound if there is none
+	FindDirectConversation(ctx context.Context, a, b uuid.UUID) (Conversation, error)
+	// the user's conversations, the one with the newest message first
+	ListConversations(ctx context.Context, userID uuid.UUID) ([]Conversation, error)
+	// saves the message, moves the conversation to the top and marks it read for the sender
+	CreateMessage(ctx context.Context, conversationID, senderID uuid.UUID, body string) (Message, error)
+	ListMessages(ctx context.Context, conversationID uuid.UUID, params ListMessagesParams) ([]Message, error)
+	// returns ErrNotFound if the user is not a member of the conversation
+	MarkConversationRead(ctx context.Context, conversationID, userID uuid.UUID, readAt time.Time) error
+	// who can start a conversation with the user, "" if they never changed it
+	GetDMPrivacy(ctx context.Context, userID uuid.UUID) (string, error)
+	SetDMPrivacy(ctx context.Context, userID uuid.UUID, privacy string) error
+}
+
+// blocked users can't see or contact each other
+type Block struct {
+	BlockerID uuid.UUID
+	BlockedID uuid.UUID
+	CreatedAt time.Time
+}
+
+// muted users are hidden from the muter's timelines, until ExpiresAt when it is set
+type Mute struct {
+	MuterID   uuid.UUID
+	MutedID   uuid.UUID
+	CreatedAt time.Time
+	ExpiresAt sql.NullTime
+}
+
+type BlockStore interface {
+	// does nothing if the user is already blocked
+	BlockUser(ctx context.Context, blockerID, blockedID uuid.UUID) error
+	// returns ErrNotFound if the user was not blocked
+	UnblockUser(ctx context.Context, blockerID, blockedID uuid.UUID) error
+	// the users blockerID blocked, newest first
+	ListBlocks(ctx context.Context, blockerID uuid.UUID) ([]Block, error)
+	// reports if either user blocked the other
+	IsBlocked(ctx context.Context, a, b uuid.UUID) (bool, error)
+	// mutes the user or changes the expiry of an existing mute
+	MuteUser(ctx context.Context, mute Mute) error
+	// returns ErrNotFound if the user was not muted
+	UnmuteUser(ctx context.Context, muterID, mutedID uuid.UUID) error
+	// the mutes of muterID that haven't expired at now, newest first
+	ListMutes(ctx context.Context, muterID uuid.UUID, now time.Time) ([]Mute, error)
+	// the users whose chirps viewerID doesn't see in timelines at now: blocked either way or muted
+	HiddenUserIDs(ctx context.Context, viewerID uuid.UUID, now time.Time) ([]uuid.UUID, error)
+}
+
+// a word, phrase, hashtag or regex a user doesn't want to see in the scopes it is on
+type ContentFilter struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UserID        uuid.UUID
+	Kind          string
+	Value         string
+	Timeline      bool
+	Notifications bool
+	ExpiresAt     sql.NullTime
+}
+
+type ContentFilterStore interface {
+	// only the fields the caller decides are used (user, kind, value, scopes and expiry)
+	CreateContentFilter(ctx context.Context, filter ContentFilter) (ContentFilter, error)
+	// the filters of userID that haven't expired at now, newest first
+	ListContentFilters(ctx context.Context, userID uuid.UUID, now time.Time) ([]ContentFilter, error)
+	// returns ErrNotFound if the filter doesn't exist or belongs to someone else
+	DeleteContentFilter(ctx context.Context, userID, id uuid.UUID) error
+}
+
+// a word, phrase or wildcard chirps are checked for and what happens when it is found
+type ProfanityTerm struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Term      string
+	Action    string
+}
+
+type ProfanityStore interface {
+	// every term ordered by term
+	ListProfanityTerms(ctx context.Context) ([]ProfanityTerm, error)
+	// returns ErrConflict if the term already exists
+	CreateProfanityTerm(ctx context.Context, term, action string) (ProfanityTerm, error)
+	// returns ErrNotFound if the term doesn't exist and ErrConflict if another term is the same
+	UpdateProfanityTerm(ctx context.Context, id uuid.UUID, term, action string) (ProfanityTerm, error)
+	// returns ErrNotFound if the term doesn't exist
+	DeleteProfanityTerm(ctx context.Context, id uuid.UUID) error
+}
+
+// a chirp waiting for moderators, opened by the first report or by moderate profanity terms
+// a chirp has at most one unresolved case, reports after it is resolved open a new one
+type ModerationCase struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	ChirpID   uuid.UUID
+	AuthorID  uuid.UUID
+	// the chirp when the case was opened, it is kept when the chirp is deleted
+	Body string
+	// the moderate profanity terms the chirp was posted with
+	FlaggedTerms []string
+	// how many users reported the chirp
+	Reports int
+	// the moderator working on the case, "" while it is open
+	ClaimedBy  string
+	ClaimedAt  sql.NullTime
+	Resolution string
+	Note       string
+	ResolvedBy string
+	ResolvedAt sql.NullTime
+}
+
+// the statuses of a case, what ListModerationCases filters by
+const (
+	CaseOpen     = "open"
+	CaseClaimed  = "claimed"
+	CaseResolved = "resolved"
+)
+
+func (c ModerationCase) Status() string {
+	switch {
+	case c.ResolvedAt.Valid:
+		return CaseResolved
+	case c.ClaimedBy != "":
+		return CaseClaimed
+	}
+	return CaseOpen
+}
+
+// a user telling the moderators about a chirp
+type Report struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	CaseID     uuid.UUID
+	ChirpID    uuid.UUID
+	ReporterID uuid.UUID
+	Reason     string
+	Comment    string
+}
+
+type ModerationStore interface {
+	// the unresolved case of the chirp, one is opened when there is none
+	OpenModerationCase(ctx context.Context, chirp Chirp) (ModerationCase, error)
+	// only the fields the caller decides are used (case, chirp, reporter, reason and comment)
+	// returns the case with the report counted, ErrConflict if the reporter already reported it
+	AddReport(ctx context.Context, report Report) (ModerationCase, error)
+	// replaces the terms the case was flagged with
+	FlagModerationCase(ctx context.Context, id uuid.UUID, terms []string) (ModerationCase, error)
+	GetModerationCase(ctx context.Context, id uuid.UUID) (ModerationCase, error)
+	// the cases with the status, oldest first, "" lists open and claimed ones
+	ListModerationCases(ctx context.Context, status string) ([]ModerationCase, error)
+	// oldest first
+	ListReports(ctx context.Context, caseID uuid.UUID) ([]Report, error)
+	// claiming a case you already claimed does nothing
+	// returns ErrNotFound if the case doesn't exist and ErrConflict if it is resolved or someone else claimed it
+	ClaimModerationCase(ctx context.Context, id uuid.UUID, moderator string) (ModerationCase, error)
+	// an open case is claimed by the moderator resolving it, the errors are the same as ClaimModerationCase
+	ResolveModerationCase(ctx context.Context, id uuid.UUID, moderator, resolution, note string) (ModerationCase, error)
+}
+
+// everything the api needs to keep its data
+type Store interface {
+	UserStore
+	ChirpStore
+	RefreshTokenStore
+	NotificationStore
+	MessageStore
+	BlockStore
+	ContentFilterStore
+	ProfanityStore
+	ModerationStore
+}
@@ -0,0 +1,118 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/christianrm0821/Chirpy/internal/database/sqlitedb"
+	"github.com/christianrm0821/Chirpy/internal/migrate"
+	"github.com/christianrm0821/Chirpy/internal/store"
+	"github.com/christianrm0821/Chirpy/internal/store/storetest"
+	"github.com/christianrm0821/Chirpy/internal/tracing"
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestMemory(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		return store.NewMemory()
+	})
+}
+
+// a migrated sqlite database in a temp dir
+func openSQLite(t *testing.T) *sql.DB {
+	db, err := store.OpenSQLite(filepath.Join(t.TempDir(), "chirpy.db"))
+	if err != nil {
+		t.Fatalf("could not open sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrate.NewSQLite(db, discardLogger)
+	if err != nil {
+		t.Fatalf("could not load migrations: %v", err)
+	}
+	err = migrator.Up(context.Background())
+	if err != nil {
+		t.Fatalf("could not migrate: %v", err)
+	}
+	return db
+}
+
+func TestSQLite(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		return store.NewSQLite(openSQLite(t), nil)
+	})
+}
+
+// queries in a transaction get spans like the others
+func TestSQLiteTransactionsAreTraced(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	s := store.NewSQLite(openSQLite(t), func(db sqlitedb.DBTX) sqlitedb.DBTX {
+		return tracing.WrapDBTX(db, "sqlite")
+	})
+	ctx := context.Background()
+	alice, err := s.CreateUser(ctx, "alice@example.com", "hash")
+	if err != nil {
+		t.Fatalf("could not create user: %v", err)
+	}
+	_, err = s.CreateConversation(ctx, alice.ID, []uuid.UUID{alice.ID})
+	if err != nil {
+		t.Fatalf("could not create conversation: %v", err)
+	}
+
+	names := map[string]bool{}
+	for _, span := range recorder.Ended() {
+		names[span.Name()] = true
+	}
+	for _, want := range []string{"db.CreateUser", "db.CreateConversation", "db.AddConversationMember"} {
+		if !names[want] {
+			t.Errorf("was expecting a %s span but got %v", want, names)
+		}
+	}
+}
+
+// runs against a real postgres when CHIRPY_TEST_POSTGRES_URL is set
+// every user in that database is deleted
+func TestPostgres(t *testing.T) {
+	dbURL := os.Getenv("CHIRPY_TEST_POSTGRES_URL")
+	if dbURL == "" {
+		t.Skip("CHIRPY_TEST_POSTGRES_URL is not set")
+	}
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		t.Fatalf("could not open postgres: %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := migrate.NewPostgres(db, discardLogger)
+	if err != nil {
+		t.Fatalf("could not load migrations: %v", err)
+	}
+	err = migrator.Up(context.Background())
+	if err != nil {
+		t.Fatalf("could not migrate: %v", err)
+	}
+
+	storetest.Run(t, func(t *testing.T) store.Store {
+		s := store.NewPostgres(db, nil)
+		err := s.DeleteAllUsers(context.Background())
+		if err != nil {
+			t.Fatalf("could not clear the database: %v", err)
+		}
+		return s
+	})
+}
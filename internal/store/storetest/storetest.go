@@ -0,0 +1,844 @@
+// shared tests every Store implementation has to pass so the backends behave the same
+package storetest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christianrm0821/Chirpy/internal/store"
+	"github.com/google/uuid"
+)
+
+// runs the conformance suite
+// newStore has to return an empty store every time it is called
+func Run(t *testing.T, newStore func(t *testing.T) store.Store) {
+	t.Run("Users", func(t *testing.T) { testUsers(t, newStore(t)) })
+	t.Run("Chirps", func(t *testing.T) { testChirps(t, newStore(t)) })
+	t.Run("RefreshTokens", func(t *testing.T) { testRefreshTokens(t, newStore(t)) })
+	t.Run("DeleteAllUsers", func(t *testing.T) { testDeleteAllUsers(t, newStore(t)) })
+	t.Run("Notifications", func(t *testing.T) { testNotifications(t, newStore(t)) })
+	t.Run("Messages", func(t *testing.T) { testMessages(t, newStore(t)) })
+	t.Run("BlocksAndMutes", func(t *testing.T) { testBlocksAndMutes(t, newStore(t)) })
+	t.Run("ContentFilters", func(t *testing.T) { testContentFilters(t, newStore(t)) })
+	t.Run("Profanity", func(t *testing.T) { testProfanity(t, newStore(t)) })
+	t.Run("Moderation", func(t *testing.T) { testModeration(t, newStore(t)) })
+	t.Run("Roles", func(t *testing.T) { testRoles(t, newStore(t)) })
+	t.Run("Handles", func(t *testing.T) { testHandles(t, newStore(t)) })
+}
+
+// timestamps go through the database so only compare them to the millisecond
+func sameTime(a, b time.Time) bool {
+	diff := a.Sub(b)
+	return diff < time.Millisecond && diff > -time.Millisecond
+}
+
+func mustCreateUser(t *testing.T, s store.Store, email string) store.User {
+	t.Helper()
+	user, err := s.CreateUser(context.Background(), email, "hash-"+email)
+	if err != nil {
+		t.Fatalf("could not create user %s: %v", email, err)
+	}
+	return user
+}
+
+func testUsers(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	user := mustCreateUser(t, s, "alice@example.com")
+	if user.ID == uuid.Nil || user.CreatedAt.IsZero() || user.IsChirpyRed {
+		t.Errorf("new user is missing defaults: %+v", user)
+	}
+
+	_, err := s.CreateUser(ctx, "alice@example.com", "other")
+	if !errors.Is(err, store.ErrConflict) {
+		t.Errorf("was expecting ErrConflict for a duplicate email but got %v", err)
+	}
+
+	got, err := s.GetUserByEmail(ctx, "alice@example.com")
+	if err != nil || got.ID != user.ID || got.HashedPassword != "hash-alice@example.com" {
+		t.Errorf("was expecting %+v but got %+v, %v", user, got, err)
+	}
+
+	_, err = s.GetUserByEmail(ctx, "nobody@example.com")
+	if !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("was expecting ErrNotFound but got %v", err)
+	}
+	_, err = s.GetUserByID(ctx, uuid.New())
+	if !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("was expecting ErrNotFound but got %v", err)
+	}
+
+	bob := mustCreateUser(t, s, "bob@example.com")
+	err = s.UpdateUserCredentials(ctx, user.ID, bob.Email, "new-hash")
+	if !errors.Is(err, store.ErrConflict) {
+		t.Errorf("was expecting ErrConflict when taking another user's email but got %v", err)
+	}
+
+	err = s.UpdateUserCredentials(ctx, user.ID, "alice@new.example.com", "new-hash")
+	if err != nil {
+		t.Fatalf("was not expecting an error but got error: %v", err)
+	}
+	err = s.UpgradeUserToChirpyRed(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("was not expecting an error but got error: %v", err)
+	}
+	got, err = s.GetUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("was not expecting an error but got error: %v", err)
+	}
+	if got.Email != "alice@new.example.com" || got.HashedPassword != "new-hash" || !got.IsChirpyRed {
+		t.Errorf("user was not updated: %+v", got)
+	}
+	if !sameTime(got.CreatedAt, user.CreatedAt) {
+		t.Errorf("created_at changed from %v to %v", user.CreatedAt, got.CreatedAt)
+	}
+
+	err = s.UpgradeUserToChirpyRed(ctx, uuid.New())
+	if err != nil {
+		t.Errorf("upgrading a missing user should do nothing but got %v", err)
+	}
+}
+
+func testChirps(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	alice := mustCreateUser(t, s, "alice@example.com")
+	bob := mustCreateUser(t, s, "bob@example.com")
+
+	var created []store.Chirp
+	for _, c := range []struct {
+		user uuid.UUID
+		body string
+	}{{alice.ID, "first"}, {bob.ID, "second"}, {alice.ID, "third"}} {
+		chirp, err := s.CreateChirp(ctx, c.user, c.body)
+		if err != nil {
+			t.Fatalf("could not create chirp: %v", err)
+		}
+		created = append(created, chirp)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	_, err := s.CreateChirp(ctx, uuid.New(), "nobody")
+	if err == nil {
+		t.Error("was expecting an error for a chirp from a missing user")
+	}
+
+	got, err := s.GetChirp(ctx, created[1].ID)
+	if err != nil || got.Body != "second" || got.UserID != bob.ID {
+		t.Errorf("was expecting %+v but got %+v, %v", created[1], got, err)
+	}
+	_, err = s.GetChirp(ctx, uuid.New())
+	if !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("was expecting ErrNotFound but got %v", err)
+	}
+	some, err := s.GetChirps(ctx, []uuid.UUID{created[2].ID, uuid.New(), created[0].ID})
+	if err != nil || len(some) != 2 {
+		t.Errorf("was expecting the two chirps that exist but got %+v, %v", some, err)
+	}
+	if none, err := s.GetChirps(ctx, nil); err != nil || len(none) != 0 {
+		t.Errorf("was expecting no chirps for no ids but got %+v, %v", none, err)
+	}
+
+	tests := []struct {
+		name   string
+		params store.ListChirpsParams
+		want   []string
+	}{
+		{"all asc", store.ListChirpsParams{}, []string{"first", "second", "third"}},
+		{"all desc", store.ListChirpsParams{Desc: true}, []string{"third", "second", "first"}},
+		{"author asc", store.ListChirpsParams{AuthorID: alice.ID}, []string{"first", "third"}},
+		{"author desc", store.ListChirpsParams{AuthorID: alice.ID, Desc: true}, []string{"third", "first"}},
+		{"unknown author", store.ListChirpsParams{AuthorID: uuid.New()}, nil},
+	}
+	for _, tc := range tests {
+		chirps, err := s.ListChirps(ctx, tc.params)
+		if err != nil {
+			t.Fatalf("%s: was not expecting an error but got error: %v", tc.name, err)
+		}
+		var bodies []string
+		for _, chirp := range chirps {
+			bodies = append(bodies, chirp.Body)
+		}
+		if len(bodies) != len(tc.want) {
+			t.Errorf("%s: was expecting %v but got %v", tc.name, tc.want, bodies)
+			continue
+		}
+		for i := range bodies {
+			if bodies[i] != tc.want[i] {
+				t.Errorf("%s: was expecting %v but got %v", tc.name, tc.want, bodies)
+				break
+			}
+		}
+	}
+
+	for _, tc := range tests {
+		//walk the list one page at a time, the pages put together should match the full list
+		params := tc.params
+		params.Limit = 2
+		var bodies []string
+		for page := 0; page < 5; page++ {
+			chirps, err := s.ListChirps(ctx, params)
+			if err != nil {
+				t.Fatalf("%s paged: was not expecting an error but got error: %v", tc.name, err)
+			}
+			if len(chirps) > params.Limit {
+				t.Errorf("%s paged: was expecting at most %d chirps but got %d", tc.name, params.Limit, len(chirps))
+			}
+			for _, chirp := range chirps {
+				bodies = append(bodies, chirp.Body)
+			}
+			if len(chirps) < params.Limit {
+				break
+			}
+			params.After = store.CursorFor(chirps[len(chirps)-1])
+		}
+		if strings.Join(bodies, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%s paged: was expecting %v but got %v", tc.name, tc.want, bodies)
+		}
+	}
+
+	err = s.DeleteChirp(ctx, created[0].ID)
+	if err != nil {
+		t.Fatalf("was not expecting an error but got error: %v", err)
+	}
+	_, err = s.GetChirp(ctx, created[0].ID)
+	if !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("deleted chirp should be gone but got %v", err)
+	}
+	err = s.DeleteChirp(ctx, uuid.New())
+	if err != nil {
+		t.Errorf("deleting a missing chirp should do nothing but got %v", err)
+	}
+}
+
+func testRefreshTokens(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	user := mustCreateUser(t, s, "alice@example.com")
+
+	now := time.Now().UTC()
+	token := store.RefreshToken{
+		Token:     "token-1",
+		CreatedAt: now,
+		UpdatedAt: now,
+		UserID:    user.ID,
+		ExpiresAt: now.Add(60 * 24 * time.Hour),
+	}
+	_, err := s.CreateRefreshToken(ctx, token)
+	if err != nil {
+		t.Fatalf("was not expecting an error but got error: %v", err)
+	}
+	_, err = s.CreateRefreshToken(ctx, token)
+	if !errors.Is(err, store.ErrConflict) {
+		t.Errorf("was expecting ErrConflict for a duplicate token but got %v", err)
+	}
+
+	_, err = s.CreateRefreshToken(ctx, store.RefreshToken{Token: "token-2", CreatedAt: now, UpdatedAt: now, UserID: uuid.New(), ExpiresAt: now})
+	if err == nil {
+		t.Error("was expecting an error for a token of a missing user")
+	}
+
+	got, err := s.GetRefreshToken(ctx, "token-1")
+	if err != nil {
+		t.Fatalf("was not expecting an error but got error: %v", err)
+	}
+	if got.UserID != user.ID || !sameTime(got.ExpiresAt, token.ExpiresAt) || got.RevokedAt.Valid {
+		t.Errorf("was expecting %+v but got %+v", token, got)
+	}
+
+	revokedAt := now.Add(time.Minute)
+	err = s.RevokeRefreshToken(ctx, "token-1", revokedAt)
+	if err != nil {
+		t.Fatalf("was not expecting an error but got error: %v", err)
+	}
+	got, _ = s.GetRefreshToken(ctx, "token-1")
+	want := sql.NullTime{Time: revokedAt, Valid: true}
+	if !got.RevokedAt.Valid || !sameTime(got.RevokedAt.Time, want.Time) || !sameTime(got.UpdatedAt, revokedAt) {
+		t.Errorf("token was not revoked: %+v", got)
+	}
+
+	_, err = s.GetRefreshToken(ctx, "missing")
+	if !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("was expecting ErrNotFound but got %v", err)
+	}
+}
+
+func testDeleteAllUsers(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	user := mustCreateUser(t, s, "alice@example.com")
+	chirp, err := s.CreateChirp(ctx, user.ID, "hello")
+	if err != nil {
+		t.Fatalf("could not create chirp: %v", err)
+	}
+	now := time.Now().UTC()
+	_, err = s.CreateRefreshToken(ctx, store.RefreshToken{Token: "token", CreatedAt: now, UpdatedAt: now, UserID: user.ID, ExpiresAt: now})
+	if err != nil {
+		t.Fatalf("could not create refresh token: %v", err)
+	}
+
+	err = s.DeleteAllUsers(ctx)
+	if err != nil {
+		t.Fatalf("was not expecting an error but got error: %v", err)
+	}
+	_, err = s.GetUserByID(ctx, user.ID)
+	if !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("user should be gone but got %v", err)
+	}
+	_, err = s.GetChirp(ctx, chirp.ID)
+	if !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("chirps should be removed with their user but got %v", err)
+	}
+	_, err = s.GetRefreshToken(ctx, "token")
+	if !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("refresh tokens should be removed with their user but got %v", err)
+	}
+}
+
+func testNotifications(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	alice := mustCreateUser(t, s, "alice@example.com")
+	bob := mustCreateUser(t, s, "bob@example.com")
+	carol := mustCreateUser(t, s, "carol@example.com")
+	chirp, err := s.CreateChirp(ctx, alice.ID, "hello")
+	if err != nil {
+		t.Fatalf("could not create chirp: %v", err)
+	}
+	actor := func(u store.User) uuid.NullUUID { return uuid.NullUUID{UUID: u.ID, Valid: true} }
+	onChirp := uuid.NullUUID{UUID: chirp.ID, Valid: true}
+	add := func(n store.Notification) store.Notification {
+		t.Helper()
+		got, err := s.AddNotification(ctx, n)
+		if err != nil {
+			t.Fatalf("could not add notification: %v", err)
+		}
+		return got
+	}
+
+	mention := add(store.Notification{UserID: alice.ID, Type: "mention", ActorID: actor(bob), ChirpID: onChirp})
+	time.Sleep(2 * time.Millisecond)
+	resolved := add(store.Notification{UserID: alice.ID, Type: "report_resolved", ActorID: actor(carol), ChirpID: onChirp})
+	if resolved.ID == mention.ID || resolved.ActorID != actor(carol) || resolved.UpdatedAt.Before(mention.UpdatedAt) {
+		t.Errorf("was expecting a second notification but got %+v", resolved)
+	}
+	add(store.Notification{UserID: bob.ID, Type: "chirpy_red"})
+
+	count, err := s.CountUnreadNotifications(ctx, alice.ID)
+	if err != nil || count != 2 {
+		t.Errorf("was expecting 2 unread notifications but got %d, %v", count, err)
+	}
+
+	//newest activity first, one at a time
+	var ids []uuid.UUID
+	params := store.ListNotificationsParams{Limit: 1}
+	for i := 0; i < 3; i++ {
+		page, err := s.ListNotifications(ctx, alice.ID, params)
+		if err != nil {
+			t.Fatalf("was not expecting an error but got error: %v", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		ids = append(ids, page[0].ID)
+		params.After = store.NotificationCursor{UpdatedAt: page[0].UpdatedAt, ID: page[0].ID}
+	}
+	if len(ids) != 2 || ids[0] != resolved.ID || ids[1] != mention.ID {
+		t.Errorf("was expecting the resolution then the mention but got %v", ids)
+	}
+
+	err = s.MarkNotificationRead(ctx, bob.ID, resolved.ID, time.Now())
+	if !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("was expecting ErrNotFound for someone else's notification but got %v", err)
+	}
+	err = s.MarkNotificationRead(ctx, alice.ID, resolved.ID, time.Now())
+	if err != nil {
+		t.Fatalf("was not expecting an error but got error: %v", err)
+	}
+	unread, _ := s.ListNotifications(ctx, alice.ID, store.ListNotificationsParams{UnreadOnly: true})
+	if len(unread) != 1 || unread[0].ID != mention.ID {
+		t.Errorf("was expecting only the mention to be unread but got %+v", unread)
+	}
+
+	err = s.MarkAllNotificationsRead(ctx, alice.ID, time.Now())
+	if err != nil {
+		t.Fatalf("was not expecting an error but got error: %v", err)
+	}
+	count, _ = s.CountUnreadNotifications(ctx, alice.ID)
+	bobCount, _ := s.CountUnreadNotifications(ctx, bob.ID)
+	if count != 0 || bobCount != 1 {
+		t.Errorf("was expecting only alice's notifications to be read but got %d and %d unread", count, bobCount)
+	}
+
+	//notifications about a chirp go with it
+	err = s.DeleteChirp(ctx, chirp.ID)
+	if err != nil {
+		t.Fatalf("could not delete chirp: %v", err)
+	}
+	all, _ := s.ListNotifications(ctx, alice.ID, store.ListNotificationsParams{})
+	if len(all) != 0 {
+		t.Errorf("was expecting the notifications to be deleted with the chirp but got %+v", all)
+	}
+
+	prefs, err := s.GetNotificationPreferences(ctx, alice.ID)
+	if err != nil || len(prefs) != 0 {
+		t.Errorf("was expecting no preferences but got %v, %v", prefs, err)
+	}
+	s.SetNotificationPreference(ctx, alice.ID, "like", false)
+	s.SetNotificationPreference(ctx, alice.ID, "mention", false)
+	err = s.SetNotificationPreference(ctx, alice.ID, "mention", true)
+	if err != nil {
+		t.Fatalf("was not expecting an error but got error: %v", err)
+	}
+	prefs, _ = s.GetNotificationPreferences(ctx, alice.ID)
+	if len(prefs) != 2 || prefs["like"] || !prefs["mention"] {
+		t.Errorf("was expecting like off and mention on but got %v", prefs)
+	}
+}
+
+func testMessages(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	alice := mustCreateUser(t, s, "alice@example.com")
+	bob := mustCreateUser(t, s, "bob@example.com")
+	carol := mustCreateUser(t, s, "carol@example.com")
+
+	direct, err := s.CreateConversation(ctx, alice.ID, []uuid.UUID{alice.ID, bob.ID})
+	if err != nil {
+		t.Fatalf("could not create conversation: %v", err)
+	}
+	if direct.CreatedBy != alice.ID || len(direct.Members) != 2 {
+		t.Errorf("was expecting a conversation between alice and bob but got %+v", direct)
+	}
+	group, err := s.CreateConversation(ctx, bob.ID, []uuid.UUID{bob.ID, alice.ID, carol.ID})
+	if err != nil {
+		t.Fatalf("could not create conversation: %v", err)
+	}
+	//a member that doesn't exist leaves nothing behind
+	_, err = s.CreateConversation(ctx, carol.ID, []uuid.UUID{carol.ID, uuid.New()})
+	if err == nil {
+		t.Error("was expecting an error for a member that does not exist")
+	}
+	if conversations, _ := s.ListConversations(ctx, carol.ID); len(conversations) != 1 || conversations[0].ID != group.ID {
+		t.Errorf("was expecting carol to only be in the group but got %+v", conversations)
+	}
+
+	//the group has alice and bob too but it isn't their direct conversation
+	found, err := s.FindDirectConversation(ctx, bob.ID, alice.ID)
+	if err != nil || found.ID != direct.ID {
+		t.Errorf("was expecting the direct conversation but got %+v, %v", found, err)
+	}
+	_, err = s.FindDirectConversation(ctx, alice.ID, carol.ID)
+	if !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("was expecting ErrNotFound but got %v", err)
+	}
+
+	var sent []store.Message
+	for _, body := range []string{"one", "two", "three"} {
+		message, err := s.CreateMessage(ctx, direct.ID, alice.ID, body)
+		if err != nil {
+			t.Fatalf("could not create message: %v", err)
+		}
+		sent = append(sent, message)
+	}
+
+	//the conversation with the newest message comes first
+	conversations, err := s.ListConversations(ctx, alice.ID)
+	if err != nil || len(conversations) != 2 || conversations[0].ID != direct.ID {
+		t.Fatalf("was expecting the direct conversation first but got %+v, %v", conversations, err)
+	}
+	if !sameTime(conversations[0].UpdatedAt, sent[2].CreatedAt) {
+		t.Errorf("was expecting the conversation to be updated with the last message but got %v", conversations[0].UpdatedAt)
+	}
+	for _, member := range conversations[0].Members {
+		if member.LastReadAt.Valid != (member.UserID == alice.ID) {
+			t.Errorf("was expecting only the sender to have read the conversation but got %+v", member)
+		}
+	}
+	conversations, err = s.ListConversations(ctx, carol.ID)
+	if err != nil || len(conversations) != 1 || conversations[0].ID != group.ID || len(conversations[0].Members) != 3 {
+		t.Errorf("was expecting only the group for carol but got %+v, %v", conversations, err)
+	}
+
+	//newest first, one at a time
+	var bodies []string
+	params := store.ListMessagesParams{Limit: 2}
+	for i := 0; i < 3; i++ {
+		page, err := s.ListMessages(ctx, direct.ID, params)
+		if err != nil {
+			t.Fatalf("was not expecting an error but got error: %v", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		for _, message := range page {
+			bodies = append(bodies, message.Body)
+		}
+		last := page[len(page)-1]
+		params.After = store.MessageCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	if strings.Join(bodies, ",") != "three,two,one" {
+		t.Errorf("was expecting the messages newest first but got %v", bodies)
+	}
+
+	err = s.MarkConversationRead(ctx, direct.ID, carol.ID, time.Now())
+	if !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("was expecting ErrNotFound for someone who isn't a member but got %v", err)
+	}
+	err = s.MarkConversationRead(ctx, direct.ID, bob.ID, time.Now())
+	if err != nil {
+		t.Fatalf("was not expecting an error but got error: %v", err)
+	}
+	direct, err = s.GetConversation(ctx, direct.ID)
+	if err != nil {
+		t.Fatalf("was not expecting an error but got error: %v", err)
+	}
+	for _, member := range direct.Members {
+		if !member.LastReadAt.Valid {
+			t.Errorf("was expecting every member to have read the conversation but got %+v", member)
+		}
+	}
+
+	privacy, err := s.GetDMPrivacy(ctx, alice.ID)
+	if err != nil || privacy != "" {
+		t.Errorf("was expecting no privacy setting but got %q, %v", privacy, err)
+	}
+	for _, want := range []string{"nobody", "everyone"} {
+		err = s.SetDMPrivacy(ctx, alice.ID, want)
+		if err != nil {
+			t.Fatalf("was not expecting an error but got error: %v", err)
+		}
+		privacy, err = s.GetDMPrivacy(ctx, alice.ID)
+		if err != nil || privacy != want {
+			t.Errorf("was expecting %q but got %q, %v", want, privacy, err)
+		}
+	}
+}
+
+func testBlocksAndMutes(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	alice := mustCreateUser(t, s, "alice@example.com")
+	bob := mustCreateUser(t, s, "bob@example.com")
+	carol := mustCreateUser(t, s, "carol@example.com")
+	dave := mustCreateUser(t, s, "dave@example.com")
+	for _, user := range []store.User{alice, bob, carol, dave} {
+		_, err := s.CreateChirp(ctx, user.ID, "hi from "+user.Email)
+		if err != nil {
+			t.Fatalf("could not create chirp: %v", err)
+		}
+	}
+	authors := func(params store.ListChirpsParams) string {
+		t.Helper()
+		chirps, err := s.ListChirps(ctx, params)
+		if err != nil {
+			t.Fatalf("was not expecting an error but got error: %v", err)
+		}
+		var emails []string
+		for _, chirp := range chirps {
+			emails = append(emails, strings.TrimPrefix(chirp.Body, "hi from "))
+		}
+		sort.Strings(emails)
+		return strings.Join(emails, ",")
+	}
+
+	//blocking twice is fine
+	for i := 0; i < 2; i++ {
+		err := s.BlockUser(ctx, alice.ID, bob.ID)
+		if err != nil {
+			t.Fatalf("could not block: %v", err)
+		}
+	}
+	blocked, err := s.IsBlocked(ctx, bob.ID, alice.ID)
+	if err != nil || !blocked {
+		t.Errorf("was expecting the block to count both ways but got %v, %v", blocked, err)
+	}
+	err = s.MuteUser(ctx, store.Mute{MuterID: alice.ID, MutedID: carol.ID, ExpiresAt: sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}})
+	if err != nil {
+		t.Fatalf("could not mute: %v", err)
+	}
+	err = s.MuteUser(ctx, store.Mute{MuterID: alice.ID, MutedID: dave.ID, ExpiresAt: sql.NullTime{Time: time.Now().Add(-time.Hour), Valid: true}})
+	if err != nil {
+		t.Fatalf("could not mute: %v", err)
+	}
+
+	if got := authors(store.ListChirpsParams{ViewerID: alice.ID}); got != "alice@example.com,dave@example.com" {
+		t.Errorf("was expecting bob and carol to be hidden from alice but got %s", got)
+	}
+	if got := authors(store.ListChirpsParams{ViewerID: bob.ID, Limit: 10}); got != "bob@example.com,carol@example.com,dave@example.com" {
+		t.Errorf("was expecting alice to be hidden from bob but got %s", got)
+	}
+	//mutes only apply to timelines, blocks apply everywhere
+	if got := authors(store.ListChirpsParams{ViewerID: alice.ID, AuthorID: carol.ID}); got != "carol@example.com" {
+		t.Errorf("was expecting carol's own chirps to show but got %s", got)
+	}
+	if got := authors(store.ListChirpsParams{ViewerID: alice.ID, AuthorID: bob.ID, Desc: true}); got != "" {
+		t.Errorf("was expecting bob's chirps to stay hidden but got %s", got)
+	}
+
+	hidden, err := s.HiddenUserIDs(ctx, alice.ID, time.Now())
+	if err != nil || len(hidden) != 2 {
+		t.Errorf("was expecting bob and carol to be hidden but got %v, %v", hidden, err)
+	}
+	mutes, err := s.ListMutes(ctx, alice.ID, time.Now())
+	if err != nil || len(mutes) != 1 || mutes[0].MutedID != carol.ID || !mutes[0].ExpiresAt.Valid {
+		t.Errorf("was expecting only carol's mute to be active but got %+v, %v", mutes, err)
+	}
+	blocks, err := s.ListBlocks(ctx, alice.ID)
+	if err != nil || len(blocks) != 1 || blocks[0].BlockedID != bob.ID {
+		t.Errorf("was expecting alice to have blocked bob but got %+v, %v", blocks, err)
+	}
+
+	for _, err := range []error{s.UnblockUser(ctx, alice.ID, bob.ID), s.UnmuteUser(ctx, alice.ID, carol.ID)} {
+		if err != nil {
+			t.Errorf("was not expecting an error but got error: %v", err)
+		}
+	}
+	for _, err := range []error{s.UnblockUser(ctx, alice.ID, bob.ID), s.UnmuteUser(ctx, bob.ID, carol.ID)} {
+		if !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("was expecting ErrNotFound but got %v", err)
+		}
+	}
+	if got := authors(store.ListChirpsParams{ViewerID: alice.ID}); got != "alice@example.com,bob@example.com,carol@example.com,dave@example.com" {
+		t.Errorf("was expecting every chirp once nobody is blocked or muted but got %s", got)
+	}
+}
+
+func testContentFilters(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	alice := mustCreateUser(t, s, "alice@example.com")
+	bob := mustCreateUser(t, s, "bob@example.com")
+
+	expiresAt := sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}
+	word, err := s.CreateContentFilter(ctx, store.ContentFilter{UserID: alice.ID, Kind: "word", Value: "spoiler", Timeline: true, ExpiresAt: expiresAt})
+	if err != nil {
+		t.Fatalf("could not create filter: %v", err)
+	}
+	if word.ID == uuid.Nil || word.Kind != "word" || word.Value != "spoiler" || !word.Timeline || word.Notifications || !sameTime(word.ExpiresAt.Time, expiresAt.Time) {
+		t.Errorf("filter was not saved as given: %+v", word)
+	}
+	_, err = s.CreateContentFilter(ctx, store.ContentFilter{UserID: alice.ID, Kind: "hashtag", Value: "old", Timeline: true,
+		ExpiresAt: sql.NullTime{Time: time.Now().Add(-time.Hour), Valid: true}})
+	if err != nil {
+		t.Fatalf("could not create filter: %v", err)
+	}
+	regex, err := s.CreateContentFilter(ctx, store.ContentFilter{UserID: alice.ID, Kind: "regex", Value: "fo+", Notifications: true})
+	if err != nil {
+		t.Fatalf("could not create filter: %v", err)
+	}
+
+	filters, err := s.ListContentFilters(ctx, alice.ID, time.Now())
+	if err != nil {
+		t.Fatalf("could not list filters: %v", err)
+	}
+	if len(filters) != 2 || filters[0].ID != regex.ID || filters[1].ID != word.ID || filters[0].ExpiresAt.Valid {
+		t.Errorf("was expecting the regex then the word without the expired filter but got %+v", filters)
+	}
+
+	if err := s.DeleteContentFilter(ctx, bob.ID, word.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("was expecting ErrNotFound deleting someone else's filter but got %v", err)
+	}
+	if err := s.DeleteContentFilter(ctx, alice.ID, word.ID); err != nil {
+		t.Errorf("could not delete filter: %v", err)
+	}
+	if err := s.DeleteContentFilter(ctx, alice.ID, word.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("was expecting ErrNotFound deleting twice but got %v", err)
+	}
+	filters, _ = s.ListContentFilters(ctx, alice.ID, time.Now())
+	if len(filters) != 1 || filters[0].ID != regex.ID {
+		t.Errorf("was expecting only the regex to be left but got %+v", filters)
+	}
+}
+
+func testProfanity(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	terms, err := s.ListProfanityTerms(ctx)
+	if err != nil {
+		t.Fatalf("could not list terms: %v", err)
+	}
+	var words []string
+	for _, term := range terms {
+		words = append(words, term.Term+":"+term.Action)
+	}
+	if got := strings.Join(words, ","); got != "fornax:mask,kerfuffle:mask,sharbert:mask" {
+		t.Errorf("was expecting the default terms but got %s", got)
+	}
+
+	term, err := s.CreateProfanityTerm(ctx, "darn*", "reject")
+	if err != nil {
+		t.Fatalf("could not create term: %v", err)
+	}
+	if _, err := s.CreateProfanityTerm(ctx, "darn*", "mask"); !errors.Is(err, store.ErrConflict) {
+		t.Errorf("was expecting ErrConflict for the same term but got %v", err)
+	}
+	updated, err := s.UpdateProfanityTerm(ctx, term.ID, "darn it", "moderate")
+	if err != nil || updated.Term != "darn it" || updated.Action != "moderate" || updated.UpdatedAt.Before(term.UpdatedAt) {
+		t.Errorf("was expecting the term to change but got %+v, %v", updated, err)
+	}
+	if _, err := s.UpdateProfanityTerm(ctx, term.ID, "fornax", "mask"); !errors.Is(err, store.ErrConflict) {
+		t.Errorf("was expecting ErrConflict renaming to an existing term but got %v", err)
+	}
+	if _, err := s.UpdateProfanityTerm(ctx, uuid.New(), "gosh", "mask"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("was expecting ErrNotFound updating a missing term but got %v", err)
+	}
+	if err := s.DeleteProfanityTerm(ctx, term.ID); err != nil {
+		t.Errorf("could not delete term: %v", err)
+	}
+	if err := s.DeleteProfanityTerm(ctx, term.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("was expecting ErrNotFound deleting twice but got %v", err)
+	}
+
+}
+
+func testModeration(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	author := mustCreateUser(t, s, "alice@example.com")
+	reporter := mustCreateUser(t, s, "bob@example.com")
+	chirp, err := s.CreateChirp(ctx, author.ID, "darn it")
+	if err != nil {
+		t.Fatalf("could not create chirp: %v", err)
+	}
+
+	opened, err := s.OpenModerationCase(ctx, chirp)
+	if err != nil || opened.ChirpID != chirp.ID || opened.AuthorID != author.ID || opened.Body != "darn it" || opened.Status() != store.CaseOpen {
+		t.Fatalf("was expecting an open case for the chirp but got %+v, %v", opened, err)
+	}
+	again, err := s.OpenModerationCase(ctx, chirp)
+	if err != nil || again.ID != opened.ID {
+		t.Errorf("was expecting the same case for the same chirp but got %+v, %v", again, err)
+	}
+	flagged, err := s.FlagModerationCase(ctx, opened.ID, []string{"darn it", "gosh"})
+	if err != nil || strings.Join(flagged.FlaggedTerms, "|") != "darn it|gosh" {
+		t.Errorf("was expecting both terms on the case but got %+v, %v", flagged, err)
+	}
+
+	reported, err := s.AddReport(ctx, store.Report{CaseID: opened.ID, ChirpID: chirp.ID, ReporterID: reporter.ID, Reason: "spam"})
+	if err != nil || reported.Reports != 1 {
+		t.Errorf("was expecting one report on the case but got %+v, %v", reported, err)
+	}
+	if _, err := s.AddReport(ctx, store.Report{CaseID: opened.ID, ChirpID: chirp.ID, ReporterID: reporter.ID, Reason: "hate"}); !errors.Is(err, store.ErrConflict) {
+		t.Errorf("was expecting ErrConflict reporting twice but got %v", err)
+	}
+	reports, err := s.ListReports(ctx, opened.ID)
+	if err != nil || len(reports) != 1 || reports[0].ReporterID != reporter.ID || reports[0].Reason != "spam" {
+		t.Errorf("was expecting the report but got %+v, %v", reports, err)
+	}
+
+	claimed, err := s.ClaimModerationCase(ctx, opened.ID, "mod-a")
+	if err != nil || claimed.ClaimedBy != "mod-a" || claimed.Status() != store.CaseClaimed {
+		t.Errorf("was expecting the case to be claimed but got %+v, %v", claimed, err)
+	}
+	if _, err := s.ClaimModerationCase(ctx, opened.ID, "mod-b"); !errors.Is(err, store.ErrConflict) {
+		t.Errorf("was expecting ErrConflict claiming someone else's case but got %v", err)
+	}
+	if _, err := s.ResolveModerationCase(ctx, opened.ID, "mod-b", "hide", ""); !errors.Is(err, store.ErrConflict) {
+		t.Errorf("was expecting ErrConflict resolving someone else's case but got %v", err)
+	}
+	if _, err := s.ClaimModerationCase(ctx, uuid.New(), "mod-a"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("was expecting ErrNotFound claiming a missing case but got %v", err)
+	}
+	if cases, _ := s.ListModerationCases(ctx, store.CaseOpen); len(cases) != 0 {
+		t.Errorf("was expecting no open cases but got %+v", cases)
+	}
+	if cases, _ := s.ListModerationCases(ctx, ""); len(cases) != 1 {
+		t.Errorf("was expecting the claimed case among the unresolved but got %+v", cases)
+	}
+
+	resolved, err := s.ResolveModerationCase(ctx, opened.ID, "mod-a", "hide", "spam")
+	if err != nil || resolved.Resolution != "hide" || resolved.Note != "spam" || resolved.ResolvedBy != "mod-a" || resolved.Status() != store.CaseResolved {
+		t.Errorf("was expecting the case to be resolved but got %+v, %v", resolved, err)
+	}
+	if _, err := s.ResolveModerationCase(ctx, opened.ID, "mod-a", "dismiss", ""); !errors.Is(err, store.ErrConflict) {
+		t.Errorf("was expecting ErrConflict resolving twice but got %v", err)
+	}
+	got, err := s.GetModerationCase(ctx, opened.ID)
+	if err != nil || got.Reports != 1 || got.Resolution != "hide" {
+		t.Errorf("was expecting the resolved case but got %+v, %v", got, err)
+	}
+	reopened, err := s.OpenModerationCase(ctx, chirp)
+	if err != nil || reopened.ID == opened.ID || reopened.Reports != 0 {
+		t.Errorf("was expecting a new case once the old one was resolved but got %+v, %v", reopened, err)
+	}
+	if cases, _ := s.ListModerationCases(ctx, store.CaseResolved); len(cases) != 1 || cases[0].ID != opened.ID {
+		t.Errorf("was expecting the resolved case but got %+v", cases)
+	}
+
+	if err := s.SetChirpHidden(ctx, chirp.ID, true); err != nil {
+		t.Fatalf("could not hide chirp: %v", err)
+	}
+	if chirps, _ := s.ListChirps(ctx, store.ListChirpsParams{Limit: 10}); len(chirps) != 0 {
+		t.Errorf("was expecting the hidden chirp to be left out but got %+v", chirps)
+	}
+	if chirps, _ := s.ListChirps(ctx, store.ListChirpsParams{Limit: 10, ViewerID: author.ID}); len(chirps) != 1 || !chirps[0].HiddenAt.Valid {
+		t.Errorf("was expecting the author to still see the hidden chirp but got %+v", chirps)
+	}
+	if err := s.SetChirpHidden(ctx, uuid.New(), true); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("was expecting ErrNotFound hiding a missing chirp but got %v", err)
+	}
+
+	if err := s.SetUserSuspended(ctx, author.ID, true); err != nil {
+		t.Fatalf("could not suspend user: %v", err)
+	}
+	if user, _ := s.GetUserByID(ctx, author.ID); !user.SuspendedAt.Valid {
+		t.Errorf("was expecting the user to be suspended but got %+v", user)
+	}
+	if err := s.SetUserSuspended(ctx, author.ID, false); err != nil {
+		t.Fatalf("could not lift suspension: %v", err)
+	}
+	if user, _ := s.GetUserByID(ctx, author.ID); user.SuspendedAt.Valid {
+		t.Errorf("was expecting the suspension to be lifted but got %+v", user)
+	}
+	if err := s.SetUserSuspended(ctx, uuid.New(), true); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("was expecting ErrNotFound suspending a missing user but got %v", err)
+	}
+}
+
+func testRoles(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	user := mustCreateUser(t, s, "alice@example.com")
+	if user.Role != store.RoleUser {
+		t.Errorf("was expecting new users to have the user role but got %q", user.Role)
+	}
+	if err := s.SetUserRole(ctx, user.ID, store.RoleModerator); err != nil {
+		t.Fatalf("could not set role: %v", err)
+	}
+	if got, _ := s.GetUserByEmail(ctx, "alice@example.com"); got.Role != store.RoleModerator {
+		t.Errorf("was expecting the moderator role but got %q", got.Role)
+	}
+	if err := s.SetUserRole(ctx, user.ID, "owner"); err == nil {
+		t.Error("was expecting an error for an unknown role")
+	}
+	if err := s.SetUserRole(ctx, uuid.New(), store.RoleAdmin); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("was expecting ErrNotFound for a missing user but got %v", err)
+	}
+}
+
+func testHandles(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	alice, _ := s.CreateUser(ctx, "alice@example.com", "hash")
+	bob, _ := s.CreateUser(ctx, "bob@example.com", "hash")
+	if alice.Handle != "" {
+		t.Errorf("was expecting new users to have no handle but got %q", alice.Handle)
+	}
+	if _, err := s.GetUserByHandle(ctx, ""); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("was expecting ErrNotFound for an empty handle but got %v", err)
+	}
+	if err := s.SetUserHandle(ctx, alice.ID, "alice"); err != nil {
+		t.Fatalf("could not set handle: %v", err)
+	}
+	if got, err := s.GetUserByHandle(ctx, "alice"); err != nil || got.ID != alice.ID {
+		t.Errorf("was expecting alice by her handle but got %+v %v", got, err)
+	}
+	if err := s.SetUserHandle(ctx, bob.ID, "alice"); !errors.Is(err, store.ErrConflict) {
+		t.Errorf("was expecting ErrConflict for a taken handle but got %v", err)
+	}
+	//clearing it lets someone else take it
+	if err := s.SetUserHandle(ctx, alice.ID, ""); err != nil {
+		t.Fatalf("could not clear handle: %v", err)
+	}
+	if err := s.SetUserHandle(ctx, bob.ID, "alice"); err != nil {
+		t.Errorf("was expecting the cleared handle to be free but got %v", err)
+	}
+	if err := s.SetUserHandle(ctx, uuid.New(), "carol"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("was expecting ErrNotFound for a missing user but got %v", err)
+	}
+}
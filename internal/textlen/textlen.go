@@ -0,0 +1,39 @@
+// counts text the way people read it, for the chirp length limit
+package textlen
+
+import (
+	"regexp"
+
+	"github.com/rivo/uniseg"
+	"golang.org/x/text/unicode/norm"
+)
+
+// what a link counts as however long it is, so shortened and full links cost the same
+const URLWeight = 23
+
+// links longer than this, in bytes, count their characters like the rest of the text
+const MaxURLLength = 512
+
+var urlPattern = regexp.MustCompile(`(?i)\bhttps?://[^\s<>"]+`)
+
+// the length of a chirp: characters as people see them after NFC normalization,
+// with every link up to MaxURLLength counted as URLWeight
+func Length(text string) int {
+	text = norm.NFC.String(text)
+	length := 0
+	last := 0
+	for _, loc := range urlPattern.FindAllStringIndex(text, -1) {
+		if loc[1]-loc[0] > MaxURLLength {
+			continue
+		}
+		length += Graphemes(text[last:loc[0]]) + URLWeight
+		last = loc[1]
+	}
+	return length + Graphemes(text[last:])
+}
+
+// how many user-perceived characters (extended grapheme clusters, unicode annex 29) text has
+// "é" written as e and a combining accent, a flag and a family emoji joined with zero-width joiners are one each
+func Graphemes(text string) int {
+	return uniseg.GraphemeClusterCount(text)
+}
@@ -0,0 +1,53 @@
+package textlen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGraphemes(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"hello", 5},
+		{"こんにちは", 5},
+		//e and a combining acute accent
+		{"cafe\u0301", 4},
+		{"\r\n", 1},
+		{"👍🏽", 1},
+		{"👨‍👩‍👧‍👦", 1},
+		{"🇯🇵🇫🇷", 2},
+		{"🇯🇵🇫", 2},
+		{"❤️", 1},
+		//hangul jamo make one syllable
+		{"각", 1},
+		{"한국어", 3},
+		{"नमस्ते", 4},
+	}
+	for _, test := range tests {
+		if got := Graphemes(test.text); got != test.want {
+			t.Errorf("%q: was expecting %d but got %d", test.text, test.want, got)
+		}
+	}
+}
+
+func TestLength(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"hi there", 8},
+		{"see https://example.com/a/very/long/path?with=query", 4 + URLWeight},
+		{"http://a.co and http://b.co", URLWeight + 5 + URLWeight},
+		{strings.Repeat("🙂", 140), 140},
+		//a link that long counts its characters
+		{"https://example.com/" + strings.Repeat("a", MaxURLLength), 20 + MaxURLLength},
+	}
+	for _, test := range tests {
+		if got := Length(test.text); got != test.want {
+			t.Errorf("%q: was expecting %d but got %d", test.text, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	_ "modernc.org/sqlite"
+)
+
+func TestQueryName(t *testing.T) {
+	tests := map[string]string{
+		"-- name: GetChirp :one\nselect * from chirps": "GetChirp",
+		"select 1":            "query",
+		"-- name: \nselect 1": "query",
+	}
+	for query, want := range tests {
+		if got := queryName(query); got != want {
+			t.Errorf("%q: was expecting %q but got %q", query, want, got)
+		}
+	}
+}
+
+func TestWrapDBTX(t *testing.T) {
+	recorder := recordSpans(t)
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	traced := WrapDBTX(db, "sqlite")
+
+	ctx, parent := Tracer().Start(context.Background(), "request")
+	var n int
+	err = traced.QueryRowContext(ctx, "-- name: One :one\nselect 1").Scan(&n)
+	if err != nil || n != 1 {
+		t.Fatalf("was expecting 1 but got %d, %v", n, err)
+	}
+	err = traced.QueryRowContext(ctx, "-- name: Nothing :one\nselect 1 where false").Scan(&n)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("was expecting sql.ErrNoRows but got %v", err)
+	}
+	_, err = traced.ExecContext(ctx, "-- name: Broken :exec\nselect * from missing")
+	if err == nil {
+		t.Fatal("was expecting an error querying a missing table")
+	}
+	parent.End()
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	one := spans["db.One"]
+	if one == nil {
+		t.Fatalf("was expecting a db.One span but got %v", spans)
+	}
+	if one.Parent().SpanID() != parent.SpanContext().SpanID() || one.SpanKind() != trace.SpanKindClient {
+		t.Errorf("was expecting a client span under the request but got parent %s kind %v", one.Parent().SpanID(), one.SpanKind())
+	}
+	attrs := attribute.NewSet(one.Attributes()...)
+	if system, _ := attrs.Value("db.system"); system.AsString() != "sqlite" {
+		t.Errorf("was expecting db.system sqlite but got %q", system.AsString())
+	}
+	if statement, _ := attrs.Value("db.statement"); statement.AsString() != "-- name: One :one\nselect 1" {
+		t.Errorf("was expecting the query as db.statement but got %q", statement.AsString())
+	}
+	//finding nothing is not a failure
+	if nothing := spans["db.Nothing"]; nothing == nil || nothing.Status().Code == codes.Error {
+		t.Errorf("was not expecting sql.ErrNoRows to be an error but got %+v", nothing)
+	}
+	if broken := spans["db.Broken"]; broken == nil || broken.Status().Code != codes.Error {
+		t.Errorf("was expecting the failed query to be an error but got %+v", broken)
+	}
+}
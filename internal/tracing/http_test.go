@@ -0,0 +1,64 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMiddleware(t *testing.T) {
+	recorder := recordSpans(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/chirps/{chirpID}", func(w http.ResponseWriter, r *http.Request) {
+		//the handler runs inside the request span
+		if !trace.SpanContextFromContext(r.Context()).IsValid() {
+			t.Error("was expecting a span in the request context")
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest("GET", "/api/chirps/123", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	res := httptest.NewRecorder()
+	Middleware(mux).ServeHTTP(res, req)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("was expecting one span but got %d", len(spans))
+	}
+	span := spans[0]
+	if span.SpanContext().TraceID().String() != traceID || span.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("was expecting the span to continue the incoming trace but got trace %s parent %s", span.SpanContext().TraceID(), span.Parent().SpanID())
+	}
+	if span.Name() != "GET /api/chirps/{chirpID}" || span.SpanKind() != trace.SpanKindServer {
+		t.Errorf("was expecting a server span named after the route but got %q %v", span.Name(), span.SpanKind())
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("was expecting a 500 to mark the span as an error but got %v", span.Status())
+	}
+	attrs := attribute.NewSet(span.Attributes()...)
+	if status, _ := attrs.Value("http.response.status_code"); status.AsInt64() != 500 {
+		t.Errorf("was expecting the status code on the span but got %v", status)
+	}
+	//the response carries the trace so clients can look it up
+	if got := res.Header().Get("traceparent"); !strings.Contains(got, traceID) || !strings.Contains(got, span.SpanContext().SpanID().String()) {
+		t.Errorf("was expecting the traceparent of the request span in the response but got %q", got)
+	}
+}
+
+func TestMiddlewareStartsTrace(t *testing.T) {
+	recorder := recordSpans(t)
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/nowhere", nil))
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Parent().IsValid() || spans[0].Name() != "POST" {
+		t.Errorf("was expecting a new trace named after the method without a route but got %+v", spans)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// keys that should never show up in the logs in plain text
+var redactedKeys = map[string]bool{
+	"token":           true,
+	"refresh_token":   true,
+	"password":        true,
+	"hashed_password": true,
+	"authorization":   true,
+	"api_key":         true,
+	"secret":          true,
+	"body":            true,
+}
+
+// makes a json logger that redacts tokens, passwords and chirp bodies
+func newLogger(w io.Writer, level slog.Level) *slog.Logger {
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if redactedKeys[strings.ToLower(a.Key)] {
+				return slog.String(a.Key, "[REDACTED]")
+			}
+			return a
+		},
+	})
+	return slog.New(handler)
+}
@@ -1,564 +1,162 @@
 package main
 
 import (
-	"database/sql"
-	"encoding/json"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
 	"net/http"
 	"os"
-	"sync/atomic"
+	"os/signal"
+	"syscall"
 	"time"
 
-	"github.com/christianrm0821/Chirpy/internal/auth"
-	"github.com/christianrm0821/Chirpy/internal/database"
-	"github.com/google/uuid"
-	"github.com/joho/godotenv"
-	_ "github.com/lib/pq"
+	"github.com/christianrm0821/Chirpy/internal/config"
+	"github.com/christianrm0821/Chirpy/internal/events"
+	"github.com/christianrm0821/Chirpy/internal/health"
+	"github.com/christianrm0821/Chirpy/internal/server"
+	"github.com/christianrm0821/Chirpy/internal/tracing"
 )
 
-func respondWithError(w http.ResponseWriter, code int, msg string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	generalErr := resErr{
-		Error: msg,
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
 	}
-	res, _ := json.Marshal(generalErr)
-	w.Write(res)
-}
-
-func respondWithJson(w http.ResponseWriter, code int, payload interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	res, _ := json.Marshal(payload)
-	w.Write(res)
-}
-
-func mapChirpToValidChirp(myChirp database.Chirp) validChirp {
-	valChirp := validChirp{
-		ID:        myChirp.ID,
-		CreatedAT: myChirp.CreatedAt,
-		UpdatedAt: myChirp.UpdatedAt,
-		Body:      myChirp.Body,
-		UserID:    myChirp.UserID,
+	if len(os.Args) > 1 && os.Args[1] == "role" {
+		os.Exit(runRole(os.Args[2:]))
 	}
-	return valChirp
-}
 
-func main() {
-	godotenv.Load()
-	dbURL := os.Getenv("DB_URL")
-	db, err := sql.Open("postgres", dbURL)
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
 	if err != nil {
-		log.Fatal("error: ", err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if cfg.PrintConfig {
+		cfg.Print(os.Stdout)
 		return
 	}
 
-	//making a newserveMux
-	const port = ":8080"
+	logger := newLogger(os.Stdout, cfg.LogLevel)
+	logger.Info("loaded config", "config", cfg.Redacted())
 
-	//keeps count of how many requests are being made
-	counter := apiConfig{
-		fileserverHits: atomic.Int32{},
-		dbQueries:      database.New(db),
-		PLATFORM:       os.Getenv("PLATFORM"),
-		Secret:         os.Getenv("SECRET"),
-		PolkaKey:       os.Getenv("POLKA_KEY"),
+	storage, err := openStorage(cfg.DBURL, logger)
+	if err != nil {
+		logger.Error("could not open storage", "error", err)
+		os.Exit(1)
 	}
-	counter.fileserverHits.Store(0)
-
-	//mux or multiplexer
-	//it is a request router
-	// it gets incoming http requests and decides which handler function should process the request
-	//maps url patterns to handler functions
-	serveMux := http.NewServeMux()
-
-	//handlefunc register handlers with serveMux
-	//takes in the "/healthz" endpoint
-	//takes in a function with the signature "func(http.ResponseWriter, *http.Request)"
-	//It automatically converts your function to a handler interface
-	serveMux.HandleFunc("GET /api/healthz", func(w http.ResponseWriter, req *http.Request) {
-		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	})
-
-	//registers handler with serveMux
-	//takes url path with an object with the method "ServeHTTP(http.ResponseWriter, *http.Request)"
-	//used for pre-built handlers or custom handler type
-	//want to use this over a handle in more complex situations such as with the fileserver handler or using miiddleware like stripPrefix
-	//Strip prefix takes away the prefix "/app" from the handler
-	//FileServer is a built in handler, automatically handles file serving, content types, and directory listings
-	//FileServer serves static content
-	appHandler := http.StripPrefix("/app", http.FileServer(http.Dir(".")))
-	serveMux.Handle("/app/", counter.MiddlewareMetricsInc(appHandler))
-
-	//register the metrics handler
-	serveMux.HandleFunc("GET /admin/metrics", counter.RequestNum)
-
-	//register the reset handler
-	serveMux.HandleFunc("POST /admin/reset", counter.resetComplete)
-
-	//register the users handler
-	serveMux.HandleFunc("POST /api/users", func(w http.ResponseWriter, r *http.Request) {
-		//make a decoder to get the request information
-		decoder := json.NewDecoder(r.Body)
-		request := email{}
-		err := decoder.Decode(&request)
-		if err != nil {
-			errMsg := fmt.Sprintf("error decoding: %v", err)
-			respondWithError(w, 500, errMsg)
-			return
-		}
-
-		//hash password
-		hashed_password, err := auth.HashPassword(request.Password)
-		if err != nil {
-			//log.Fatal("error hashing the password: ", err)
-			errMsg := fmt.Sprintf("error hashing password %v", err)
-			respondWithError(w, 500, errMsg)
-		}
-
-		myEmailStruct := database.CreateUserParams{
-			Email:          request.Email,
-			HashedPassword: hashed_password,
-		}
-
-		user, err := counter.dbQueries.CreateUser(r.Context(), myEmailStruct)
-		if err != nil {
-			errMsg := fmt.Sprintf("error creating user: %v", err)
-			respondWithError(w, 500, errMsg)
-			return
-		}
-		respondWithJson(w, 201, userReturnEmail{
-			ID:            user.ID,
-			CreatedAT:     user.CreatedAt,
-			UpdatedAt:     user.UpdatedAt,
-			Email:         user.Email,
-			Is_Chirpy_Red: user.IsChirpyRed,
-		})
-	})
-
-	serveMux.HandleFunc("PUT /api/users", func(w http.ResponseWriter, r *http.Request) {
-		//get token from header
-		actualToken, err := auth.GetBearerToken(r.Header)
-		if err != nil {
-			errmsg := fmt.Sprintf("could not get token from header Error: %v", err)
-			respondWithError(w, 401, errmsg)
-			return
-		}
-
-		//get userID from token
-		userID, err := auth.ValidateJWT(actualToken, counter.Secret)
-		if err != nil {
-			errmsg := fmt.Sprintf("error validating token Error: %v", err)
-			respondWithError(w, 401, errmsg)
-			return
-		}
-
-		//decode request
-		decoder := json.NewDecoder(r.Body)
-		request := email{}
-		err = decoder.Decode(&request)
-		if err != nil {
-			errmsg := fmt.Sprintf("error decoding request Error: %v", err)
-			respondWithError(w, 500, errmsg)
-			return
-		}
-
-		myHashedPassword, err := auth.HashPassword(request.Password)
-		if err != nil {
-			errmsg := fmt.Sprintf("error hashing password Error: %v", err)
-			respondWithError(w, 500, errmsg)
-			return
-		}
-		err = auth.CheckPasswordHash(myHashedPassword, request.Password)
-		if err != nil {
-			errmsg := fmt.Sprintf("hashed password and password do not match Error: %v", err)
-			respondWithError(w, 500, errmsg)
-			return
-		}
-
-		newUserPasswordEmail := database.UpdatePasswordEmailFromUserIDParams{
-			HashedPassword: myHashedPassword,
-			Email:          request.Email,
-			ID:             userID,
-		}
-
-		err = counter.dbQueries.UpdatePasswordEmailFromUserID(r.Context(), newUserPasswordEmail)
-		if err != nil {
-			errmsg := fmt.Sprintf("error updating password Error: %v", err)
-			respondWithError(w, 500, errmsg)
-			return
-		}
-
-		userInfo, err := counter.dbQueries.GetUserFromID(r.Context(), userID)
-		if err != nil {
-			errmsg := fmt.Sprintf("error getting user information Error: %v", err)
-			respondWithError(w, 401, errmsg)
-			return
-		}
-
-		respondWithJson(w, 200, userReturnEmail{
-			ID:        userID,
-			CreatedAT: userInfo.CreatedAt,
-			UpdatedAt: userInfo.UpdatedAt,
-			Email:     userInfo.Email,
-		})
-
-	})
-
-	//register the login handler
-	serveMux.HandleFunc("POST /api/login", func(w http.ResponseWriter, r *http.Request) {
-		decoder := json.NewDecoder(r.Body)
-		request := email{}
-		err := decoder.Decode(&request)
-		if err != nil {
-			errMsg := fmt.Sprintf("error decoding: %v", err)
-			respondWithError(w, 500, errMsg)
-			return
-		}
-
-		user, err := counter.dbQueries.GetUserByEmail(r.Context(), request.Email)
-		if err != nil {
-			respondWithError(w, 401, "Unauthorized")
-			return
-		}
-
-		//checks if the password is correct
-		err = auth.CheckPasswordHash(user.HashedPassword, request.Password)
-		if err != nil {
-			respondWithError(w, 401, "Unauthorized")
-			return
-		}
-
-		//This is getting a time of 1 hour which is the token life length
-		expiredTimeDuration, err := time.ParseDuration("1h")
-		if err != nil {
-			respondWithError(w, 500, "could not convert time to duration")
-			return
-		}
-
-		//makes a new token with current user ID, secret and expiration time
-		token, err := auth.MakeJWT(user.ID, counter.Secret, expiredTimeDuration)
-		if err != nil {
-			respondWithError(w, 500, "could not make token")
-			return
-		}
-
-		//make a new fresh token
-		freshToken, err := auth.MakeRefreshToken()
-		if err != nil {
-			respondWithError(w, 500, "could not produce a fresh token")
-			return
-		}
-
-		//gets 60 days since that is the expire time of the refresh token
-		expireTimeRefresh := time.Hour * 24 * 60
-
-		//create a struct to input the refresh token into the database
-		refreshTokenDataBase := database.CreateRefreshTokenParams{
-			Token:     freshToken,
-			CreatedAt: time.Now().UTC(),
-			UpdatedAt: time.Now().UTC(),
-			UserID:    user.ID,
-			ExpiresAt: time.Now().UTC().Add(expireTimeRefresh),
-			RevokedAt: sql.NullTime{Valid: false},
-		}
-
-		_, err = counter.dbQueries.CreateRefreshToken(r.Context(), refreshTokenDataBase)
-		if err != nil {
-			errmsg := fmt.Sprintf("could not add refresh token to database: %v", err)
-			respondWithError(w, 500, errmsg)
-			return
-		}
-
-		respondWithJson(w, 200, userReturnEmail{
-			ID:            user.ID,
-			CreatedAT:     user.CreatedAt,
-			UpdatedAt:     user.UpdatedAt,
-			Email:         user.Email,
-			Token:         token,
-			RefreshToken:  freshToken,
-			Is_Chirpy_Red: user.IsChirpyRed,
-		})
-
-	})
-
-	//gets a new token for the given user and sets the lifespan to 1 hour
-	serveMux.HandleFunc("POST /api/refresh", func(w http.ResponseWriter, r *http.Request) {
-		token, err := auth.GetBearerToken(r.Header)
-		if err != nil {
-			respondWithError(w, 401, "Unauthorized")
-		}
-		user, err := counter.dbQueries.GetUserFromRefreshToken(r.Context(), token)
-		if err != nil {
-			respondWithError(w, 401, "token does not exist")
-			return
-		}
-		if time.Now().After(user.ExpiresAt) {
-			respondWithError(w, 401, "token has expired")
-			return
-		}
-
-		if user.RevokedAt.Valid {
-			respondWithError(w, 401, "token revoked")
-			return
-		}
-
-		newToken, err := auth.MakeJWT(user.UserID, counter.Secret, time.Hour)
-		if err != nil {
-			respondWithError(w, 500, "could not make new token")
-			return
-		}
-		respondWithJson(w, 200, tokenResponse{
-			Token: newToken,
-		})
-	})
-
-	//sets the revoke time to current time
-	serveMux.HandleFunc("POST /api/revoke", func(w http.ResponseWriter, r *http.Request) {
-		//gets token from header
-		refreshToken, err := auth.GetBearerToken(r.Header)
-		if err != nil {
-			respondWithError(w, 401, "could not get token from header")
-			return
-		}
-
-		//get current user
-		user, err := counter.dbQueries.GetUserFromRefreshToken(r.Context(), refreshToken)
-		if err != nil {
-			w.WriteHeader(204)
-			return
-		}
-		if time.Now().After(user.ExpiresAt) {
-			w.WriteHeader(204)
-			return
-		}
-
-		if user.RevokedAt.Valid {
-			w.WriteHeader(204)
-			return
-		}
-
-		//gets current time to set in the database revoked time
-		newTime := sql.NullTime{
-			Time:  time.Now(),
-			Valid: true,
-		}
+	defer storage.Close()
 
-		updatedToken := database.RevokeRefreshTokenParams{
-			RevokedAt: newTime,
-			UpdatedAt: time.Now(),
-			Token:     refreshToken,
-		}
-
-		//changes the revoke time, updated_at time for the given token
-		err = counter.dbQueries.RevokeRefreshToken(r.Context(), updatedToken)
+	if cfg.MigrateOnStart {
+		err = storage.migrate(context.Background())
 		if err != nil {
-			respondWithError(w, 500, "error updating the database")
-			return
+			logger.Error("could not migrate database", "error", err)
+			os.Exit(1)
 		}
+	}
 
-		//Sets header code to 204
-		w.WriteHeader(204)
+	//sends spans to the exporter picked in OTEL_TRACES_EXPORTER (otlp, stdout, file or none)
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter:    cfg.TraceExporter,
+		File:        cfg.TraceFile,
+		ServiceName: cfg.ServiceName,
 	})
+	if err != nil {
+		logger.Error("could not set up tracing", "error", err)
+		os.Exit(1)
+	}
 
-	//register the validate_chirp handler
-	//Makes sure chirp is valid
-	serveMux.HandleFunc("POST /api/chirps", func(w http.ResponseWriter, r *http.Request) {
-		//get the information and putting it into request
-		decoder := json.NewDecoder(r.Body)
-		request := chirpPostReq{}
-		err := decoder.Decode(&request)
-		//handling general error with decoding
-		if err != nil {
-			errMsg := fmt.Sprintf("error decoding: %v", err)
-			respondWithError(w, 500, errMsg)
-			return
-		}
-
-		token, err := auth.GetBearerToken(r.Header)
-		if err != nil {
-			errmsg := fmt.Sprintf("%v", err)
-			respondWithError(w, 401, errmsg)
-			return
-		}
-
-		userID, err := auth.ValidateJWT(token, counter.Secret)
-		if err != nil {
-			//fmt.Printf("userID: %v      request.UserID: %v", userID.String(), request.UserID.String())
-			respondWithError(w, 401, "Unauthorized")
-			return
-		}
-
-		//handling if the length of the request body(the message) is too long
-		if len(request.Body) > 140 {
-			respondWithError(w, 400, "Chirp is too long")
-			return
-		}
-
-		//handling if the request was successful
-		fmt.Printf("request body: %v", request.Body)
-		cleanText := ValidString(request.Body)
-		fmt.Println(cleanText)
-
-		input := database.CreateChirpParams{
-			Body:   cleanText,
-			UserID: userID,
-		}
-		myChirp, err := counter.dbQueries.CreateChirp(r.Context(), input)
-		if err != nil {
-			errMsg := fmt.Sprintf("error creating chirp: %v", err)
-			respondWithError(w, 500, errMsg)
-			return
-		}
-		valChirp := mapChirpToValidChirp(myChirp)
-		respondWithJson(w, 201, valChirp)
-	})
+	//probes for the load balancer / orchestrator
+	healthChecks := health.NewRegistry(cfg.HealthCheckTimeout)
+	if storage.db != nil {
+		healthChecks.Register("database", health.DBPing(storage.db))
+		healthChecks.Register("schema_version", health.SchemaVersion(storage.db, storage.migrator.Latest()))
+	}
 
-	//register getting all chirps in database
-	serveMux.HandleFunc("GET /api/chirps", func(w http.ResponseWriter, r *http.Request) {
-		authorID := r.URL.Query().Get("author_id")
-		sort := r.URL.Query().Get("sort")
-		var chirps []database.Chirp
-		if (authorID == "") && (sort == "desc") {
-			chirps, err = counter.dbQueries.GetAllChirpsDesc(r.Context())
-			if err != nil {
-				errmsg := fmt.Sprintf("error getting the chirps desc: %v", err)
-				respondWithError(w, 500, errmsg)
-				return
-			}
-		} else if (authorID == "") && (sort != "desc") {
-			chirps, err = counter.dbQueries.GetAllChirps(r.Context())
-			if err != nil {
-				errmsg := fmt.Sprintf("error getting all chirps asc Error: %v", err)
-				respondWithError(w, 500, errmsg)
-				return
-			}
-		} else if sort == "desc" {
-			chirps, err = counter.dbQueries.GetAllChirpFromIDDesc(r.Context(), uuid.MustParse(authorID))
-			if err != nil {
-				errmsg := fmt.Sprintf("error getting chirps of author in desc Error: %v", err)
-				respondWithError(w, 500, errmsg)
-				return
-			}
-		} else {
-			chirps, err = counter.dbQueries.GetAllChripsFromID(r.Context(), uuid.MustParse(authorID))
-			if err != nil {
-				errmsg := fmt.Sprintf("author not found Error: %v", err)
-				respondWithError(w, 404, errmsg)
-				return
-			}
-		}
-		var valChirps []validChirp
-		for _, val := range chirps {
-			tmpChirp := mapChirpToValidChirp(val)
-			valChirps = append(valChirps, tmpChirp)
-		}
-		respondWithJson(w, 200, valChirps)
-	})
+	//ctx is cancelled when we get SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	//Gets a specific chirp given with the ID
-	serveMux.HandleFunc("GET /api/chirps/{chirpID}", func(w http.ResponseWriter, r *http.Request) {
-		chirpID := r.PathValue("chirpID")
-		myChirp, err := counter.dbQueries.GetChirpWithID(r.Context(), uuid.MustParse(chirpID))
-		if err != nil {
-			errmsg := fmt.Sprintf("error getting this chirp: %v", err)
-			respondWithError(w, 404, errmsg)
-			return
-		}
-		respondWithJson(w, 200, mapChirpToValidChirp(myChirp))
-	})
+	//chirp events for /api/stream
+	hub := events.NewHub(cfg.StreamReplaySize)
 
-	//delete a specific chirp
-	serveMux.HandleFunc("DELETE /api/chirps/{chirpID}", func(w http.ResponseWriter, r *http.Request) {
-		userToken, err := auth.GetBearerToken(r.Header)
-		if err != nil {
-			errmsg := fmt.Sprintf("error getting token from header Error: %v", err)
-			respondWithError(w, 401, errmsg)
-			return
-		}
+	serverOpts := []server.Option{
+		server.WithLogger(logger),
+		server.WithHealth(healthChecks),
+		server.WithEvents(hub, storage.eventPublisher(ctx, hub, logger)),
+	}
+	if cfg.OpenAPIValidation {
+		//responses are only checked in dev, it costs a copy of every response body
+		serverOpts = append(serverOpts, server.WithSpecValidation(cfg.Platform == "dev"))
+	}
+	api := server.NewServer(server.Config{
+		Platform:     cfg.Platform,
+		Secret:       cfg.Secret,
+		PolkaKey:     cfg.PolkaKey,
+		AdminKey:     cfg.AdminKey,
+		MaxBodyBytes: cfg.MaxBodyBytes,
+		StaticDir:    ".",
 
-		userIDToken, err := auth.ValidateJWT(userToken, counter.Secret)
-		if err != nil {
-			errmsg := fmt.Sprintf("could not validate user from token Error: %v", err)
-			respondWithError(w, 500, errmsg)
-			return
-		}
+		MaxChirpLength:    cfg.MaxChirpLength,
+		MaxChirpLengthRed: cfg.MaxChirpLengthRed,
 
-		chirpID := r.PathValue("chirpID")
-		myChirp, err := counter.dbQueries.GetChirpWithID(r.Context(), uuid.MustParse(chirpID))
-		if err != nil {
-			errmsg := fmt.Sprintf("error getting chirp with given ID Error: %v", err)
-			respondWithError(w, 404, errmsg)
-			return
-		}
+		ReportHideThreshold: cfg.ReportHideThreshold,
 
-		if myChirp.UserID != userIDToken {
-			respondWithError(w, 403, "Unauthorized")
-			return
-		}
+		ProfanityReloadInterval: cfg.ProfanityReloadInterval,
+	}, storage.store, serverOpts...)
 
-		err = counter.dbQueries.DeleteChirpWithID(r.Context(), uuid.MustParse(chirpID))
-		if err != nil {
-			errmsg := fmt.Sprintf("could not delete chirp Error: %v", err)
-			respondWithError(w, 500, errmsg)
-			return
-		}
-		respondWithJson(w, 204, email{})
-	})
+	//making the server struct
+	myServer := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Port),
+		Handler:           api,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
 
-	serveMux.HandleFunc("POST /api/polka/webhooks", func(w http.ResponseWriter, r *http.Request) {
-		requestAPIKey, err := auth.GetAPIKey(r.Header)
-		if err != nil {
-			errmsg := fmt.Sprintf("there was an error getting the apiKey Error: %v", err)
-			respondWithError(w, 401, errmsg)
-			return
-		}
-		if requestAPIKey != counter.PolkaKey {
-			respondWithError(w, 401, "wrong api key")
-			return
-		}
+	//open streams would keep Shutdown waiting until the timeout
+	myServer.RegisterOnShutdown(hub.Close)
 
-		decoder := json.NewDecoder(r.Body)
-		request := polkaRequest{}
-		err = decoder.Decode(&request)
-		if err != nil {
-			errmsg := fmt.Sprintf("error decoding request Error: %v", err)
-			respondWithError(w, 500, errmsg)
-		}
-		if request.Event != "user.upgraded" {
-			respondWithJson(w, 204, email{})
-			return
-		}
+	//start an http server with the port and handler we created above/ handles any errors
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("starting server", "port", cfg.Port)
+		serverErr <- myServer.ListenAndServe()
+	}()
 
-		user, err := counter.dbQueries.GetUserFromID(r.Context(), uuid.MustParse(request.Data.UserID))
-		if err != nil {
-			errmsg := fmt.Sprintf("user cannot be found Error: %v", err)
-			respondWithError(w, 404, errmsg)
-			return
+	select {
+	case err = <-serverErr:
+		if err != http.ErrServerClosed {
+			logger.Error("server error", "error", err)
 		}
+		return
+	case <-ctx.Done():
+	}
 
-		err = counter.dbQueries.UpdateUserSubWithID(r.Context(), user.ID)
-		if err != nil {
-			errmsg := fmt.Sprintf("error updating subscription Error: %v", err)
-			respondWithError(w, 500, errmsg)
-			return
-		}
-		respondWithJson(w, 204, email{})
-	})
+	//fail /readyz first and keep serving for a while so load balancers stop sending requests here
+	healthChecks.SetReady(false)
+	if cfg.ShutdownDrain > 0 {
+		logger.Info("draining before shutdown", "drain", cfg.ShutdownDrain.String())
+		time.Sleep(cfg.ShutdownDrain)
+	}
 
-	//making the server struct
-	myServer := &http.Server{
-		Addr:    port,
-		Handler: serveMux,
+	//stop taking new connections and wait for in-flight requests to finish
+	logger.Info("shutting down server", "timeout", cfg.ShutdownTimeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	err = myServer.Shutdown(shutdownCtx)
+	if err != nil {
+		logger.Error("server did not shut down cleanly", "error", err)
 	}
 
-	//start an http server with the port and handler we created above/ handles any errors
-	log.Println("Starting server on port #", port)
-	err = myServer.ListenAndServe()
-	if err != http.ErrServerClosed {
-		log.Fatal("server error: ", err)
+	//flush any spans that are still waiting to be exported
+	err = shutdownTracing(shutdownCtx)
+	if err != nil {
+		logger.Error("could not flush traces", "error", err)
 	}
+	logger.Info("server stopped")
 }
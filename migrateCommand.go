@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/christianrm0821/Chirpy/internal/config"
+)
+
+const migrateUsage = "usage: chirpy migrate up|down|status [flags]"
+
+// runs "chirpy migrate ..." and returns the exit code
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	command := args[0]
+
+	cfg, err := config.LoadForMigrate(args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	logger := newLogger(os.Stderr, cfg.LogLevel)
+
+	storage, err := openStorage(cfg.DBURL, logger)
+	if err != nil {
+		logger.Error("could not open storage", "error", err)
+		return 1
+	}
+	defer storage.Close()
+	if storage.migrator == nil {
+		logger.Error("DB_URL does not point at a database that can be migrated")
+		return 1
+	}
+	migrator := storage.migrator
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		err = migrator.Down(ctx)
+	case "status":
+		err = migrator.Status(ctx, os.Stdout)
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	if err != nil {
+		logger.Error("migration failed", "command", command, "error", err)
+		return 1
+	}
+	return 0
+}
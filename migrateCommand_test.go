@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/christianrm0821/Chirpy/internal/health"
+)
+
+func TestMigrateCommand(t *testing.T) {
+	dbURL := "sqlite://" + filepath.Join(t.TempDir(), "chirpy.db")
+	tests := []struct {
+		args []string
+		code int
+	}{
+		{[]string{}, 2},
+		{[]string{"sideways", "-db-url", dbURL}, 2},
+		{[]string{"status", "-db-url", dbURL}, 0},
+		{[]string{"up", "-db-url", dbURL}, 0},
+		{[]string{"down", "-db-url", dbURL}, 0},
+		{[]string{"up", "-db-url", dbURL}, 0},
+		{[]string{"status", "-db-url", dbURL}, 0},
+		{[]string{"up", "-db-url", "memory://"}, 1},
+	}
+	for _, tc := range tests {
+		code := runMigrate(tc.args)
+		if code != tc.code {
+			t.Errorf("chirpy migrate %v: was expecting exit code %d but got %d", tc.args, tc.code, code)
+		}
+	}
+}
+
+// what the server does with MIGRATE_ON_START=true
+func TestMigrateOnStart(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+
+	storage, err := openStorage("sqlite://"+filepath.Join(t.TempDir(), "chirpy.db"), logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer storage.Close()
+	schemaVersion := health.SchemaVersion(storage.db, storage.migrator.Latest())
+	if schemaVersion.Check(ctx) == nil {
+		t.Error("was expecting the schema check to fail before migrating")
+	}
+	err = storage.migrate(ctx)
+	if err != nil {
+		t.Fatalf("was not expecting an error but got %v", err)
+	}
+	err = schemaVersion.Check(ctx)
+	if err != nil {
+		t.Errorf("was expecting the database to be at the newest migration but got %v", err)
+	}
+
+	memory, err := openStorage("memory://", logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = memory.migrate(ctx)
+	if err != nil {
+		t.Errorf("was expecting nothing to migrate in memory but got %v", err)
+	}
+}
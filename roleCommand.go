@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/christianrm0821/Chirpy/internal/config"
+	"github.com/christianrm0821/Chirpy/internal/store"
+)
+
+const roleUsage = "usage: chirpy role grant <email> user|moderator|admin [flags] or chirpy role revoke <email> [flags]"
+
+// runs "chirpy role ..." and returns the exit code
+// it is how the first admin is made when ADMIN_KEY is not set
+func runRole(args []string) int {
+	if len(args) < 2 {
+		fmt.Fprintln(os.Stderr, roleUsage)
+		return 2
+	}
+	command, userEmail, flags := args[0], args[1], args[2:]
+	role := store.RoleUser
+	switch command {
+	case "grant":
+		if len(flags) == 0 {
+			fmt.Fprintln(os.Stderr, roleUsage)
+			return 2
+		}
+		role, flags = flags[0], flags[1:]
+		if role != store.RoleUser && role != store.RoleModerator && role != store.RoleAdmin {
+			fmt.Fprintln(os.Stderr, roleUsage)
+			return 2
+		}
+	case "revoke":
+	default:
+		fmt.Fprintln(os.Stderr, roleUsage)
+		return 2
+	}
+
+	cfg, err := config.LoadForMigrate(flags)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	logger := newLogger(os.Stderr, cfg.LogLevel)
+
+	storage, err := openStorage(cfg.DBURL, logger)
+	if err != nil {
+		logger.Error("could not open storage", "error", err)
+		return 1
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+	user, err := storage.store.GetUserByEmail(ctx, userEmail)
+	if err != nil {
+		logger.Error("could not find user", "email", userEmail, "error", err)
+		return 1
+	}
+	err = storage.store.SetUserRole(ctx, user.ID, role)
+	if err != nil {
+		logger.Error("could not set role", "email", userEmail, "error", err)
+		return 1
+	}
+	logger.Info("changed role", "user_id", user.ID, "role", role)
+	return 0
+}
@@ -0,0 +1,3 @@
+-- name: AddConversationMember :exec
+insert into conversation_members(conversation_id, user_id, joined_at, last_read_at)
+values(sqlc.arg('conversation_id'), sqlc.arg('user_id'), sqlc.arg('joined_at'), null);
@@ -0,0 +1,6 @@
+-- name: ClaimModerationCase :one
+update moderation_cases
+set claimed_by = sqlc.arg('moderator'), updated_at = sqlc.arg('now'), claimed_at = sqlc.arg('now')
+where id = sqlc.arg('id') and resolved_at is null
+and (claimed_by = '' or claimed_by = sqlc.arg('moderator'))
+returning *;
@@ -0,0 +1,4 @@
+-- name: CreateConversation :one
+insert into conversations(id, created_at, updated_at, created_by)
+values(sqlc.arg('id'), sqlc.arg('created_at'), sqlc.arg('created_at'), sqlc.arg('created_by'))
+returning *;
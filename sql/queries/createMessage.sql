@@ -0,0 +1,4 @@
+-- name: CreateMessage :one
+insert into messages(id, created_at, conversation_id, sender_id, body)
+values(sqlc.arg('id'), sqlc.arg('created_at'), sqlc.arg('conversation_id'), sqlc.arg('sender_id'), sqlc.arg('body'))
+returning *;
@@ -0,0 +1,3 @@
+-- name: DeleteProfanityTerm :execrows
+delete from profanity_terms
+where id = sqlc.arg('id');
@@ -0,0 +1,5 @@
+-- name: FlagModerationCase :one
+update moderation_cases
+set flagged_terms = sqlc.arg('flagged_terms'), updated_at = sqlc.arg('updated_at')
+where id = sqlc.arg('id')
+returning *;
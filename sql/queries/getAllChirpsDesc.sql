@@ -1,3 +1,4 @@
 -- name: GetAllChirpsDesc :many
 select * from chirps
+where hidden_at is null
 order by created_at desc;
\ No newline at end of file
@@ -1,4 +1,4 @@
 -- name: GetAllChripsFromID :many
 select * from chirps
-where user_id = $1
+where user_id = $1 and hidden_at is null
 order by created_at asc;
\ No newline at end of file
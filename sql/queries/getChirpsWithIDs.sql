@@ -0,0 +1,3 @@
+-- name: GetChirpsWithIDs :many
+select * from chirps
+where id = any(sqlc.arg('ids')::uuid[]);
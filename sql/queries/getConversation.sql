@@ -0,0 +1,3 @@
+-- name: GetConversation :one
+select * from conversations
+where id = $1;
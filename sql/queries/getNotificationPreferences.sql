@@ -0,0 +1,3 @@
+-- name: GetNotificationPreferences :many
+select type, enabled from notification_preferences
+where user_id = $1;
@@ -0,0 +1,3 @@
+-- name: GetUserByHandle :one
+select * from users
+where handle = $1;
\ No newline at end of file
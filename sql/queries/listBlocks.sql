@@ -0,0 +1,4 @@
+-- name: ListBlocks :many
+select * from blocks
+where blocker_id = $1
+order by created_at desc, blocked_id;
@@ -0,0 +1,16 @@
+-- name: ListChirpsPageDesc :many
+select * from chirps
+where (sqlc.narg('author_id')::uuid is null or user_id = sqlc.narg('author_id'))
+and (sqlc.narg('after_created_at')::timestamp is null or (created_at, id) < (sqlc.narg('after_created_at'), sqlc.narg('after_id')::uuid))
+and (sqlc.narg('viewer_id')::uuid is null or user_id not in (
+    select blocked_id from blocks where blocker_id = sqlc.narg('viewer_id')
+    union
+    select blocker_id from blocks where blocked_id = sqlc.narg('viewer_id')
+    union
+    select muted_id from mutes
+    where muter_id = sqlc.narg('viewer_id') and sqlc.narg('author_id')::uuid is null
+    and (expires_at is null or expires_at > sqlc.arg('now')::timestamp)
+))
+and (hidden_at is null or user_id = sqlc.narg('viewer_id'))
+order by created_at desc, id desc
+limit sqlc.arg('page_size');
@@ -0,0 +1,4 @@
+-- name: ListContentFilters :many
+select * from content_filters
+where user_id = sqlc.arg('user_id') and (expires_at is null or expires_at > sqlc.arg('now')::timestamp)
+order by created_at desc, id;
@@ -0,0 +1,4 @@
+-- name: ListConversationMembers :many
+select * from conversation_members
+where conversation_id = $1
+order by joined_at, user_id;
@@ -0,0 +1,4 @@
+-- name: ListMembersOfUserConversations :many
+select * from conversation_members
+where conversation_id in (select conversation_id from conversation_members m where m.user_id = $1)
+order by joined_at, user_id;
@@ -0,0 +1,6 @@
+-- name: ListMessages :many
+select * from messages
+where conversation_id = sqlc.arg('conversation_id')
+and (sqlc.narg('after_created_at')::timestamp is null or (created_at, id) < (sqlc.narg('after_created_at'), sqlc.narg('after_id')::uuid))
+order by created_at desc, id desc
+limit sqlc.arg('page_size');
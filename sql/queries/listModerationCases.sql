@@ -0,0 +1,5 @@
+-- name: ListModerationCases :many
+select * from moderation_cases
+where (sqlc.arg('status')::text = 'resolved') = (resolved_at is not null)
+and (sqlc.arg('status')::text not in ('open', 'claimed') or (claimed_by = '') = (sqlc.arg('status')::text = 'open'))
+order by created_at, id;
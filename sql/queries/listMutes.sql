@@ -0,0 +1,4 @@
+-- name: ListMutes :many
+select * from mutes
+where muter_id = sqlc.arg('muter_id') and (expires_at is null or expires_at > sqlc.arg('now')::timestamp)
+order by created_at desc, muted_id;
@@ -0,0 +1,6 @@
+-- name: ListNotifications :many
+select * from notifications
+where user_id = sqlc.arg('user_id')
+and (sqlc.narg('after_updated_at')::timestamp is null or (updated_at, id) < (sqlc.narg('after_updated_at'), sqlc.narg('after_id')::uuid))
+order by updated_at desc, id desc
+limit sqlc.arg('page_size');
@@ -0,0 +1,3 @@
+-- name: ListProfanityTerms :many
+select * from profanity_terms
+order by term;
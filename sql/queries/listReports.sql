@@ -0,0 +1,4 @@
+-- name: ListReports :many
+select * from reports
+where case_id = $1
+order by created_at, id;
@@ -0,0 +1,4 @@
+-- name: LockModerationCase :one
+select id from moderation_cases
+where id = $1
+for update;
@@ -0,0 +1,4 @@
+-- name: MarkConversationRead :execrows
+update conversation_members
+set last_read_at = sqlc.arg('last_read_at')
+where conversation_id = sqlc.arg('conversation_id') and user_id = sqlc.arg('user_id');
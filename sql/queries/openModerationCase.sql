@@ -0,0 +1,6 @@
+-- name: OpenModerationCase :one
+insert into moderation_cases(id, created_at, updated_at, chirp_id, author_id, body)
+values(sqlc.arg('id'), sqlc.arg('created_at'), sqlc.arg('created_at'), sqlc.arg('chirp_id'), sqlc.arg('author_id'), sqlc.arg('body'))
+on conflict(chirp_id) where resolved_at is null
+do update set updated_at = moderation_cases.updated_at
+returning *;
@@ -0,0 +1,7 @@
+-- name: ResolveModerationCase :one
+update moderation_cases
+set resolution = sqlc.arg('resolution'), note = sqlc.arg('note'), resolved_by = sqlc.arg('moderator'),
+    updated_at = sqlc.arg('now'), resolved_at = sqlc.arg('now')
+where id = sqlc.arg('id') and resolved_at is null
+and (claimed_by = '' or claimed_by = sqlc.arg('moderator'))
+returning *;
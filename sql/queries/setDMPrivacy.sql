@@ -0,0 +1,4 @@
+-- name: SetDMPrivacy :exec
+insert into dm_settings(user_id, privacy)
+values(sqlc.arg('user_id'), sqlc.arg('privacy'))
+on conflict(user_id) do update set privacy = excluded.privacy;
@@ -0,0 +1,4 @@
+-- name: SetNotificationPreference :exec
+insert into notification_preferences(user_id, type, enabled)
+values(sqlc.arg('user_id'), sqlc.arg('type'), sqlc.arg('enabled'))
+on conflict(user_id, type) do update set enabled = excluded.enabled;
@@ -0,0 +1,4 @@
+-- name: SetUserHandle :execrows
+update users
+set handle = sqlc.arg('handle'), updated_at = sqlc.arg('updated_at')
+where id = sqlc.arg('id');
@@ -0,0 +1,4 @@
+-- name: SetUserRole :execrows
+update users
+set role = sqlc.arg('role'), updated_at = sqlc.arg('updated_at')
+where id = sqlc.arg('id');
@@ -0,0 +1,4 @@
+-- name: SetUserSuspended :execrows
+update users
+set suspended_at = sqlc.narg('suspended_at')
+where id = sqlc.arg('id');
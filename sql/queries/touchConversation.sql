@@ -0,0 +1,4 @@
+-- name: TouchConversation :exec
+update conversations
+set updated_at = sqlc.arg('updated_at')
+where id = sqlc.arg('id');
@@ -0,0 +1,3 @@
+-- name: UnblockUser :execrows
+delete from blocks
+where blocker_id = sqlc.arg('blocker_id') and blocked_id = sqlc.arg('blocked_id');
@@ -0,0 +1,36 @@
+-- +goose Up
+create table notifications(
+    id UUID primary key,
+    created_at timestamp not null,
+    updated_at timestamp not null,
+    user_id UUID not null,
+    type text not null,
+    actor_id UUID,
+    chirp_id UUID,
+    read_at timestamp,
+    constraint fk_notifications_user
+        foreign key(user_id)
+        references users(id) on delete cascade,
+    constraint fk_notifications_actor
+        foreign key(actor_id)
+        references users(id) on delete set null,
+    constraint fk_notifications_chirp
+        foreign key(chirp_id)
+        references chirps(id) on delete cascade
+);
+
+create index notifications_user_updated on notifications(user_id, updated_at desc, id desc);
+
+create table notification_preferences(
+    user_id UUID not null,
+    type text not null,
+    enabled boolean not null,
+    primary key(user_id, type),
+    constraint fk_notification_preferences_user
+        foreign key(user_id)
+        references users(id) on delete cascade
+);
+
+-- +goose Down
+drop table notification_preferences;
+drop table notifications;
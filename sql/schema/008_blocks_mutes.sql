@@ -0,0 +1,33 @@
+-- +goose Up
+create table blocks(
+    blocker_id UUID not null,
+    blocked_id UUID not null,
+    created_at timestamp not null,
+    primary key(blocker_id, blocked_id),
+    constraint fk_blocks_blocker
+        foreign key(blocker_id)
+        references users(id) on delete cascade,
+    constraint fk_blocks_blocked
+        foreign key(blocked_id)
+        references users(id) on delete cascade
+);
+
+create index blocks_blocked on blocks(blocked_id);
+
+create table mutes(
+    muter_id UUID not null,
+    muted_id UUID not null,
+    created_at timestamp not null,
+    expires_at timestamp,
+    primary key(muter_id, muted_id),
+    constraint fk_mutes_muter
+        foreign key(muter_id)
+        references users(id) on delete cascade,
+    constraint fk_mutes_muted
+        foreign key(muted_id)
+        references users(id) on delete cascade
+);
+
+-- +goose Down
+drop table mutes;
+drop table blocks;
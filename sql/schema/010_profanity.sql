@@ -0,0 +1,27 @@
+-- +goose Up
+create table profanity_terms(
+    id UUID primary key,
+    created_at timestamp not null,
+    updated_at timestamp not null,
+    term text not null unique,
+    action text not null
+);
+
+-- the words chirpy always masked
+insert into profanity_terms(id, created_at, updated_at, term, action) values
+    ('6a1d3c1e-5b8f-4a52-9d0e-1f6c2b7a9e01', now(), now(), 'kerfuffle', 'mask'),
+    ('6a1d3c1e-5b8f-4a52-9d0e-1f6c2b7a9e02', now(), now(), 'sharbert', 'mask'),
+    ('6a1d3c1e-5b8f-4a52-9d0e-1f6c2b7a9e03', now(), now(), 'fornax', 'mask');
+
+create table flagged_chirps(
+    chirp_id UUID primary key,
+    created_at timestamp not null,
+    terms text not null,
+    constraint fk_flagged_chirps_chirps
+        foreign key(chirp_id)
+        references chirps(id) on delete cascade
+);
+
+-- +goose Down
+drop table flagged_chirps;
+drop table profanity_terms;
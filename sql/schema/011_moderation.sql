@@ -0,0 +1,77 @@
+-- +goose Up
+alter table chirps
+add hidden_at timestamp;
+
+alter table users
+add suspended_at timestamp;
+
+create table moderation_cases(
+    id UUID primary key,
+    created_at timestamp not null,
+    updated_at timestamp not null,
+    -- not a foreign key, the case is kept when the chirp is deleted
+    chirp_id UUID not null,
+    author_id UUID not null,
+    body text not null,
+    flagged_terms text not null default '',
+    report_count integer not null default 0,
+    claimed_by text not null default '',
+    claimed_at timestamp,
+    resolution text not null default '',
+    note text not null default '',
+    resolved_by text not null default '',
+    resolved_at timestamp,
+    constraint fk_moderation_cases_author
+        foreign key(author_id)
+        references users(id) on delete cascade
+);
+
+-- a chirp has one unresolved case at a time
+create unique index moderation_cases_open_chirp on moderation_cases(chirp_id) where resolved_at is null;
+
+create table reports(
+    id UUID primary key,
+    created_at timestamp not null,
+    case_id UUID not null,
+    chirp_id UUID not null,
+    reporter_id UUID not null,
+    reason text not null,
+    comment text not null default '',
+    unique(case_id, reporter_id),
+    constraint fk_reports_case
+        foreign key(case_id)
+        references moderation_cases(id) on delete cascade,
+    constraint fk_reports_reporter
+        foreign key(reporter_id)
+        references users(id) on delete cascade
+);
+
+-- chirps flagged by moderate profanity terms are cases now
+insert into moderation_cases(id, created_at, updated_at, chirp_id, author_id, body, flagged_terms)
+select gen_random_uuid(), f.created_at, f.created_at, f.chirp_id, c.user_id, c.body, f.terms
+from flagged_chirps f join chirps c on c.id = f.chirp_id;
+
+drop table flagged_chirps;
+
+-- +goose Down
+create table flagged_chirps(
+    chirp_id UUID primary key,
+    created_at timestamp not null,
+    terms text not null,
+    constraint fk_flagged_chirps_chirps
+        foreign key(chirp_id)
+        references chirps(id) on delete cascade
+);
+
+insert into flagged_chirps(chirp_id, created_at, terms)
+select chirp_id, created_at, flagged_terms from moderation_cases
+where flagged_terms <> '' and resolved_at is null and chirp_id in (select id from chirps);
+
+drop table reports;
+drop table moderation_cases;
+
+alter table users
+drop column suspended_at;
+
+alter table chirps
+drop column hidden_at;
@@ -0,0 +1,11 @@
+-- +goose Up
+alter table users
+add handle text;
+
+create unique index users_handle on users(handle);
+
+-- +goose Down
+drop index users_handle;
+
+alter table users
+drop column handle;
@@ -0,0 +1,4 @@
+-- name: BlockUser :exec
+insert into blocks(blocker_id, blocked_id, created_at)
+values(sqlc.arg('blocker_id'), sqlc.arg('blocked_id'), sqlc.arg('created_at'))
+on conflict(blocker_id, blocked_id) do nothing;
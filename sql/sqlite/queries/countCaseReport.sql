@@ -0,0 +1,5 @@
+-- name: CountCaseReport :one
+update moderation_cases
+set report_count = report_count + 1, updated_at = sqlc.arg('updated_at')
+where id = sqlc.arg('id')
+returning *;
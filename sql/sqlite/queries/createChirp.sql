@@ -0,0 +1,10 @@
+-- name: CreateChirp :one
+Insert into chirps(id,created_at,updated_at,body,user_id)
+values(
+    ?,
+    ?,
+    ?,
+    ?,
+    ?
+)
+returning *;
@@ -0,0 +1,5 @@
+-- name: CreateContentFilter :one
+insert into content_filters(id, created_at, user_id, kind, value, scope_timeline, scope_notifications, expires_at)
+values(sqlc.arg('id'), sqlc.arg('created_at'), sqlc.arg('user_id'), sqlc.arg('kind'), sqlc.arg('value'),
+    sqlc.arg('scope_timeline'), sqlc.arg('scope_notifications'), sqlc.narg('expires_at'))
+returning *;
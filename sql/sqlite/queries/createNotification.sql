@@ -0,0 +1,13 @@
+-- name: CreateNotification :one
+insert into notifications(id, created_at, updated_at, user_id, type, actor_id, chirp_id, read_at)
+values(
+    sqlc.arg('id'),
+    sqlc.arg('created_at'),
+    sqlc.arg('created_at'),
+    sqlc.arg('user_id'),
+    sqlc.arg('type'),
+    sqlc.narg('actor_id'),
+    sqlc.narg('chirp_id'),
+    null
+)
+returning *;
@@ -0,0 +1,4 @@
+-- name: CreateProfanityTerm :one
+insert into profanity_terms(id, created_at, updated_at, term, action)
+values(sqlc.arg('id'), sqlc.arg('created_at'), sqlc.arg('updated_at'), sqlc.arg('term'), sqlc.arg('action'))
+returning *;
@@ -0,0 +1,11 @@
+-- name: CreateRefreshToken :one
+Insert into refresh_tokens(token, created_at,updated_at, user_id, expires_at, revoked_at)
+values(
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?
+)
+returning *;
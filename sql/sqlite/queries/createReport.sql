@@ -0,0 +1,3 @@
+-- name: CreateReport :exec
+insert into reports(id, created_at, case_id, chirp_id, reporter_id, reason, comment)
+values(sqlc.arg('id'), sqlc.arg('created_at'), sqlc.arg('case_id'), sqlc.arg('chirp_id'), sqlc.arg('reporter_id'), sqlc.arg('reason'), sqlc.arg('comment'));
@@ -0,0 +1,10 @@
+-- name: CreateUser :one
+Insert into users(id,created_at,updated_at,email,hashed_password)
+values(
+    ?,
+    ?,
+    ?,
+    ?,
+    ?
+)
+returning *;
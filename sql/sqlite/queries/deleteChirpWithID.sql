@@ -0,0 +1,3 @@
+-- name: DeleteChirpWithID :exec
+delete from chirps
+where id = ?;
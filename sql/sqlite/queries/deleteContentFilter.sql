@@ -0,0 +1,3 @@
+-- name: DeleteContentFilter :execrows
+delete from content_filters
+where id = sqlc.arg('id') and user_id = sqlc.arg('user_id');
@@ -0,0 +1,2 @@
+-- name: DeleteUsers :exec
+delete from users;
\ No newline at end of file
@@ -0,0 +1,10 @@
+-- name: FindDirectConversation :one
+select * from conversations
+where id in (
+    select a.conversation_id from conversation_members a where a.user_id = sqlc.arg('user_a')
+    intersect
+    select b.conversation_id from conversation_members b where b.user_id = sqlc.arg('user_b')
+)
+and (select count(*) from conversation_members m where m.conversation_id = conversations.id) = 2
+order by created_at, id
+limit 1;
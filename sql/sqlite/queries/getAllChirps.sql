@@ -0,0 +1,4 @@
+-- name: GetAllChirps :many
+select * from chirps
+where hidden_at is null
+order by created_at asc;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- name: GetAllChirpsFromIDDesc :many
+select * from chirps
+where user_id = ? and hidden_at is null
+order by created_at desc;
@@ -0,0 +1,3 @@
+-- name: GetChirpWithID :one
+select * from chirps
+where id = ?;
@@ -0,0 +1,3 @@
+-- name: GetChirpsWithIDs :many
+select * from chirps
+where id in (sqlc.slice('ids'));
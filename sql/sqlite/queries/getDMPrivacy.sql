@@ -0,0 +1,3 @@
+-- name: GetDMPrivacy :one
+select privacy from dm_settings
+where user_id = ?;
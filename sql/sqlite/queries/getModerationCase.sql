@@ -0,0 +1,3 @@
+-- name: GetModerationCase :one
+select * from moderation_cases
+where id = ?;
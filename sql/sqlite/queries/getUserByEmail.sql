@@ -0,0 +1,3 @@
+-- name: GetUserByEmail :one
+select * from users
+where email = ?;
@@ -0,0 +1,3 @@
+-- name: GetUserByHandle :one
+select * from users
+where handle = ?;
\ No newline at end of file
@@ -0,0 +1,3 @@
+-- name: GetUserFromID :one
+select * from users
+where id = ?;
@@ -0,0 +1,3 @@
+-- name: GetUserFromRefreshToken :one
+select * from refresh_tokens
+where token = ?;
@@ -0,0 +1,7 @@
+-- name: HiddenUserIDs :many
+select b.blocked_id as user_id from blocks b where b.blocker_id = sqlc.arg('viewer_id')
+union
+select b.blocker_id from blocks b where b.blocked_id = sqlc.arg('viewer_id')
+union
+select m.muted_id from mutes m
+where m.muter_id = sqlc.arg('viewer_id') and (m.expires_at is null or m.expires_at > sqlc.arg('now'));
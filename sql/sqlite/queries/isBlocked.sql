@@ -0,0 +1,4 @@
+-- name: IsBlocked :one
+select count(*) from blocks
+where (blocker_id = sqlc.arg('user_a') and blocked_id = sqlc.arg('user_b'))
+or (blocker_id = sqlc.arg('user_b') and blocked_id = sqlc.arg('user_a'));
@@ -0,0 +1,17 @@
+-- name: ListChirpsPageDesc :many
+select * from chirps
+where (sqlc.narg('author_id') is null or user_id = sqlc.narg('author_id'))
+and (sqlc.narg('after_created_at') is null or chirps.created_at < sqlc.narg('after_created_at')
+    or (chirps.created_at = sqlc.narg('after_created_at') and chirps.id < sqlc.narg('after_id')))
+and (sqlc.narg('viewer_id') is null or user_id not in (
+    select blocked_id from blocks where blocker_id = sqlc.narg('viewer_id')
+    union
+    select blocker_id from blocks where blocked_id = sqlc.narg('viewer_id')
+    union
+    select muted_id from mutes
+    where muter_id = sqlc.narg('viewer_id') and sqlc.narg('author_id') is null
+    and (expires_at is null or expires_at > sqlc.arg('now'))
+))
+and (hidden_at is null or user_id = sqlc.narg('viewer_id'))
+order by created_at desc, id desc
+limit sqlc.arg('page_size');
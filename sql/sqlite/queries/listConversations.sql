@@ -0,0 +1,4 @@
+-- name: ListConversations :many
+select * from conversations
+where id in (select conversation_id from conversation_members where user_id = ?)
+order by updated_at desc, id desc;
@@ -0,0 +1,7 @@
+-- name: ListMessages :many
+select * from messages
+where conversation_id = sqlc.arg('conversation_id')
+and (sqlc.narg('after_created_at') is null or created_at < sqlc.narg('after_created_at')
+    or (created_at = sqlc.narg('after_created_at') and id < sqlc.narg('after_id')))
+order by created_at desc, id desc
+limit sqlc.arg('page_size');
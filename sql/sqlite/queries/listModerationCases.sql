@@ -0,0 +1,6 @@
+-- name: ListModerationCases :many
+select * from moderation_cases
+where (resolved_at is not null) = (cast(sqlc.arg('status') as text) = 'resolved')
+and (claimed_by = '' or sqlc.arg('status') <> 'open')
+and (claimed_by <> '' or sqlc.arg('status') <> 'claimed')
+order by created_at, id;
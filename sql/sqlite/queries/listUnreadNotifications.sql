@@ -0,0 +1,8 @@
+-- name: ListUnreadNotifications :many
+select * from notifications
+where user_id = sqlc.arg('user_id')
+and read_at is null
+and (sqlc.narg('after_updated_at') is null or updated_at < sqlc.narg('after_updated_at')
+    or (updated_at = sqlc.narg('after_updated_at') and id < sqlc.narg('after_id')))
+order by updated_at desc, id desc
+limit sqlc.arg('page_size');
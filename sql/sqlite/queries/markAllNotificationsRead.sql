@@ -0,0 +1,4 @@
+-- name: MarkAllNotificationsRead :exec
+update notifications
+set read_at = sqlc.arg('read_at')
+where user_id = sqlc.arg('user_id') and read_at is null;
@@ -0,0 +1,4 @@
+-- name: MarkNotificationRead :execrows
+update notifications
+set read_at = coalesce(read_at, sqlc.arg('read_at'))
+where id = sqlc.arg('id') and user_id = sqlc.arg('user_id');
@@ -0,0 +1,4 @@
+-- name: MuteUser :exec
+insert into mutes(muter_id, muted_id, created_at, expires_at)
+values(sqlc.arg('muter_id'), sqlc.arg('muted_id'), sqlc.arg('created_at'), sqlc.narg('expires_at'))
+on conflict(muter_id, muted_id) do update set created_at = excluded.created_at, expires_at = excluded.expires_at;
@@ -0,0 +1,4 @@
+-- name: RevokeRefreshToken :exec
+update refresh_tokens
+set revoked_at = ?, updated_at = ?
+where token = ?;
@@ -0,0 +1,4 @@
+-- name: SetChirpHidden :execrows
+update chirps
+set hidden_at = sqlc.narg('hidden_at')
+where id = sqlc.arg('id');
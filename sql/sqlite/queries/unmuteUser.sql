@@ -0,0 +1,3 @@
+-- name: UnmuteUser :execrows
+delete from mutes
+where muter_id = sqlc.arg('muter_id') and muted_id = sqlc.arg('muted_id');
@@ -0,0 +1,4 @@
+-- name: UpdatePasswordEmailFromUserID :exec
+update users
+set hashed_password = ?, email = ?
+where id = ?;
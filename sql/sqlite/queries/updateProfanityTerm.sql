@@ -0,0 +1,4 @@
+-- name: UpdateProfanityTerm :one
+update profanity_terms set term = sqlc.arg('term'), action = sqlc.arg('action'), updated_at = sqlc.arg('updated_at')
+where id = sqlc.arg('id')
+returning *;
@@ -0,0 +1,4 @@
+-- name: UpdateUserSubWithID :exec
+update users
+set is_chirpy_red = true
+where id = ?;
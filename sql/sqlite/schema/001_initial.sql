@@ -0,0 +1,37 @@
+-- +goose Up
+create table users(
+    id text primary key,
+    created_at datetime not null,
+    updated_at datetime not null,
+    email text unique not null,
+    hashed_password text not null default 'unset',
+    is_chirpy_red boolean not null default false
+);
+
+create table chirps(
+    id text primary key,
+    created_at datetime not null,
+    updated_at datetime not null,
+    body text not null,
+    user_id text not null,
+    constraint fk_uid_users
+        foreign key(user_id)
+        references users(id) on delete cascade
+);
+
+create table refresh_tokens(
+    token text primary key,
+    created_at datetime not null,
+    updated_at datetime not null,
+    user_id text not null,
+    expires_at datetime not null,
+    revoked_at datetime,
+    constraint fk_uid_users
+        foreign key(user_id)
+        references users(id) on delete cascade
+);
+
+-- +goose Down
+drop table refresh_tokens;
+drop table chirps;
+drop table users;
@@ -0,0 +1,57 @@
+-- +goose Up
+create table conversations(
+    id text primary key,
+    created_at datetime not null,
+    updated_at datetime not null,
+    created_by text not null,
+    constraint fk_conversations_created_by
+        foreign key(created_by)
+        references users(id) on delete cascade
+);
+
+create table conversation_members(
+    conversation_id text not null,
+    user_id text not null,
+    joined_at datetime not null,
+    last_read_at datetime,
+    primary key(conversation_id, user_id),
+    constraint fk_conversation_members_conversation
+        foreign key(conversation_id)
+        references conversations(id) on delete cascade,
+    constraint fk_conversation_members_user
+        foreign key(user_id)
+        references users(id) on delete cascade
+);
+
+create index conversation_members_user on conversation_members(user_id);
+
+create table messages(
+    id text primary key,
+    created_at datetime not null,
+    conversation_id text not null,
+    sender_id text not null,
+    body text not null,
+    constraint fk_messages_conversation
+        foreign key(conversation_id)
+        references conversations(id) on delete cascade,
+    constraint fk_messages_sender
+        foreign key(sender_id)
+        references users(id) on delete cascade
+);
+
+create index messages_conversation_created on messages(conversation_id, created_at desc, id desc);
+
+create table dm_settings(
+    user_id text primary key,
+    -- there are no follows yet so there is no followers only setting
+    privacy text not null check (privacy in ('everyone', 'nobody')),
+    constraint fk_dm_settings_user
+        foreign key(user_id)
+        references users(id) on delete cascade
+);
+
+-- +goose Down
+drop table dm_settings;
+drop table messages;
+drop table conversation_members;
+drop table conversations;
@@ -0,0 +1,33 @@
+-- +goose Up
+create table blocks(
+    blocker_id text not null,
+    blocked_id text not null,
+    created_at datetime not null,
+    primary key(blocker_id, blocked_id),
+    constraint fk_blocks_blocker
+        foreign key(blocker_id)
+        references users(id) on delete cascade,
+    constraint fk_blocks_blocked
+        foreign key(blocked_id)
+        references users(id) on delete cascade
+);
+
+create index blocks_blocked on blocks(blocked_id);
+
+create table mutes(
+    muter_id text not null,
+    muted_id text not null,
+    created_at datetime not null,
+    expires_at datetime,
+    primary key(muter_id, muted_id),
+    constraint fk_mutes_muter
+        foreign key(muter_id)
+        references users(id) on delete cascade,
+    constraint fk_mutes_muted
+        foreign key(muted_id)
+        references users(id) on delete cascade
+);
+
+-- +goose Down
+drop table mutes;
+drop table blocks;
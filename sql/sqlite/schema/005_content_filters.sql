@@ -0,0 +1,19 @@
+-- +goose Up
+create table content_filters(
+    id text primary key,
+    created_at datetime not null,
+    user_id text not null,
+    kind text not null,
+    value text not null,
+    scope_timeline boolean not null,
+    scope_notifications boolean not null,
+    expires_at datetime,
+    constraint fk_content_filters_users
+        foreign key(user_id)
+        references users(id) on delete cascade
+);
+
+create index content_filters_user on content_filters(user_id);
+
+-- +goose Down
+drop table content_filters;
@@ -0,0 +1,27 @@
+-- +goose Up
+create table profanity_terms(
+    id text primary key,
+    created_at datetime not null,
+    updated_at datetime not null,
+    term text not null unique,
+    action text not null
+);
+
+-- the words chirpy always masked
+insert into profanity_terms(id, created_at, updated_at, term, action) values
+    ('6a1d3c1e-5b8f-4a52-9d0e-1f6c2b7a9e01', strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'), strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'), 'kerfuffle', 'mask'),
+    ('6a1d3c1e-5b8f-4a52-9d0e-1f6c2b7a9e02', strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'), strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'), 'sharbert', 'mask'),
+    ('6a1d3c1e-5b8f-4a52-9d0e-1f6c2b7a9e03', strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'), strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'), 'fornax', 'mask');
+
+create table flagged_chirps(
+    chirp_id text primary key,
+    created_at datetime not null,
+    terms text not null,
+    constraint fk_flagged_chirps_chirps
+        foreign key(chirp_id)
+        references chirps(id) on delete cascade
+);
+
+-- +goose Down
+drop table flagged_chirps;
+drop table profanity_terms;
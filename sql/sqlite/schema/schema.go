@@ -0,0 +1,7 @@
+// the goose migrations for sqlite, embedded so the binary can run them itself
+package schema
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/christianrm0821/Chirpy/internal/database"
+	"github.com/christianrm0821/Chirpy/internal/database/sqlitedb"
+	"github.com/christianrm0821/Chirpy/internal/events"
+	"github.com/christianrm0821/Chirpy/internal/migrate"
+	"github.com/christianrm0821/Chirpy/internal/store"
+	"github.com/christianrm0821/Chirpy/internal/tracing"
+	_ "github.com/lib/pq"
+)
+
+// the store the api runs on plus the database behind it
+// db and migrator are nil for the in-memory store
+type storage struct {
+	store    store.Store
+	db       *sql.DB
+	migrator *migrate.Migrator
+	// set for postgres, used to share chirp events between instances
+	postgresURL string
+}
+
+// picks the store from the DB_URL scheme
+// postgres:// and postgresql:// use postgres, sqlite://path/to/chirpy.db uses a sqlite file
+// and memory:// keeps everything in memory
+func openStorage(dbURL string, logger *slog.Logger) (*storage, error) {
+	u, err := url.Parse(dbURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_URL: %w", err)
+	}
+
+	switch u.Scheme {
+	case "memory":
+		logger.Warn("using the in-memory store, data is lost when the server stops")
+		return &storage{store: store.NewMemory()}, nil
+	case "postgres", "postgresql":
+		db, err := sql.Open("postgres", dbURL)
+		if err != nil {
+			return nil, fmt.Errorf("could not open database: %w", err)
+		}
+		migrator, err := migrate.NewPostgres(db, logger)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		return &storage{
+			store: store.NewPostgres(db, func(db database.DBTX) database.DBTX {
+				return tracing.WrapDBTX(db, "postgresql")
+			}),
+			db:          db,
+			migrator:    migrator,
+			postgresURL: dbURL,
+		}, nil
+	case "sqlite":
+		db, err := store.OpenSQLite(strings.TrimPrefix(dbURL, "sqlite://"))
+		if err != nil {
+			return nil, fmt.Errorf("could not open database: %w", err)
+		}
+		migrator, err := migrate.NewSQLite(db, logger)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		return &storage{
+			store: store.NewSQLite(db, func(db sqlitedb.DBTX) sqlitedb.DBTX {
+				return tracing.WrapDBTX(db, "sqlite")
+			}),
+			db:       db,
+			migrator: migrator,
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported DB_URL scheme %q", u.Scheme)
+}
+
+// applies pending migrations, the in-memory store has nothing to migrate
+func (s *storage) migrate(ctx context.Context) error {
+	if s.migrator == nil {
+		return nil
+	}
+	return s.migrator.Up(ctx)
+}
+
+// how chirp events reach the hub
+// on postgres they go through LISTEN/NOTIFY so every instance sees them, the relay runs until ctx is done
+func (s *storage) eventPublisher(ctx context.Context, hub *events.Hub, logger *slog.Logger) events.Publisher {
+	if s.postgresURL == "" {
+		return hub
+	}
+	relay := events.NewPostgresRelay(s.db, s.postgresURL, hub, logger)
+	go func() {
+		err := relay.Run(ctx)
+		if err != nil {
+			logger.Error("chirp events are not shared between instances, they are only streamed on this one", "error", err)
+		}
+	}()
+	return relay
+}
+
+func (s *storage) Close() error {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.Close()
+}